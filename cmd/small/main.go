@@ -10,6 +10,6 @@ import (
 func main() {
 	if err := commands.Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(commands.ExitCodeForError(err))
 	}
 }
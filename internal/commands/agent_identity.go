@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"os"
+	"os/user"
+	"strings"
+)
+
+const (
+	agentIDEnvVar      = "SMALL_AGENT_ID"
+	agentModelEnvVar   = "SMALL_AGENT_MODEL"
+	agentSessionEnvVar = "SMALL_AGENT_SESSION"
+)
+
+// agentFlag holds the global --agent flag, so one value identifies the caller in
+// every command (progress identity, leases, checkpoint, apply).
+var agentFlag string
+
+// AgentIdentity identifies who wrote a progress entry or handoff: an agent, a tool,
+// or a human operator.
+type AgentIdentity struct {
+	ID        string `yaml:"id" json:"id"`
+	Model     string `yaml:"model,omitempty" json:"model,omitempty"`
+	SessionID string `yaml:"session_id,omitempty" json:"session_id,omitempty"`
+}
+
+// resolveAgentIdentity returns the caller's identity from --agent or SMALL_AGENT_ID,
+// with model and session from SMALL_AGENT_MODEL and SMALL_AGENT_SESSION. It returns
+// nil when no id is set.
+func resolveAgentIdentity() *AgentIdentity {
+	id := strings.TrimSpace(agentFlag)
+	if id == "" {
+		id = strings.TrimSpace(os.Getenv(agentIDEnvVar))
+	}
+	if id == "" {
+		return nil
+	}
+	return &AgentIdentity{
+		ID:        id,
+		Model:     strings.TrimSpace(os.Getenv(agentModelEnvVar)),
+		SessionID: strings.TrimSpace(os.Getenv(agentSessionEnvVar)),
+	}
+}
+
+// recordedAgentIdentity returns the identity stamped on progress entries and handoffs:
+// the configured one, or else the OS account running the command, so that every
+// entry names who wrote it.
+func recordedAgentIdentity() *AgentIdentity {
+	if identity := resolveAgentIdentity(); identity != nil {
+		return identity
+	}
+	return &AgentIdentity{ID: defaultAgentID()}
+}
+
+// defaultAgentID names the OS account running the command as "user:<name>".
+var defaultAgentID = func() string {
+	if current, err := user.Current(); err == nil && strings.TrimSpace(current.Username) != "" {
+		return "user:" + strings.TrimSpace(current.Username)
+	}
+	return "user:unknown"
+}
+
+// currentAgentID returns the caller's agent id, or "" when none is set.
+func currentAgentID() string {
+	if identity := resolveAgentIdentity(); identity != nil {
+		return identity.ID
+	}
+	return ""
+}
+
+// attachProgressAgent stamps the recorded identity on entry unless it already has one.
+func attachProgressAgent(entry map[string]any) {
+	if entry == nil {
+		return
+	}
+	if _, ok := entry["agent"]; ok {
+		return
+	}
+	identity := recordedAgentIdentity()
+	value := map[string]any{"id": identity.ID}
+	if identity.Model != "" {
+		value["model"] = identity.Model
+	}
+	if identity.SessionID != "" {
+		value["session_id"] = identity.SessionID
+	}
+	entry["agent"] = value
+}
+
+// progressEntryAgentID returns the id of the agent that wrote entry, or "".
+func progressEntryAgentID(entry map[string]any) string {
+	agent, _ := entry["agent"].(map[string]any)
+	id, _ := agent["id"].(string)
+	return strings.TrimSpace(id)
+}
+
+// filterProgressEntriesByAgent returns the entries written by agent, or all entries
+// when agent is empty.
+func filterProgressEntriesByAgent(entries []map[string]any, agent string) []map[string]any {
+	agent = strings.TrimSpace(agent)
+	if agent == "" {
+		return entries
+	}
+	filtered := []map[string]any{}
+	for _, entry := range entries {
+		if progressEntryAgentID(entry) == agent {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
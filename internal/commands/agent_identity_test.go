@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+// setAgentFlag sets the global --agent value for one test.
+func setAgentFlag(t *testing.T, value string) {
+	t.Helper()
+	previous := agentFlag
+	agentFlag = value
+	t.Cleanup(func() { agentFlag = previous })
+}
+
+func TestResolveAgentIdentity(t *testing.T) {
+	setAgentFlag(t, "")
+	t.Setenv(agentIDEnvVar, "")
+	if identity := resolveAgentIdentity(); identity != nil {
+		t.Fatalf("expected no identity, got %+v", identity)
+	}
+
+	t.Setenv(agentIDEnvVar, "agent-env")
+	t.Setenv(agentModelEnvVar, "model-x")
+	t.Setenv(agentSessionEnvVar, "sess-1")
+	want := &AgentIdentity{ID: "agent-env", Model: "model-x", SessionID: "sess-1"}
+	if identity := resolveAgentIdentity(); !reflect.DeepEqual(identity, want) {
+		t.Fatalf("resolveAgentIdentity() = %+v, want %+v", identity, want)
+	}
+
+	t.Setenv(agentIDEnvVar, "")
+	if identity := recordedAgentIdentity(); identity == nil || !strings.HasPrefix(identity.ID, "user:") {
+		t.Fatalf("expected entries to record the OS user without a configured identity, got %+v", identity)
+	}
+	if id := currentAgentID(); id != "" {
+		t.Fatalf("the OS user fallback must not act as a lease holder, got %q", id)
+	}
+
+	setAgentFlag(t, "agent-flag")
+	if id := currentAgentID(); id != "agent-flag" {
+		t.Fatalf("expected --agent to override %s, got %q", agentIDEnvVar, id)
+	}
+}
+
+func TestAppendProgressEntryStampsAgent(t *testing.T) {
+	setAgentFlag(t, "")
+	dir := t.TempDir()
+	writeArtifacts(t, dir, defaultArtifacts())
+	mustSaveWorkspace(t, dir, workspace.KindRepoRoot)
+
+	t.Setenv(agentIDEnvVar, "agent-a")
+	t.Setenv(agentModelEnvVar, "model-x")
+	t.Setenv(agentSessionEnvVar, "")
+	if err := appendProgressEntry(dir, map[string]any{"task_id": "task-1", "status": "in_progress", "evidence": "start"}); err != nil {
+		t.Fatal(err)
+	}
+	preset := map[string]any{"id": "human:ops"}
+	if err := appendProgressEntry(dir, map[string]any{"task_id": "task-1", "status": "completed", "evidence": "done", "agent": preset}); err != nil {
+		t.Fatal(err)
+	}
+
+	progress, err := loadProgressData(filepath.Join(dir, small.SmallDir, "progress.small.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := progress.Entries[0]["agent"]; !reflect.DeepEqual(got, map[string]any{"id": "agent-a", "model": "model-x"}) {
+		t.Fatalf("unexpected agent on first entry: %#v", got)
+	}
+	if got := progressEntryAgentID(progress.Entries[1]); got != "human:ops" {
+		t.Fatalf("expected an explicit agent to be kept, got %q", got)
+	}
+	if code := runVerify(dir, true, false, workspace.ScopeAny); code != ExitValid {
+		t.Fatalf("expected stamped progress to verify, got exit %d", code)
+	}
+}
+
+func TestHandoffRecordsAgent(t *testing.T) {
+	setAgentFlag(t, "agent-a")
+	dir := t.TempDir()
+	writeArtifacts(t, dir, defaultArtifacts())
+	mustSaveWorkspace(t, dir, workspace.KindRepoRoot)
+
+	handoff, err := buildHandoff(dir, "", "", nil, nil, nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if handoff.Agent == nil || handoff.Agent.ID != "agent-a" {
+		t.Fatalf("expected handoff agent agent-a, got %+v", handoff.Agent)
+	}
+}
+
+func TestProgressFilterByAgent(t *testing.T) {
+	setAgentFlag(t, "")
+	t.Setenv(agentIDEnvVar, "")
+	dir := t.TempDir()
+	artifacts := cloneArtifacts(defaultArtifacts())
+	artifacts["progress.small.yml"] = `small_version: "1.0.0"
+owner: "agent"
+entries:
+  - task_id: "task-1"
+    status: "in_progress"
+    timestamp: "2026-01-01T00:00:00.000000000Z"
+    evidence: "started"
+    agent:
+      id: "agent-a"
+  - task_id: "task-1"
+    status: "completed"
+    timestamp: "2026-01-01T00:00:01.000000000Z"
+    evidence: "finished"
+    agent:
+      id: "agent-b"
+`
+	writeArtifacts(t, dir, artifacts)
+	mustSaveWorkspace(t, dir, workspace.KindRepoRoot)
+
+	entries, err := getRecentProgressByAgent(dir, 5, false, "agent-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Evidence != "started" || entries[0].Agent != "agent-a" {
+		t.Fatalf("unexpected entries for agent-a: %+v", entries)
+	}
+
+	include, err := parseEmitInclude("progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, _, err := buildEmitOutput(dir, dir, include, workspace.ScopeRoot, 5, 3, "agent-b", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output.Progress.Entries) != 1 || output.Progress.Entries[0]["evidence"] != "finished" {
+		t.Fatalf("unexpected emit entries for agent-b: %+v", output.Progress.Entries)
+	}
+	if len(output.Progress.Recent) != 1 || output.Progress.Recent[0].Agent != "agent-b" {
+		t.Fatalf("unexpected emit recent entries for agent-b: %+v", output.Progress.Recent)
+	}
+	// On status and emit, --agent is the filter rather than the caller identity.
+	root := newRootCmd()
+	root.SetArgs([]string{"status", "--dir", dir, "--agent", "agent-a", "--json"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("status --agent failed: %v", err)
+	}
+	if agentFlag != "" {
+		t.Fatalf("status --agent should not set the caller identity, got %q", agentFlag)
+	}
+	for _, name := range []string{"status", "emit"} {
+		sub, _, err := root.Find([]string{name})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if flag := sub.Flags().Lookup("agent"); flag == nil || !strings.Contains(flag.Usage, "Only") {
+			t.Fatalf("expected %s --agent to filter progress, got %+v", name, flag)
+		}
+	}
+}
@@ -1,10 +1,8 @@
 package commands
 
 import (
-	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -23,6 +21,16 @@ func applyCmd() *cobra.Command {
 		dryRun         bool
 		autoProgress   bool
 		autoCheckpoint bool
+		skipScope      bool
+		timeout        time.Duration
+		killGrace      time.Duration
+		limits         applyResourceLimits
+		sandbox        bool
+		sandboxNetwork bool
+		captureEnv     bool
+		envAllow       []string
+		envProbes      []string
+		retry          applyRetryPolicy
 		dir            string
 		workspaceFlag  string
 	)
@@ -35,6 +43,33 @@ Writes progress entries using signal-first mode by default.
 Set SMALL_PROGRESS_MODE=audit to retain verbose apply telemetry.
 Optionally generates a handoff at the end.
 
+Files the command creates, modifies, or deletes are recorded as touched_paths.
+Any touched path outside intent scope.include, or inside scope.exclude, marks
+the run blocked with scope_violation evidence.
+
+The command runs in its own process group. --timeout stops it with SIGTERM,
+escalating to SIGKILL after --kill-grace; Ctrl-C is forwarded the same way.
+Optional rlimits cap CPU seconds, memory, and open files. The stop reason is
+recorded as structured termination evidence.
+
+--sandbox runs the command in new user, mount, and network namespaces (bwrap,
+or unshare as a fallback). The workspace is read-only except for paths in
+intent scope.include, scope.exclude and .small/ stay read-only, and network is
+off unless --sandbox-network is set. The profile is recorded as sandbox evidence.
+
+--retries re-runs a failing command with exponential backoff. Every attempt is
+recorded in the completion entry's evidence, which is marked flaky when a
+later attempt succeeds.
+
+--capture-env records OS/arch, the working directory, whitelisted environment
+variables, and tool versions as environment evidence. Values whose names look
+like secrets are redacted.
+
+With --task and no --cmd, a task that declares a run block in plan.small.yml
+executes its recipe step by step: one progress entry per step, stopping at the
+first step that fails or exits with an unexpected code, then checkpointing the
+task as completed or blocked.
+
 If no command is provided, defaults to dry-run mode.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if dir == "" {
@@ -79,9 +114,46 @@ If no command is provided, defaults to dry-run mode.`,
 			if autoProgress && dryRun {
 				return fmt.Errorf("--auto-progress cannot be used with --dry-run")
 			}
+			if timeout < 0 || killGrace < 0 {
+				return fmt.Errorf("--timeout and --kill-grace must be non-negative")
+			}
+			if err := limits.validate(); err != nil {
+				return err
+			}
+			if err := retry.validate(); err != nil {
+				return err
+			}
+			if sandboxNetwork && !sandbox {
+				return fmt.Errorf("--sandbox-network requires --sandbox")
+			}
+			if (len(envAllow) > 0 || len(envProbes) > 0) && !captureEnv {
+				return fmt.Errorf("--env-allow and --env-probe require --capture-env")
+			}
+			var envCapture *applyEnvCapture
+			if captureEnv {
+				envCapture, err = newApplyEnvCapture(envAllow, envProbes)
+				if err != nil {
+					return err
+				}
+			}
+
+			if taskID != "" {
+				if err := ensureTaskLeaseAvailable(artifactsDir, taskID, currentAgentID()); err != nil {
+					return err
+				}
+			}
+
+			// A task with a run recipe executes its own commands when --cmd is omitted.
+			var recipe *PlanTaskRun
+			if cmdArg == "" && taskID != "" {
+				recipe, err = loadTaskRecipe(artifactsDir, taskID)
+				if err != nil {
+					return err
+				}
+			}
 
 			// Default to dry-run if no command provided
-			if cmdArg == "" {
+			if cmdArg == "" && recipe == nil {
 				dryRun = true
 			}
 
@@ -95,6 +167,11 @@ If no command is provided, defaults to dry-run mode.`,
 
 				if cmdArg != "" {
 					fmt.Printf("Would execute: %s\n", cmdArg)
+				} else if recipe != nil {
+					fmt.Printf("Would run recipe for task %s:\n", taskID)
+					for i, command := range recipe.Commands {
+						fmt.Printf("  %d. %s\n", i+1, command.Cmd)
+					}
 				} else {
 					fmt.Println("No command specified")
 				}
@@ -140,6 +217,49 @@ If no command is provided, defaults to dry-run mode.`,
 				return nil
 			}
 
+			var sandboxProfile *applySandboxProfile
+			if sandbox {
+				sandboxProfile, err = newApplySandboxProfile(artifactsDir, sandboxNetwork)
+				if err != nil {
+					return err
+				}
+			}
+
+			if recipe != nil {
+				fmt.Printf("Running recipe for task %s\n", taskID)
+				fmt.Println()
+				outcome, err := runApplyRecipe(artifactsDir, taskID, recipe, applyRecipeOptions{
+					Timeout:      timeout,
+					KillGrace:    killGrace,
+					Limits:       limits,
+					AutoProgress: autoProgress,
+					SkipScope:    skipScope,
+					Sandbox:      sandboxProfile,
+					Environment:  envCapture,
+					Retry:        retry,
+					Stdout:       os.Stdout,
+					Stderr:       os.Stderr,
+				})
+				if err != nil {
+					return err
+				}
+				fmt.Println()
+				fmt.Println(outcome.Summary)
+				if !outcome.completed() {
+					return fmt.Errorf("task %s recipe failed at step %d/%d", taskID, outcome.Failed, outcome.Steps)
+				}
+				if handoff {
+					fmt.Println()
+					fmt.Println("Generating handoff...")
+					if err := generateHandoffFromApply(artifactsDir); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: failed to generate handoff: %v\n", err)
+					} else {
+						fmt.Println("Handoff generated")
+					}
+				}
+				return nil
+			}
+
 			emitStartProgress := shouldEmitProgress(progressEventApplyStart, normalizedTaskID, mode)
 			if emitStartProgress {
 				startEntry := map[string]any{
@@ -166,36 +286,60 @@ If no command is provided, defaults to dry-run mode.`,
 				}
 			}
 
+			scopeGuard, err := startApplyScopeGuard(artifactsDir, !skipScope)
+			if err != nil {
+				return err
+			}
+
+			var environment map[string]any
+			if envCapture != nil {
+				environment = envCapture.capture(artifactsDir, nil)
+			}
+
 			fmt.Printf("Executing: %s\n", cmdArg)
 			fmt.Println()
 
 			// Execute command using sh -lc for portability
-			shellCmd := exec.Command("sh", "-lc", cmdArg)
-			shellCmd.Dir = artifactsDir
-
-			var outputBuffer bytes.Buffer
-			if autoProgress {
-				shellCmd.Stdout = &outputBuffer
-				shellCmd.Stderr = &outputBuffer
-			} else {
-				shellCmd.Stdout = os.Stdout
-				shellCmd.Stderr = os.Stderr
+			execOpts := applyExecOptions{
+				Command:   cmdArg,
+				Dir:       artifactsDir,
+				Stdout:    os.Stdout,
+				Stderr:    os.Stderr,
+				Timeout:   timeout,
+				KillGrace: killGrace,
+				Limits:    limits,
+				Sandbox:   sandboxProfile,
 			}
 
-			cmdErr := shellCmd.Run()
-			exitCode := 0
+			// Stream stdout/stderr to sibling logs of the command log when the run has a replayId.
+			replayId, err := resolveApplyReplayID(artifactsDir)
+			if err != nil {
+				return err
+			}
+			attempts, err := runApplyAttempts(artifactsDir, replayId, timestamp, execOpts, retry, autoProgress, os.Stderr)
+			if err != nil {
+				return err
+			}
+			final := attempts[len(attempts)-1]
+			result := final.Result
+			outputLogs := final.Logs
+			cmdErr := result.Err
+			exitCode := result.ExitCode
 			status := "completed"
-
 			if cmdErr != nil {
-				if exitErr, ok := cmdErr.(*exec.ExitError); ok {
-					exitCode = exitErr.ExitCode()
-				} else {
-					exitCode = 1
-				}
 				status = "blocked"
 			}
 
-			emitEndProgress := shouldEmitProgress(progressEventApplyComplete, normalizedTaskID, mode)
+			touched, violations, scopeErr := scopeGuard.finish()
+			if scopeErr != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", scopeErr)
+			}
+			if len(violations) > 0 {
+				status = "blocked"
+			}
+
+			// Scope violations are always recorded, regardless of progress mode.
+			emitEndProgress := shouldEmitProgress(progressEventApplyComplete, normalizedTaskID, mode) || len(violations) > 0
 
 			// Record completion entry
 			endTimestamp := formatProgressTimestamp(time.Now().UTC())
@@ -206,7 +350,8 @@ If no command is provided, defaults to dry-run mode.`,
 			}
 
 			if emitEndProgress && cmdArg != "" {
-				summary, ref, sha, err := applyCommandMetadata(artifactsDir, endTimestamp, cmdArg)
+				// Name the command log after the start timestamp so it sits beside the output logs.
+				summary, ref, sha, err := applyCommandMetadata(artifactsDir, timestamp, cmdArg)
 				if err != nil {
 					return err
 				}
@@ -215,17 +360,73 @@ If no command is provided, defaults to dry-run mode.`,
 				endEntry["command_ref"] = ref
 				endEntry["command_sha256"] = sha
 			}
+			outputLogs.annotate(endEntry)
+			if !touched.IsEmpty() {
+				endEntry["touched_paths"] = touched.Map()
+			}
+
+			// Structured sections replace the plain evidence string when present.
+			var summaries []string
+			sections := map[string]any{}
+			if result.terminated() {
+				summaries = append(summaries, applyTerminationSummary(result, timeout))
+				sections["termination"] = applyTerminationEvidence(result, execOpts)
+			}
+			if len(violations) > 0 {
+				summaries = append(summaries, scopeViolationSummary(violations))
+				sections["scope_violation"] = buildScopeViolationEvidence(scopeGuard.scope, violations)
+			}
+			if retry.enabled() {
+				if summary := applyAttemptsSummary(attempts, retry); summary != "" {
+					summaries = append(summaries, summary)
+				}
+				sections["attempts"] = applyAttemptsEvidence(attempts)
+				if applyAttemptsFlaky(attempts) {
+					sections["flaky"] = true
+				}
+			}
+			if environment != nil {
+				sections["environment"] = environment
+			}
+			// Informational sections still need the outcome in the summary.
+			if len(summaries) == 0 && (len(sections) > 0 || sandboxProfile != nil) {
+				if status == "completed" {
+					summaries = append(summaries, "Command completed successfully")
+				} else {
+					summaries = append(summaries, fmt.Sprintf("Command failed with exit code %d", exitCode))
+				}
+			}
+			if sandboxProfile != nil {
+				summaries = append(summaries, sandboxProfile.summary())
+				sections["sandbox"] = sandboxProfile.evidence()
+			}
 
+			var plainEvidence string
 			if autoProgress {
-				endEntry["evidence"] = buildAutoProgressEvidence(outputBuffer.String(), exitCode)
+				plainEvidence = buildAutoProgressEvidence(final.Output, exitCode)
 				endEntry["notes"] = fmt.Sprintf("apply: exit code %d", exitCode)
 			} else if status == "completed" {
-				endEntry["evidence"] = "Command completed successfully"
+				plainEvidence = "Command completed successfully"
 				endEntry["notes"] = fmt.Sprintf("apply: exit code %d", exitCode)
 			} else {
-				endEntry["evidence"] = fmt.Sprintf("Command failed with exit code %d", exitCode)
+				plainEvidence = fmt.Sprintf("Command failed with exit code %d", exitCode)
 				endEntry["notes"] = fmt.Sprintf("apply: failed with exit code %d", exitCode)
 			}
+			endEntry["evidence"] = plainEvidence
+			if len(sections) > 0 {
+				evidence := map[string]any{
+					"summary":   strings.Join(summaries, "; "),
+					"exit_code": exitCode,
+				}
+				for key, value := range sections {
+					evidence[key] = value
+				}
+				if autoProgress {
+					evidence["output"] = plainEvidence
+				}
+				endEntry["evidence"] = evidence
+				endEntry["notes"] = fmt.Sprintf("apply: %s (exit code %d)", strings.Join(summaries, "; "), exitCode)
+			}
 
 			if emitEndProgress {
 				if err := appendProgressEntry(artifactsDir, endEntry); err != nil {
@@ -241,15 +442,25 @@ If no command is provided, defaults to dry-run mode.`,
 				if status != "completed" {
 					checkpointStatus = "blocked"
 				}
-				checkpointEvidence := buildAutoProgressEvidence(outputBuffer.String(), exitCode)
+				checkpointEvidence := buildAutoProgressEvidence(final.Output, exitCode)
+				if len(summaries) > 0 {
+					checkpointEvidence = strings.Join(summaries, "; ") + "; " + checkpointEvidence
+				}
 				if err := runCheckpointApply(artifactsDir, taskID, checkpointStatus, checkpointEvidence); err != nil {
 					return err
 				}
 			}
 
 			fmt.Println()
+			if len(violations) > 0 {
+				fmt.Fprintf(os.Stderr, "%s:\n%s", scopeViolationSummary(violations), formatScopeViolations(violations))
+			}
 			if status == "completed" {
 				fmt.Printf("Command completed successfully (exit code: %d)\n", exitCode)
+			} else if result.terminated() {
+				fmt.Printf("%s (exit code: %d)\n", applyTerminationSummary(result, timeout), exitCode)
+			} else if cmdErr == nil {
+				fmt.Printf("Command blocked by scope violation (exit code: %d)\n", exitCode)
 			} else {
 				fmt.Printf("Command failed (exit code: %d)\n", exitCode)
 			}
@@ -270,6 +481,9 @@ If no command is provided, defaults to dry-run mode.`,
 			if cmdErr != nil {
 				os.Exit(exitCode)
 			}
+			if len(violations) > 0 {
+				return fmt.Errorf("scope violation: %d path(s) outside intent scope", len(violations))
+			}
 
 			return nil
 		},
@@ -281,6 +495,20 @@ If no command is provided, defaults to dry-run mode.`,
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Do not execute, only record intent")
 	cmd.Flags().BoolVar(&autoProgress, "auto-progress", false, "Capture output in progress evidence")
 	cmd.Flags().BoolVar(&autoCheckpoint, "auto-checkpoint", false, "Checkpoint the task based on command result")
+	cmd.Flags().BoolVar(&skipScope, "skip-scope-check", false, "Record touched paths without enforcing intent scope")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Stop the command after this duration (e.g. 30s, 5m; 0 disables)")
+	cmd.Flags().DurationVar(&killGrace, "kill-grace", defaultApplyKillGrace, "Grace period between SIGTERM and SIGKILL when stopping the command")
+	cmd.Flags().IntVar(&limits.CPUSeconds, "rlimit-cpu", 0, "CPU time limit in seconds (0 disables)")
+	cmd.Flags().IntVar(&limits.MemoryMB, "rlimit-mem", 0, "Virtual memory limit in MiB (0 disables)")
+	cmd.Flags().IntVar(&limits.OpenFiles, "rlimit-nofile", 0, "Open file descriptor limit (0 disables)")
+	cmd.Flags().BoolVar(&sandbox, "sandbox", false, "Run the command in a Linux namespace sandbox (writable only within intent scope)")
+	cmd.Flags().BoolVar(&sandboxNetwork, "sandbox-network", false, "Allow network access inside the sandbox")
+	cmd.Flags().IntVar(&retry.Retries, "retries", 0, "Re-run a failing command up to N more times")
+	cmd.Flags().DurationVar(&retry.Backoff, "retry-backoff", defaultApplyRetryBackoff, "Delay before the first retry, doubling for each later retry")
+	cmd.Flags().IntSliceVar(&retry.OnExitCodes, "retry-on-exit-codes", nil, "Only retry these exit codes (comma-separated; default any failure)")
+	cmd.Flags().BoolVar(&captureEnv, "capture-env", false, "Record OS/arch, working dir, whitelisted env vars, and tool versions as environment evidence")
+	cmd.Flags().StringArrayVar(&envAllow, "env-allow", nil, "Environment variable to capture (repeatable; trailing * matches a prefix)")
+	cmd.Flags().StringArrayVar(&envProbes, "env-probe", nil, "Tool version probe as name=command (repeatable)")
 
 	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
 	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root or any)")
@@ -303,22 +531,19 @@ func generateHandoffFromApply(baseDir string) error {
 	if err := setWorkspaceRunReplayIDIfPresent(baseDir, handoff.ReplayId.Value); err != nil {
 		return err
 	}
+	if err := attachHandoffContext(baseDir, &handoff, carriedHandoffContext(baseDir, handoff.ReplayId.Value)); err != nil {
+		return err
+	}
 
 	return writeHandoff(baseDir, handoff)
 }
 
 func applyCommandMetadata(baseDir, timestamp, command string) (string, string, string, error) {
 	summary := small.SummarizeCommand(command, small.DefaultCommandSummaryCap)
-	replayId, err := currentWorkspaceRunReplayID(baseDir)
+	replayId, err := resolveApplyReplayID(baseDir)
 	if err != nil {
 		return "", "", "", err
 	}
-	if replayId == "" {
-		existing, loadErr := loadExistingHandoff(baseDir)
-		if loadErr == nil && existing != nil && existing.ReplayId != nil {
-			replayId = strings.TrimSpace(existing.ReplayId.Value)
-		}
-	}
 	if replayId == "" {
 		return "", "", "", fmt.Errorf("cannot record command log: replayId missing (run small plan --add or small checkpoint)")
 	}
@@ -359,27 +584,28 @@ func runCheckpointApply(baseDir, taskID, status string, evidence string) error {
 	if status != "completed" && status != "blocked" {
 		return fmt.Errorf("checkpoint status must be completed or blocked")
 	}
+	artifactWriteMu.Lock()
+	defer artifactWriteMu.Unlock()
+	unlock, err := small.LockWorkspace(baseDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	planPath := filepath.Join(baseDir, small.SmallDir, "plan.small.yml")
-	progressPath := filepath.Join(baseDir, small.SmallDir, "progress.small.yml")
 
 	plan, err := loadPlan(planPath)
 	if err != nil {
 		return fmt.Errorf("failed to load plan.small.yml: %w", err)
 	}
 
-	progress, err := loadProgressData(progressPath)
-	if err != nil {
-		return fmt.Errorf("failed to load progress.small.yml: %w", err)
-	}
-
 	originalPlanData, err := yaml.Marshal(plan)
 	if err != nil {
 		return fmt.Errorf("failed to snapshot plan.small.yml: %w", err)
 	}
-	originalProgressData, err := yaml.Marshal(&progress)
+	originalProgress, err := snapshotProgress(baseDir)
 	if err != nil {
-		return fmt.Errorf("failed to snapshot progress.small.yml: %w", err)
+		return err
 	}
 
 	if err := setTaskStatus(plan, taskID, status); err != nil {
@@ -402,19 +628,19 @@ func runCheckpointApply(baseDir, taskID, status string, evidence string) error {
 		return err
 	}
 
-	if err := appendProgressEntryWithData(baseDir, entry, progress); err != nil {
+	if err := appendProgressEntryLocked(baseDir, entry); err != nil {
 		return err
 	}
 
 	if err := savePlan(planPath, plan); err != nil {
 		_ = os.WriteFile(planPath, originalPlanData, 0o644)
-		_ = os.WriteFile(progressPath, originalProgressData, 0o644)
+		originalProgress.restore()
 		return err
 	}
 
 	if err := validateCheckpointArtifacts(baseDir); err != nil {
 		_ = os.WriteFile(planPath, originalPlanData, 0o644)
-		_ = os.WriteFile(progressPath, originalProgressData, 0o644)
+		originalProgress.restore()
 		return err
 	}
 
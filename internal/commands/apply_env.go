@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+)
+
+// defaultApplyEnvAllow is the variable whitelist used when --env-allow is not given.
+var defaultApplyEnvAllow = []string{"CI", "GOOS", "GOARCH", "GOFLAGS", "NODE_ENV", "LANG", "SHELL"}
+
+// defaultApplyEnvProbes are the tool version probes always attempted; --env-probe adds
+// to them or replaces one by name.
+var defaultApplyEnvProbes = []applyEnvProbe{
+	{Name: "go", Command: "go version"},
+	{Name: "node", Command: "node --version"},
+	{Name: "git", Command: "git --version"},
+}
+
+const (
+	applyEnvProbeTimeout = 5 * time.Second
+	applyEnvProbeMaxLen  = 200
+)
+
+type applyEnvProbe struct {
+	Name    string
+	Command string
+}
+
+// applyEnvCapture configures the opt-in environment capture for apply runs.
+type applyEnvCapture struct {
+	// Allow holds variable names; a trailing "*" matches by prefix.
+	Allow  []string
+	Probes []applyEnvProbe
+}
+
+// newApplyEnvCapture merges flag values with the defaults. Probes are given as name=command.
+func newApplyEnvCapture(allow, probes []string) (*applyEnvCapture, error) {
+	capture := &applyEnvCapture{Allow: defaultApplyEnvAllow}
+	if len(allow) > 0 {
+		capture.Allow = allow
+	}
+
+	merged := append([]applyEnvProbe{}, defaultApplyEnvProbes...)
+	for _, value := range probes {
+		name, command, ok := strings.Cut(value, "=")
+		name, command = strings.TrimSpace(name), strings.TrimSpace(command)
+		if !ok || name == "" || command == "" {
+			return nil, fmt.Errorf("invalid --env-probe %q (expected name=command)", value)
+		}
+		replaced := false
+		for i := range merged {
+			if merged[i].Name == name {
+				merged[i].Command = command
+				replaced = true
+			}
+		}
+		if !replaced {
+			merged = append(merged, applyEnvProbe{Name: name, Command: command})
+		}
+	}
+	capture.Probes = merged
+	return capture, nil
+}
+
+func (c *applyEnvCapture) allows(name string) bool {
+	for _, pattern := range c.Allow {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// capture records OS/arch, the working directory, whitelisted variables, and probe
+// output. Values whose names match the secret key list, or that look like opaque
+// tokens, are dropped and their names listed under redacted.
+func (c *applyEnvCapture) capture(dir string, extraEnv []string) map[string]any {
+	environ := append(os.Environ(), extraEnv...)
+	values := map[string]string{}
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if ok && c.allows(name) {
+			values[name] = value
+		}
+	}
+
+	variables := map[string]any{}
+	var redacted []string
+	for name, value := range values {
+		if small.IsSecretKey(name) || small.LooksLikeSecretValue(value) {
+			redacted = append(redacted, name)
+			continue
+		}
+		variables[name] = value
+	}
+
+	tools := map[string]any{}
+	var unavailable []string
+	for _, probe := range c.Probes {
+		output, err := runApplyEnvProbe(dir, probe.Command, environ)
+		switch {
+		case err != nil || output == "":
+			unavailable = append(unavailable, probe.Name)
+		case small.IsSecretKey(probe.Name) || small.LooksLikeSecretValue(output):
+			redacted = append(redacted, probe.Name)
+		default:
+			tools[probe.Name] = output
+		}
+	}
+
+	environment := map[string]any{
+		"os":          runtime.GOOS,
+		"arch":        runtime.GOARCH,
+		"working_dir": dir,
+		"variables":   variables,
+		"tools":       tools,
+	}
+	if len(redacted) > 0 {
+		sort.Strings(redacted)
+		environment["redacted"] = small.StringsToAny(redacted)
+	}
+	if len(unavailable) > 0 {
+		sort.Strings(unavailable)
+		environment["unavailable"] = small.StringsToAny(unavailable)
+	}
+	return environment
+}
+
+// runApplyEnvProbe returns the first line of the probe's output.
+func runApplyEnvProbe(dir, command string, environ []string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), applyEnvProbeTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	cmd.Env = environ
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	line = strings.TrimSpace(line)
+	if len(line) > applyEnvProbeMaxLen {
+		line = line[:applyEnvProbeMaxLen]
+	}
+	return line, nil
+}
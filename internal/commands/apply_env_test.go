@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func TestNewApplyEnvCapture(t *testing.T) {
+	capture, err := newApplyEnvCapture(nil, []string{"git=git version", "make=make --version"})
+	if err != nil {
+		t.Fatalf("newApplyEnvCapture failed: %v", err)
+	}
+	if len(capture.Allow) != len(defaultApplyEnvAllow) {
+		t.Fatalf("expected default allow list, got %v", capture.Allow)
+	}
+	probes := map[string]string{}
+	for _, probe := range capture.Probes {
+		probes[probe.Name] = probe.Command
+	}
+	if probes["git"] != "git version" || probes["make"] != "make --version" || probes["go"] != "go version" {
+		t.Fatalf("unexpected probes: %v", probes)
+	}
+
+	if _, err := newApplyEnvCapture(nil, []string{"missing-command"}); err == nil {
+		t.Fatal("expected error for probe without =")
+	}
+
+	capture, _ = newApplyEnvCapture([]string{"SMALL_TEST_*", "CI"}, nil)
+	for name, want := range map[string]bool{"SMALL_TEST_A": true, "CI": true, "CIX": false, "SMALL_OTHER": false} {
+		if got := capture.allows(name); got != want {
+			t.Errorf("allows(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestApplyEnvCaptureRedactsSecrets(t *testing.T) {
+	t.Setenv("SMALL_TEST_MODE", "fast")
+	t.Setenv("SMALL_TEST_API_TOKEN", "hunter2")
+	t.Setenv("SMALL_TEST_OPAQUE", "QUJDREVGR0hJSktMTU5PUFFSU1RVVldYWVo0NTY3ODk=")
+
+	capture, err := newApplyEnvCapture([]string{"SMALL_TEST_*"}, []string{"echo=echo probe-ok", "broken=exit 3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	environment := capture.capture(dir, []string{"SMALL_TEST_EXTRA=step"})
+
+	if environment["os"] != runtime.GOOS || environment["arch"] != runtime.GOARCH || environment["working_dir"] != dir {
+		t.Fatalf("unexpected platform fields: %#v", environment)
+	}
+	variables := environment["variables"].(map[string]any)
+	if variables["SMALL_TEST_MODE"] != "fast" || variables["SMALL_TEST_EXTRA"] != "step" {
+		t.Fatalf("unexpected variables: %#v", variables)
+	}
+	if _, ok := variables["SMALL_TEST_API_TOKEN"]; ok {
+		t.Fatal("secret-named variable must not be recorded")
+	}
+	redacted := environment["redacted"].([]any)
+	if len(redacted) != 2 || redacted[0] != "SMALL_TEST_API_TOKEN" || redacted[1] != "SMALL_TEST_OPAQUE" {
+		t.Fatalf("redacted = %#v", redacted)
+	}
+	tools := environment["tools"].(map[string]any)
+	if tools["echo"] != "probe-ok" {
+		t.Fatalf("expected probe output, got %#v", tools)
+	}
+	unavailable, _ := environment["unavailable"].([]any)
+	found := false
+	for _, name := range unavailable {
+		if name == "broken" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected failing probe to be unavailable, got %#v", environment["unavailable"])
+	}
+}
+
+func TestApplyCaptureEnvRecordsEvidence(t *testing.T) {
+	t.Setenv(progressModeEnvVar, string(progressModeSignal))
+	t.Setenv("SMALL_TEST_SECRET", "s3cr3t")
+
+	tmpDir := t.TempDir()
+	writeArtifacts(t, tmpDir, defaultArtifacts())
+	mustSaveWorkspace(t, tmpDir, workspace.KindRepoRoot)
+
+	cmd := applyCmd()
+	cmd.SetArgs([]string{"--dir", tmpDir, "--workspace", "any", "--task", "task-1", "--cmd", "true",
+		"--capture-env", "--env-allow", "SMALL_TEST_*", "--env-probe", "shell=echo sh"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("apply execute failed: %v", err)
+	}
+
+	progress, err := loadProgressData(filepath.Join(tmpDir, ".small", "progress.small.yml"))
+	if err != nil {
+		t.Fatalf("failed to load progress: %v", err)
+	}
+	entry := progress.Entries[len(progress.Entries)-1]
+	evidence, ok := entry["evidence"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected structured evidence, got %#v", entry["evidence"])
+	}
+	if evidence["summary"] != "Command completed successfully" {
+		t.Fatalf("summary = %#v", evidence["summary"])
+	}
+	environment, ok := evidence["environment"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected environment evidence, got %#v", evidence["environment"])
+	}
+	if redacted, _ := environment["redacted"].([]any); len(redacted) != 1 || redacted[0] != "SMALL_TEST_SECRET" {
+		t.Fatalf("redacted = %#v", environment["redacted"])
+	}
+	if tools, _ := environment["tools"].(map[string]any); tools["shell"] != "sh" {
+		t.Fatalf("tools = %#v", environment["tools"])
+	}
+
+	if code := runVerify(tmpDir, false, true, workspace.ScopeAny); code != ExitValid {
+		t.Fatalf("expected artifacts with environment evidence to verify, got exit code %d", code)
+	}
+}
@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// Termination reasons recorded when small apply stops a command itself.
+const (
+	applyKillTimeout     = "timeout"
+	applyKillInterrupted = "interrupted"
+	applyKillSignal      = "signal"
+)
+
+// Exit codes reported for commands stopped by small apply, following coreutils timeout
+// and shell conventions.
+const (
+	applyExitTimeout     = 124
+	applyExitInterrupted = 130
+)
+
+const defaultApplyKillGrace = 5 * time.Second
+
+// applyResourceLimits are per-command rlimits applied with the shell's ulimit builtin.
+// Zero values leave the inherited limit unchanged.
+type applyResourceLimits struct {
+	CPUSeconds int
+	MemoryMB   int
+	OpenFiles  int
+}
+
+func (l applyResourceLimits) any() bool {
+	return l.CPUSeconds > 0 || l.MemoryMB > 0 || l.OpenFiles > 0
+}
+
+func (l applyResourceLimits) validate() error {
+	if l.CPUSeconds < 0 || l.MemoryMB < 0 || l.OpenFiles < 0 {
+		return fmt.Errorf("resource limits must be non-negative")
+	}
+	if l.any() && !applyResourceLimitsSupported {
+		return fmt.Errorf("resource limits are not supported on this platform")
+	}
+	return nil
+}
+
+// wrap prefixes the command with ulimit calls. A failing ulimit aborts the command
+// with exit code 125 rather than running it unbounded.
+func (l applyResourceLimits) wrap(command string) string {
+	if !l.any() {
+		return command
+	}
+	var b strings.Builder
+	if l.CPUSeconds > 0 {
+		fmt.Fprintf(&b, "ulimit -t %d || exit 125\n", l.CPUSeconds)
+	}
+	if l.MemoryMB > 0 {
+		fmt.Fprintf(&b, "ulimit -v %d || exit 125\n", l.MemoryMB*1024)
+	}
+	if l.OpenFiles > 0 {
+		fmt.Fprintf(&b, "ulimit -n %d || exit 125\n", l.OpenFiles)
+	}
+	b.WriteString(command)
+	return b.String()
+}
+
+func (l applyResourceLimits) evidence() map[string]any {
+	out := map[string]any{}
+	if l.CPUSeconds > 0 {
+		out["cpu_seconds"] = l.CPUSeconds
+	}
+	if l.MemoryMB > 0 {
+		out["memory_mb"] = l.MemoryMB
+	}
+	if l.OpenFiles > 0 {
+		out["open_files"] = l.OpenFiles
+	}
+	return out
+}
+
+type applyExecOptions struct {
+	Command string
+	Dir     string
+	// Env is appended to the inherited environment; later entries win.
+	Env       []string
+	Stdout    io.Writer
+	Stderr    io.Writer
+	Timeout   time.Duration
+	KillGrace time.Duration
+	Limits    applyResourceLimits
+	// Sandbox, when set, runs the command inside a namespace sandbox.
+	Sandbox *applySandboxProfile
+}
+
+type applyExecResult struct {
+	ExitCode int
+	Err      error
+	Duration time.Duration
+	// KillReason is set when the command was stopped by a timeout, an interrupt,
+	// or a signal rather than exiting on its own.
+	KillReason string
+	Signal     string
+	Escalated  bool
+}
+
+func (r applyExecResult) terminated() bool {
+	return r.KillReason != ""
+}
+
+// startFailed reports whether the command could not be started at all, as opposed
+// to running and exiting non-zero.
+func (r applyExecResult) startFailed() bool {
+	var exitErr *exec.ExitError
+	return r.Err != nil && !errors.As(r.Err, &exitErr)
+}
+
+// runApplyCommand runs the command via sh -lc in its own process group. On timeout or
+// interrupt the whole group receives a graceful signal, escalating to a forced kill
+// after the grace period.
+func runApplyCommand(opts applyExecOptions) applyExecResult {
+	grace := opts.KillGrace
+	if grace <= 0 {
+		grace = defaultApplyKillGrace
+	}
+
+	argv := []string{"sh", "-lc", opts.Limits.wrap(opts.Command)}
+	if opts.Sandbox != nil {
+		argv = opts.Sandbox.command(opts.Dir, argv)
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = opts.Dir
+	if len(opts.Env) > 0 {
+		cmd.Env = append(os.Environ(), opts.Env...)
+	}
+	cmd.Stdout = opts.Stdout
+	cmd.Stderr = opts.Stderr
+	cmd.WaitDelay = grace
+	configureApplyProcessGroup(cmd)
+
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, applyForwardedSignals...)
+	defer signal.Stop(interrupts)
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		return applyExecResult{ExitCode: 1, Err: err}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var timeoutC <-chan time.Time
+	if opts.Timeout > 0 {
+		timer := time.NewTimer(opts.Timeout)
+		defer timer.Stop()
+		timeoutC = timer.C
+	}
+
+	var result applyExecResult
+	var killC <-chan time.Time
+	for {
+		select {
+		case err := <-done:
+			result.Err = err
+			result.Duration = time.Since(start)
+			result.ExitCode = applyExitCode(err, &result)
+			return result
+		case <-timeoutC:
+			timeoutC = nil
+			if result.KillReason == "" {
+				result.KillReason = applyKillTimeout
+				result.Signal = terminateApplyProcessGroup(cmd)
+				killC = time.After(grace)
+			}
+		case sig := <-interrupts:
+			if result.KillReason == "" {
+				result.KillReason = applyKillInterrupted
+				result.Signal = forwardApplySignal(cmd, sig)
+				killC = time.After(grace)
+			} else {
+				// A second interrupt skips the grace period.
+				killC = nil
+				result.Signal = killApplyProcessGroup(cmd)
+				result.Escalated = true
+			}
+		case <-killC:
+			killC = nil
+			result.Signal = killApplyProcessGroup(cmd)
+			result.Escalated = true
+		}
+	}
+}
+
+func applyExitCode(err error, result *applyExecResult) int {
+	switch result.KillReason {
+	case applyKillTimeout:
+		return applyExitTimeout
+	case applyKillInterrupted:
+		return applyExitInterrupted
+	}
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if name, signo, ok := applySignaled(exitErr); ok {
+			result.KillReason = applyKillSignal
+			result.Signal = name
+			return 128 + signo
+		}
+		return exitErr.ExitCode()
+	}
+	return 1
+}
+
+// applyTerminationSummary describes why small apply stopped the command.
+func applyTerminationSummary(result applyExecResult, timeout time.Duration) string {
+	switch result.KillReason {
+	case applyKillTimeout:
+		return fmt.Sprintf("Command timed out after %s", timeout)
+	case applyKillInterrupted:
+		return "Command interrupted"
+	case applyKillSignal:
+		return fmt.Sprintf("Command terminated by %s", result.Signal)
+	default:
+		return ""
+	}
+}
+
+func applyTerminationEvidence(result applyExecResult, opts applyExecOptions) map[string]any {
+	termination := map[string]any{
+		"reason":      result.KillReason,
+		"duration_ms": result.Duration.Milliseconds(),
+	}
+	if result.Signal != "" {
+		termination["signal"] = result.Signal
+	}
+	if result.KillReason != applyKillSignal {
+		grace := opts.KillGrace
+		if grace <= 0 {
+			grace = defaultApplyKillGrace
+		}
+		termination["escalated"] = result.Escalated
+		termination["kill_grace"] = grace.String()
+	}
+	if result.KillReason == applyKillTimeout {
+		termination["timeout"] = opts.Timeout.String()
+	}
+	if limits := opts.Limits.evidence(); len(limits) > 0 {
+		termination["resource_limits"] = limits
+	}
+	return termination
+}
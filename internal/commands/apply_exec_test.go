@@ -0,0 +1,125 @@
+//go:build !windows
+
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunApplyCommandTimeout(t *testing.T) {
+	start := time.Now()
+	result := runApplyCommand(applyExecOptions{
+		Command:   "sleep 30 & sleep 30",
+		Dir:       t.TempDir(),
+		Stdout:    &bytes.Buffer{},
+		Stderr:    &bytes.Buffer{},
+		Timeout:   100 * time.Millisecond,
+		KillGrace: 200 * time.Millisecond,
+	})
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("expected command to be stopped promptly, took %s", elapsed)
+	}
+	if result.KillReason != applyKillTimeout {
+		t.Fatalf("KillReason = %q, want %q", result.KillReason, applyKillTimeout)
+	}
+	if result.ExitCode != applyExitTimeout {
+		t.Fatalf("ExitCode = %d, want %d", result.ExitCode, applyExitTimeout)
+	}
+	if result.Err == nil {
+		t.Fatal("expected error for timed out command")
+	}
+	if result.Signal != "SIGTERM" || result.Escalated {
+		t.Fatalf("expected graceful SIGTERM stop, got signal=%q escalated=%v", result.Signal, result.Escalated)
+	}
+}
+
+func TestRunApplyCommandEscalatesToKill(t *testing.T) {
+	result := runApplyCommand(applyExecOptions{
+		Command:   "trap '' TERM; while :; do sleep 0.05; done",
+		Dir:       t.TempDir(),
+		Stdout:    &bytes.Buffer{},
+		Stderr:    &bytes.Buffer{},
+		Timeout:   100 * time.Millisecond,
+		KillGrace: 200 * time.Millisecond,
+	})
+	if result.KillReason != applyKillTimeout {
+		t.Fatalf("KillReason = %q, want %q", result.KillReason, applyKillTimeout)
+	}
+	if !result.Escalated || result.Signal != "SIGKILL" {
+		t.Fatalf("expected escalation to SIGKILL, got signal=%q escalated=%v", result.Signal, result.Escalated)
+	}
+
+	evidence := applyTerminationEvidence(result, applyExecOptions{Timeout: 100 * time.Millisecond, KillGrace: 200 * time.Millisecond})
+	if evidence["reason"] != applyKillTimeout || evidence["signal"] != "SIGKILL" || evidence["escalated"] != true {
+		t.Fatalf("unexpected termination evidence: %#v", evidence)
+	}
+	if evidence["timeout"] != "100ms" {
+		t.Fatalf("timeout evidence = %v, want 100ms", evidence["timeout"])
+	}
+}
+
+func TestRunApplyCommandSignaled(t *testing.T) {
+	result := runApplyCommand(applyExecOptions{
+		Command: "kill -KILL $$",
+		Dir:     t.TempDir(),
+		Stdout:  &bytes.Buffer{},
+		Stderr:  &bytes.Buffer{},
+	})
+	if result.KillReason != applyKillSignal || result.Signal != "SIGKILL" {
+		t.Fatalf("expected signal termination by SIGKILL, got reason=%q signal=%q", result.KillReason, result.Signal)
+	}
+	if result.ExitCode != 137 {
+		t.Fatalf("ExitCode = %d, want 137", result.ExitCode)
+	}
+	if got := applyTerminationSummary(result, 0); got != "Command terminated by SIGKILL" {
+		t.Fatalf("summary = %q", got)
+	}
+}
+
+func TestRunApplyCommandExitCode(t *testing.T) {
+	result := runApplyCommand(applyExecOptions{
+		Command: "exit 3",
+		Dir:     t.TempDir(),
+		Stdout:  &bytes.Buffer{},
+		Stderr:  &bytes.Buffer{},
+	})
+	if result.ExitCode != 3 || result.terminated() {
+		t.Fatalf("expected plain exit code 3, got %d (reason %q)", result.ExitCode, result.KillReason)
+	}
+}
+
+func TestRunApplyCommandResourceLimits(t *testing.T) {
+	var stdout bytes.Buffer
+	result := runApplyCommand(applyExecOptions{
+		Command: "ulimit -n",
+		Dir:     t.TempDir(),
+		Stdout:  &stdout,
+		Stderr:  &bytes.Buffer{},
+		Limits:  applyResourceLimits{OpenFiles: 64},
+	})
+	if result.Err != nil {
+		t.Fatalf("unexpected error: %v", result.Err)
+	}
+	if strings.TrimSpace(stdout.String()) != "64" {
+		t.Fatalf("expected open file limit 64, got %q", stdout.String())
+	}
+}
+
+func TestApplyResourceLimitsWrap(t *testing.T) {
+	limits := applyResourceLimits{CPUSeconds: 10, MemoryMB: 512, OpenFiles: 128}
+	wrapped := limits.wrap("make test")
+	for _, want := range []string{"ulimit -t 10", "ulimit -v 524288", "ulimit -n 128", "make test"} {
+		if !strings.Contains(wrapped, want) {
+			t.Fatalf("wrapped command missing %q:\n%s", want, wrapped)
+		}
+	}
+	if (applyResourceLimits{}).wrap("true") != "true" {
+		t.Fatal("expected no wrapping without limits")
+	}
+	if err := (applyResourceLimits{CPUSeconds: -1}).validate(); err == nil {
+		t.Fatal("expected negative limit to be rejected")
+	}
+}
@@ -0,0 +1,52 @@
+//go:build !windows
+
+package commands
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const applyResourceLimitsSupported = true
+
+var applyForwardedSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+func configureApplyProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func signalApplyProcessGroup(cmd *exec.Cmd, sig syscall.Signal) string {
+	if cmd.Process != nil {
+		if err := syscall.Kill(-cmd.Process.Pid, sig); err != nil && !errors.Is(err, syscall.ESRCH) {
+			_ = cmd.Process.Signal(sig)
+		}
+	}
+	return unix.SignalName(sig)
+}
+
+func terminateApplyProcessGroup(cmd *exec.Cmd) string {
+	return signalApplyProcessGroup(cmd, syscall.SIGTERM)
+}
+
+func killApplyProcessGroup(cmd *exec.Cmd) string {
+	return signalApplyProcessGroup(cmd, syscall.SIGKILL)
+}
+
+func forwardApplySignal(cmd *exec.Cmd, sig os.Signal) string {
+	if s, ok := sig.(syscall.Signal); ok {
+		return signalApplyProcessGroup(cmd, s)
+	}
+	return terminateApplyProcessGroup(cmd)
+}
+
+func applySignaled(exitErr *exec.ExitError) (string, int, bool) {
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return "", 0, false
+	}
+	return unix.SignalName(status.Signal()), int(status.Signal()), true
+}
@@ -0,0 +1,38 @@
+//go:build windows
+
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+const applyResourceLimitsSupported = false
+
+var applyForwardedSignals = []os.Signal{os.Interrupt}
+
+func configureApplyProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// Windows has no graceful group signal for console-less children, so every
+// stop request is a forced kill.
+func terminateApplyProcessGroup(cmd *exec.Cmd) string {
+	return killApplyProcessGroup(cmd)
+}
+
+func killApplyProcessGroup(cmd *exec.Cmd) string {
+	if cmd.Process != nil {
+		_ = cmd.Process.Kill()
+	}
+	return "KILL"
+}
+
+func forwardApplySignal(cmd *exec.Cmd, _ os.Signal) string {
+	return killApplyProcessGroup(cmd)
+}
+
+func applySignaled(_ *exec.ExitError) (string, int, bool) {
+	return "", 0, false
+}
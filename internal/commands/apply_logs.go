@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+)
+
+// applyOutputLogs captures stdout and stderr of an apply command to sibling log
+// files under the run's command log directory.
+type applyOutputLogs struct {
+	stdout *small.CommandOutputLog
+	stderr *small.CommandOutputLog
+}
+
+// openApplyOutputLogs creates the stdout/stderr logs for a command started at timestamp.
+func openApplyOutputLogs(baseDir, replayId, timestamp string) (*applyOutputLogs, error) {
+	stdout, err := small.CreateCommandOutputLog(baseDir, replayId, timestamp, small.CommandStreamStdout)
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := small.CreateCommandOutputLog(baseDir, replayId, timestamp, small.CommandStreamStderr)
+	if err != nil {
+		_ = stdout.Close()
+		return nil, err
+	}
+	return &applyOutputLogs{stdout: stdout, stderr: stderr}, nil
+}
+
+// tee returns writers that copy each stream to its log as well as the given destination.
+func (l *applyOutputLogs) tee(stdout, stderr io.Writer) (io.Writer, io.Writer) {
+	if l == nil {
+		return stdout, stderr
+	}
+	return io.MultiWriter(stdout, l.stdout), io.MultiWriter(stderr, l.stderr)
+}
+
+func (l *applyOutputLogs) close() error {
+	if l == nil {
+		return nil
+	}
+	errOut := l.stdout.Close()
+	if err := l.stderr.Close(); errOut == nil {
+		errOut = err
+	}
+	return errOut
+}
+
+// annotate records log references, byte counts, and hashes on a progress entry.
+func (l *applyOutputLogs) annotate(entry map[string]any) {
+	if l == nil {
+		return
+	}
+	entry["stdout_ref"] = l.stdout.Ref()
+	entry["stdout_sha256"] = l.stdout.SHA256()
+	entry["stdout_bytes"] = l.stdout.Bytes()
+	entry["stderr_ref"] = l.stderr.Ref()
+	entry["stderr_sha256"] = l.stderr.SHA256()
+	entry["stderr_bytes"] = l.stderr.Bytes()
+}
+
+// lockedBuffer is a bytes.Buffer safe for the concurrent stdout/stderr copies made by exec.Cmd.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// resolveApplyReplayID returns the current run replayId from workspace metadata,
+// falling back to the existing handoff.
+func resolveApplyReplayID(baseDir string) (string, error) {
+	replayId, err := currentWorkspaceRunReplayID(baseDir)
+	if err != nil {
+		return "", err
+	}
+	if replayId == "" {
+		existing, loadErr := loadExistingHandoff(baseDir)
+		if loadErr == nil && existing != nil && existing.ReplayId != nil {
+			replayId = strings.TrimSpace(existing.ReplayId.Value)
+		}
+	}
+	return replayId, nil
+}
@@ -0,0 +1,332 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+)
+
+// applyRecipeOptions carries the apply flags that also shape recipe steps.
+type applyRecipeOptions struct {
+	// Timeout applies to steps whose recipe declares no timeout.
+	Timeout      time.Duration
+	KillGrace    time.Duration
+	Limits       applyResourceLimits
+	AutoProgress bool
+	SkipScope    bool
+	Sandbox      *applySandboxProfile
+	Environment  *applyEnvCapture
+	// Retry re-runs failing steps; steps that expect a non-zero exit are not retried.
+	Retry  applyRetryPolicy
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// applyRecipeStep is a recipe command resolved against the workspace.
+type applyRecipeStep struct {
+	Command    string
+	Dir        string
+	RelDir     string
+	Env        []string
+	ExpectExit int
+	Timeout    time.Duration
+}
+
+// applyRecipeOutcome summarizes a recipe run. Failed is the 1-based index of the
+// step that stopped the recipe, or 0 when every step passed.
+type applyRecipeOutcome struct {
+	Steps    int
+	Passed   int
+	Failed   int
+	ExitCode int
+	Summary  string
+}
+
+func (o applyRecipeOutcome) completed() bool {
+	return o.Failed == 0
+}
+
+// loadTaskRecipe returns the run block for a task, or nil when the plan is missing,
+// the task is unknown, or the task declares no commands.
+func loadTaskRecipe(baseDir, taskID string) (*PlanTaskRun, error) {
+	if !small.ArtifactExists(baseDir, "plan.small.yml") {
+		return nil, nil
+	}
+	plan, err := loadPlan(filepath.Join(baseDir, small.SmallDir, "plan.small.yml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plan.small.yml: %w", err)
+	}
+	task, _ := findTask(plan, taskID)
+	if task == nil || task.Run == nil || len(task.Run.Commands) == 0 {
+		return nil, nil
+	}
+	return task.Run, nil
+}
+
+// resolveApplyRecipe validates a run block and resolves directories, environment,
+// expected exit codes, and timeouts for each step. Step values override run values.
+func resolveApplyRecipe(baseDir string, run *PlanTaskRun, defaultTimeout time.Duration) ([]applyRecipeStep, error) {
+	runTimeout := defaultTimeout
+	if strings.TrimSpace(run.Timeout) != "" {
+		parsed, err := parseRecipeTimeout(run.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("run.timeout: %w", err)
+		}
+		runTimeout = parsed
+	}
+
+	steps := make([]applyRecipeStep, 0, len(run.Commands))
+	for i, command := range run.Commands {
+		field := fmt.Sprintf("run.commands[%d]", i)
+		if strings.TrimSpace(command.Cmd) == "" {
+			return nil, fmt.Errorf("%s.cmd must not be empty", field)
+		}
+
+		relDir, err := resolveWorkspaceRelPath(baseDir, run.Dir, command.Dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s.dir: %w", field, err)
+		}
+
+		timeout := runTimeout
+		if strings.TrimSpace(command.Timeout) != "" {
+			timeout, err = parseRecipeTimeout(command.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("%s.timeout: %w", field, err)
+			}
+		}
+
+		expectExit := 0
+		if command.ExpectExit != nil {
+			expectExit = *command.ExpectExit
+		}
+
+		steps = append(steps, applyRecipeStep{
+			Command:    command.Cmd,
+			Dir:        filepath.Join(baseDir, filepath.FromSlash(relDir)),
+			RelDir:     relDir,
+			Env:        mergeRecipeEnv(run.Env, command.Env),
+			ExpectExit: expectExit,
+			Timeout:    timeout,
+		})
+	}
+	return steps, nil
+}
+
+func parseRecipeTimeout(value string) (time.Duration, error) {
+	parsed, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	if parsed < 0 {
+		return 0, fmt.Errorf("duration must be non-negative")
+	}
+	return parsed, nil
+}
+
+// resolveWorkspaceRelPath joins workspace-relative path parts and keeps the result
+// inside the workspace so recipes and checks stay reproducible across checkouts.
+// The workspace root itself resolves to "".
+func resolveWorkspaceRelPath(baseDir string, parts ...string) (string, error) {
+	joined := baseDir
+	for _, part := range parts {
+		if filepath.IsAbs(part) || strings.HasPrefix(part, "/") {
+			return "", fmt.Errorf("%q must be relative to the workspace root", part)
+		}
+		joined = filepath.Join(joined, filepath.FromSlash(part))
+	}
+	rel, err := filepath.Rel(baseDir, joined)
+	if err != nil {
+		return "", err
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%q escapes the workspace root", path.Join(parts...))
+	}
+	if rel == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// mergeRecipeEnv returns KEY=VALUE pairs sorted by key, with step values overriding run values.
+func mergeRecipeEnv(runEnv, stepEnv map[string]string) []string {
+	merged := map[string]string{}
+	for k, v := range runEnv {
+		merged[k] = v
+	}
+	for k, v := range stepEnv {
+		merged[k] = v
+	}
+	keys := make([]string, 0, len(merged))
+	for k := range merged {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	env := make([]string, 0, len(keys))
+	for _, k := range keys {
+		env = append(env, k+"="+merged[k])
+	}
+	return env
+}
+
+// runApplyRecipe executes the task recipe step by step, recording a progress entry per
+// step and stopping at the first step that fails, times out, exits with an unexpected
+// code, or touches paths outside intent scope. The task is then checkpointed as
+// completed or blocked.
+func runApplyRecipe(baseDir, taskID string, run *PlanTaskRun, opts applyRecipeOptions) (applyRecipeOutcome, error) {
+	steps, err := resolveApplyRecipe(baseDir, run, opts.Timeout)
+	if err != nil {
+		return applyRecipeOutcome{}, fmt.Errorf("invalid run recipe for task %s: %w", taskID, err)
+	}
+	replayId, err := ensureWorkspaceRunReplayID(baseDir)
+	if err != nil {
+		return applyRecipeOutcome{}, err
+	}
+
+	outcome := applyRecipeOutcome{Steps: len(steps)}
+	for i, step := range steps {
+		index := i + 1
+		fmt.Fprintf(opts.Stdout, "Step %d/%d: %s\n", index, len(steps), step.Command)
+
+		timestamp := formatProgressTimestamp(time.Now().UTC())
+		summary, ref, sha, err := applyCommandMetadata(baseDir, timestamp, step.Command)
+		if err != nil {
+			return outcome, err
+		}
+
+		scopeGuard, err := startApplyScopeGuard(baseDir, !opts.SkipScope)
+		if err != nil {
+			return outcome, err
+		}
+
+		var environment map[string]any
+		if opts.Environment != nil {
+			environment = opts.Environment.capture(step.Dir, step.Env)
+		}
+
+		execOpts := applyExecOptions{
+			Command:   step.Command,
+			Dir:       step.Dir,
+			Env:       step.Env,
+			Stdout:    opts.Stdout,
+			Stderr:    opts.Stderr,
+			Timeout:   step.Timeout,
+			KillGrace: opts.KillGrace,
+			Limits:    opts.Limits,
+			Sandbox:   opts.Sandbox,
+		}
+		retry := opts.Retry
+		if step.ExpectExit != 0 {
+			retry = applyRetryPolicy{}
+		}
+		attempts, err := runApplyAttempts(baseDir, replayId, timestamp, execOpts, retry, opts.AutoProgress, opts.Stderr)
+		if err != nil {
+			return outcome, err
+		}
+		final := attempts[len(attempts)-1]
+		result := final.Result
+		outputLogs := final.Logs
+
+		touched, violations, scopeErr := scopeGuard.finish()
+		if scopeErr != nil {
+			fmt.Fprintf(opts.Stderr, "Warning: %v\n", scopeErr)
+		}
+
+		evidence := map[string]any{
+			"step":        index,
+			"steps":       len(steps),
+			"exit_code":   result.ExitCode,
+			"expect_exit": step.ExpectExit,
+		}
+		if step.RelDir != "" {
+			evidence["dir"] = step.RelDir
+		}
+		if opts.Sandbox != nil {
+			evidence["sandbox"] = opts.Sandbox.evidence()
+		}
+		if environment != nil {
+			evidence["environment"] = environment
+		}
+		if retry.enabled() {
+			evidence["attempts"] = applyAttemptsEvidence(attempts)
+			if applyAttemptsFlaky(attempts) {
+				evidence["flaky"] = true
+			}
+		}
+		var failures []string
+		if result.startFailed() {
+			failures = append(failures, fmt.Sprintf("command failed to start: %v", result.Err))
+		}
+		if result.terminated() {
+			failures = append(failures, applyTerminationSummary(result, step.Timeout))
+			evidence["termination"] = applyTerminationEvidence(result, execOpts)
+		} else if len(failures) == 0 && result.ExitCode != step.ExpectExit {
+			failures = append(failures, fmt.Sprintf("exit code %d, expected %d", result.ExitCode, step.ExpectExit))
+		}
+		if len(violations) > 0 {
+			failures = append(failures, scopeViolationSummary(violations))
+			evidence["scope_violation"] = buildScopeViolationEvidence(scopeGuard.scope, violations)
+		}
+		if opts.AutoProgress {
+			evidence["output"] = buildAutoProgressEvidence(final.Output, result.ExitCode)
+		}
+
+		status := "in_progress"
+		if len(failures) == 0 {
+			evidence["summary"] = fmt.Sprintf("Step %d/%d passed (exit code %d)", index, len(steps), result.ExitCode)
+			if applyAttemptsFlaky(attempts) {
+				evidence["summary"] = fmt.Sprintf("Step %d/%d passed on attempt %d (flaky)", index, len(steps), len(attempts))
+			}
+			outcome.Passed++
+		} else {
+			status = "blocked"
+			evidence["summary"] = fmt.Sprintf("Step %d/%d failed: %s", index, len(steps), strings.Join(failures, "; "))
+			outcome.Failed = index
+		}
+
+		entry := map[string]any{
+			"timestamp":       formatProgressTimestamp(time.Now().UTC()),
+			"task_id":         taskID,
+			"status":          status,
+			"evidence":        evidence,
+			"notes":           fmt.Sprintf("apply recipe: step %d/%d (exit code %d)", index, len(steps), result.ExitCode),
+			"command":         summary,
+			"command_summary": summary,
+			"command_ref":     ref,
+			"command_sha256":  sha,
+		}
+		outputLogs.annotate(entry)
+		if !touched.IsEmpty() {
+			entry["touched_paths"] = touched.Map()
+		}
+		if err := appendProgressEntry(baseDir, entry); err != nil {
+			return outcome, fmt.Errorf("failed to record step %d: %w", index, err)
+		}
+
+		outcome.ExitCode = result.ExitCode
+		if len(violations) > 0 {
+			fmt.Fprintf(opts.Stderr, "%s:\n%s", scopeViolationSummary(violations), formatScopeViolations(violations))
+		}
+		if outcome.Failed != 0 {
+			outcome.Summary = fmt.Sprintf("Recipe stopped at step %d/%d: %s", index, len(steps), strings.Join(failures, "; "))
+			break
+		}
+	}
+	if outcome.completed() {
+		outcome.Summary = fmt.Sprintf("Recipe completed: %d/%d steps passed", outcome.Passed, outcome.Steps)
+	}
+
+	checkpointStatus := "completed"
+	if !outcome.completed() {
+		checkpointStatus = "blocked"
+	}
+	if err := runCheckpointApply(baseDir, taskID, checkpointStatus, outcome.Summary); err != nil {
+		return outcome, err
+	}
+	return outcome, nil
+}
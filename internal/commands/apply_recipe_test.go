@@ -0,0 +1,226 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+	"gopkg.in/yaml.v3"
+)
+
+func writeRecipePlan(t *testing.T, dir, run string) {
+	t.Helper()
+	artifacts := defaultArtifacts()
+	artifacts["plan.small.yml"] = `small_version: "1.0.0"
+owner: "agent"
+tasks:
+  - id: "task-1"
+    title: "Test task"
+    run:
+` + run
+	writeArtifacts(t, dir, artifacts)
+	mustSaveWorkspace(t, dir, workspace.KindRepoRoot)
+}
+
+func TestPlanRunCommandYAMLShorthand(t *testing.T) {
+	var run PlanTaskRun
+	input := `commands:
+  - echo one
+  - cmd: exit 3
+    expect_exit: 3
+    timeout: 5s
+`
+	if err := yaml.Unmarshal([]byte(input), &run); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if len(run.Commands) != 2 {
+		t.Fatalf("expected 2 commands, got %d", len(run.Commands))
+	}
+	if run.Commands[0].Cmd != "echo one" || run.Commands[0].ExpectExit != nil {
+		t.Fatalf("unexpected shorthand command: %+v", run.Commands[0])
+	}
+	if run.Commands[1].ExpectExit == nil || *run.Commands[1].ExpectExit != 3 {
+		t.Fatalf("expected expect_exit 3, got %+v", run.Commands[1])
+	}
+
+	out, err := yaml.Marshal(&run)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if !strings.Contains(string(out), "- echo one\n") {
+		t.Fatalf("expected shorthand to round-trip, got:\n%s", out)
+	}
+}
+
+func TestResolveApplyRecipe(t *testing.T) {
+	baseDir := t.TempDir()
+	expect := 2
+	run := &PlanTaskRun{
+		Dir:     "sub",
+		Env:     map[string]string{"A": "run", "B": "run"},
+		Timeout: "10s",
+		Commands: []PlanRunCommand{
+			{Cmd: "true"},
+			{Cmd: "false", Dir: "inner", Env: map[string]string{"B": "step"}, ExpectExit: &expect, Timeout: "1s"},
+		},
+	}
+
+	steps, err := resolveApplyRecipe(baseDir, run, time.Minute)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if steps[0].RelDir != "sub" || steps[0].Timeout != 10*time.Second || steps[0].ExpectExit != 0 {
+		t.Fatalf("unexpected first step: %+v", steps[0])
+	}
+	if steps[1].RelDir != "sub/inner" || steps[1].Timeout != time.Second || steps[1].ExpectExit != 2 {
+		t.Fatalf("unexpected second step: %+v", steps[1])
+	}
+	if got := strings.Join(steps[1].Env, ","); got != "A=run,B=step" {
+		t.Fatalf("env = %q, want A=run,B=step", got)
+	}
+
+	for _, bad := range []*PlanTaskRun{
+		{Commands: []PlanRunCommand{{Cmd: " "}}},
+		{Dir: "../outside", Commands: []PlanRunCommand{{Cmd: "true"}}},
+		{Commands: []PlanRunCommand{{Cmd: "true", Dir: "/abs"}}},
+		{Timeout: "soon", Commands: []PlanRunCommand{{Cmd: "true"}}},
+	} {
+		if _, err := resolveApplyRecipe(baseDir, bad, 0); err == nil {
+			t.Fatalf("expected error for recipe %+v", bad)
+		}
+	}
+}
+
+func TestApplyRunsTaskRecipe(t *testing.T) {
+	t.Setenv(progressModeEnvVar, string(progressModeSignal))
+
+	tmpDir := t.TempDir()
+	writeRecipePlan(t, tmpDir, `      dir: "work"
+      env:
+        GREETING: "hello"
+      commands:
+        - mkdir -p out
+        - cmd: echo "$GREETING" > out/greeting.txt
+`)
+	if err := os.MkdirAll(filepath.Join(tmpDir, "work"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := applyCmd()
+	cmd.SetArgs([]string{"--dir", tmpDir, "--workspace", "any", "--task", "task-1"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("apply execute failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "work", "out", "greeting.txt"))
+	if err != nil || strings.TrimSpace(string(data)) != "hello" {
+		t.Fatalf("expected recipe output file with env value, got %q (%v)", data, err)
+	}
+
+	progress, err := loadProgressData(filepath.Join(tmpDir, ".small", "progress.small.yml"))
+	if err != nil {
+		t.Fatalf("failed to load progress: %v", err)
+	}
+	if len(progress.Entries) != 3 {
+		t.Fatalf("expected 2 step entries and a checkpoint, got %d", len(progress.Entries))
+	}
+	for i, entry := range progress.Entries[:2] {
+		if stringVal(entry["status"]) != "in_progress" {
+			t.Fatalf("step %d status = %q, want in_progress", i+1, stringVal(entry["status"]))
+		}
+		evidence, ok := entry["evidence"].(map[string]any)
+		if !ok || evidence["step"] != i+1 || evidence["dir"] != "work" {
+			t.Fatalf("unexpected step evidence: %#v", entry["evidence"])
+		}
+		if stringVal(entry["command_ref"]) == "" || stringVal(entry["stdout_ref"]) == "" {
+			t.Fatalf("expected command and output log refs on step entry: %#v", entry)
+		}
+	}
+	checkpoint := progress.Entries[2]
+	if stringVal(checkpoint["status"]) != "completed" || stringVal(checkpoint["evidence"]) != "Recipe completed: 2/2 steps passed" {
+		t.Fatalf("unexpected checkpoint entry: %#v", checkpoint)
+	}
+
+	plan, err := loadPlan(filepath.Join(tmpDir, ".small", "plan.small.yml"))
+	if err != nil {
+		t.Fatalf("failed to load plan: %v", err)
+	}
+	if plan.Tasks[0].Status != "completed" || plan.Tasks[0].Run == nil || len(plan.Tasks[0].Run.Commands) != 2 {
+		t.Fatalf("expected task completed with recipe preserved, got %+v", plan.Tasks[0])
+	}
+
+	if code := runVerify(tmpDir, false, true, workspace.ScopeAny); code != ExitValid {
+		t.Fatalf("expected artifacts to verify after recipe, got exit code %d", code)
+	}
+}
+
+func TestApplyTaskRecipeStopsOnFirstFailure(t *testing.T) {
+	t.Setenv(progressModeEnvVar, string(progressModeSignal))
+
+	tmpDir := t.TempDir()
+	writeRecipePlan(t, tmpDir, `      commands:
+        - cmd: exit 3
+          expect_exit: 3
+        - exit 1
+        - touch never.txt
+`)
+
+	cmd := applyCmd()
+	cmd.SetArgs([]string{"--dir", tmpDir, "--workspace", "any", "--task", "task-1"})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "failed at step 2/3") {
+		t.Fatalf("expected recipe failure at step 2, got %v", err)
+	}
+	if _, statErr := os.Stat(filepath.Join(tmpDir, "never.txt")); !os.IsNotExist(statErr) {
+		t.Fatalf("expected step 3 not to run")
+	}
+
+	progress, loadErr := loadProgressData(filepath.Join(tmpDir, ".small", "progress.small.yml"))
+	if loadErr != nil {
+		t.Fatalf("failed to load progress: %v", loadErr)
+	}
+	if len(progress.Entries) != 3 {
+		t.Fatalf("expected 2 step entries and a checkpoint, got %d", len(progress.Entries))
+	}
+	failed := progress.Entries[1]
+	if stringVal(failed["status"]) != "blocked" {
+		t.Fatalf("failed step status = %q, want blocked", stringVal(failed["status"]))
+	}
+	evidence, _ := failed["evidence"].(map[string]any)
+	if summary, _ := evidence["summary"].(string); summary != "Step 2/3 failed: exit code 1, expected 0" {
+		t.Fatalf("unexpected failed step summary: %q", summary)
+	}
+	checkpoint := progress.Entries[2]
+	if stringVal(checkpoint["status"]) != "blocked" || !strings.HasPrefix(stringVal(checkpoint["evidence"]), "Recipe stopped at step 2/3") {
+		t.Fatalf("unexpected checkpoint entry: %#v", checkpoint)
+	}
+
+	plan, planErr := loadPlan(filepath.Join(tmpDir, ".small", "plan.small.yml"))
+	if planErr != nil {
+		t.Fatalf("failed to load plan: %v", planErr)
+	}
+	if plan.Tasks[0].Status != "blocked" {
+		t.Fatalf("task status = %q, want blocked", plan.Tasks[0].Status)
+	}
+}
+
+func TestApplyTaskRecipeDryRun(t *testing.T) {
+	t.Setenv(progressModeEnvVar, string(progressModeSignal))
+
+	tmpDir := t.TempDir()
+	writeRecipePlan(t, tmpDir, `      commands:
+        - touch ran.txt
+`)
+
+	cmd := applyCmd()
+	cmd.SetArgs([]string{"--dir", tmpDir, "--workspace", "any", "--task", "task-1", "--dry-run"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("apply dry-run failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "ran.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected dry-run not to execute the recipe")
+	}
+}
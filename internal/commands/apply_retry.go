@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	defaultApplyRetryBackoff = time.Second
+	maxApplyRetryBackoff     = 5 * time.Minute
+)
+
+// applyRetryPolicy controls how often a failing apply command is re-run.
+type applyRetryPolicy struct {
+	Retries int
+	// Backoff is the delay before the first retry; it doubles for each later retry.
+	Backoff time.Duration
+	// OnExitCodes limits retries to these exit codes; empty retries any failure.
+	OnExitCodes []int
+}
+
+func (p applyRetryPolicy) enabled() bool {
+	return p.Retries > 0
+}
+
+func (p applyRetryPolicy) validate() error {
+	if p.Retries < 0 {
+		return fmt.Errorf("--retries must be non-negative")
+	}
+	if p.Backoff < 0 {
+		return fmt.Errorf("--retry-backoff must be non-negative")
+	}
+	if len(p.OnExitCodes) > 0 && p.Retries == 0 {
+		return fmt.Errorf("--retry-on-exit-codes requires --retries")
+	}
+	for _, code := range p.OnExitCodes {
+		if code == 0 {
+			return fmt.Errorf("--retry-on-exit-codes cannot include 0")
+		}
+	}
+	return nil
+}
+
+func (p applyRetryPolicy) attempts() int {
+	return p.Retries + 1
+}
+
+// shouldRetry reports whether a failed attempt qualifies for another try. Interrupts
+// and commands that could not start are never retried.
+func (p applyRetryPolicy) shouldRetry(result applyExecResult) bool {
+	if result.Err == nil || result.KillReason == applyKillInterrupted || result.startFailed() {
+		return false
+	}
+	if len(p.OnExitCodes) == 0 {
+		return true
+	}
+	for _, code := range p.OnExitCodes {
+		if code == result.ExitCode {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns the wait before the retry that follows the given 1-based attempt.
+// Doubling stops once it would exceed maxApplyRetryBackoff.
+func (p applyRetryPolicy) delay(attempt int) time.Duration {
+	d := p.Backoff
+	for i := 1; i < attempt && d*2 <= maxApplyRetryBackoff; i++ {
+		d *= 2
+	}
+	return d
+}
+
+// applyAttempt is one execution of an apply command.
+type applyAttempt struct {
+	Number int
+	Result applyExecResult
+	Logs   *applyOutputLogs
+	// Output holds the combined output when it is captured for evidence.
+	Output string
+}
+
+// runApplyAttempts runs the command until it succeeds, fails in a way the policy does not
+// retry, or the attempts are exhausted. Each attempt streams to its own output logs named
+// after the attempt's start; the first attempt uses timestamp so it sits beside the command log.
+func runApplyAttempts(baseDir, replayId, timestamp string, opts applyExecOptions, policy applyRetryPolicy, captureOutput bool, notices io.Writer) ([]applyAttempt, error) {
+	var attempts []applyAttempt
+	for number := 1; ; number++ {
+		attemptTimestamp := timestamp
+		if number > 1 {
+			attemptTimestamp = formatProgressTimestamp(time.Now().UTC())
+		}
+
+		attemptOpts := opts
+		var outputBuffer lockedBuffer
+		if captureOutput {
+			attemptOpts.Stdout = &outputBuffer
+			attemptOpts.Stderr = &outputBuffer
+		}
+		var logs *applyOutputLogs
+		if replayId != "" {
+			var err error
+			logs, err = openApplyOutputLogs(baseDir, replayId, attemptTimestamp)
+			if err != nil {
+				return attempts, fmt.Errorf("failed to create output logs: %w", err)
+			}
+		}
+		attemptOpts.Stdout, attemptOpts.Stderr = logs.tee(attemptOpts.Stdout, attemptOpts.Stderr)
+
+		result := runApplyCommand(attemptOpts)
+		if err := logs.close(); err != nil {
+			fmt.Fprintf(notices, "Warning: failed to close output logs: %v\n", err)
+		}
+		attempts = append(attempts, applyAttempt{Number: number, Result: result, Logs: logs, Output: outputBuffer.String()})
+
+		if number >= policy.attempts() || !policy.shouldRetry(result) {
+			return attempts, nil
+		}
+		wait := policy.delay(number)
+		fmt.Fprintf(notices, "Attempt %d/%d failed (exit code %d); retrying in %s\n", number, policy.attempts(), result.ExitCode, wait)
+		time.Sleep(wait)
+	}
+}
+
+// applyAttemptsFlaky reports whether the command succeeded only after a failed attempt.
+func applyAttemptsFlaky(attempts []applyAttempt) bool {
+	return len(attempts) > 1 && attempts[len(attempts)-1].Result.Err == nil
+}
+
+// applyAttemptsSummary describes a multi-attempt run; single attempts need no summary.
+func applyAttemptsSummary(attempts []applyAttempt, policy applyRetryPolicy) string {
+	if len(attempts) < 2 {
+		return ""
+	}
+	if applyAttemptsFlaky(attempts) {
+		return fmt.Sprintf("Command succeeded on attempt %d of %d (flaky)", len(attempts), policy.attempts())
+	}
+	return fmt.Sprintf("Command failed after %d attempts", len(attempts))
+}
+
+// applyAttemptsEvidence records every attempt in the shape stored on progress entries.
+func applyAttemptsEvidence(attempts []applyAttempt) []any {
+	out := make([]any, 0, len(attempts))
+	for _, attempt := range attempts {
+		item := map[string]any{
+			"attempt":     attempt.Number,
+			"exit_code":   attempt.Result.ExitCode,
+			"duration_ms": attempt.Result.Duration.Milliseconds(),
+		}
+		if attempt.Result.KillReason != "" {
+			item["termination"] = attempt.Result.KillReason
+		}
+		if attempt.Logs != nil {
+			item["stdout_ref"] = attempt.Logs.stdout.Ref()
+			item["stderr_ref"] = attempt.Logs.stderr.Ref()
+		}
+		out = append(out, item)
+	}
+	return out
+}
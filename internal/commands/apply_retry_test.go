@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func TestApplyRetryPolicy(t *testing.T) {
+	exitErr := &exec.ExitError{}
+	policy := applyRetryPolicy{Retries: 3, Backoff: time.Second, OnExitCodes: []int{75}}
+	if err := policy.validate(); err != nil {
+		t.Fatalf("validate failed: %v", err)
+	}
+	if !policy.shouldRetry(applyExecResult{Err: exitErr, ExitCode: 75}) {
+		t.Fatal("expected exit code 75 to be retried")
+	}
+	if policy.shouldRetry(applyExecResult{Err: exitErr, ExitCode: 1}) {
+		t.Fatal("expected exit code 1 not to be retried")
+	}
+	if policy.shouldRetry(applyExecResult{Err: exitErr, ExitCode: 75, KillReason: applyKillInterrupted}) {
+		t.Fatal("expected interrupted commands not to be retried")
+	}
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second} {
+		if got := policy.delay(attempt); got != want {
+			t.Errorf("delay(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+	if got := (applyRetryPolicy{Backoff: time.Minute}).delay(10); got != 4*time.Minute {
+		t.Errorf("expected backoff to stop doubling at the cap, got %s", got)
+	}
+
+	for _, invalid := range []applyRetryPolicy{
+		{Retries: -1},
+		{Retries: 1, Backoff: -time.Second},
+		{OnExitCodes: []int{1}},
+		{Retries: 1, OnExitCodes: []int{0}},
+	} {
+		if err := invalid.validate(); err == nil {
+			t.Errorf("expected %+v to be invalid", invalid)
+		}
+	}
+}
+
+func TestApplyRetriesRecordsFlakyAttempts(t *testing.T) {
+	t.Setenv(progressModeEnvVar, string(progressModeSignal))
+
+	tmpDir := t.TempDir()
+	writeArtifacts(t, tmpDir, defaultArtifacts())
+	mustSaveWorkspace(t, tmpDir, workspace.KindRepoRoot)
+
+	// Fails until the third run.
+	counter := filepath.Join(t.TempDir(), "count")
+	command := `n=$(cat ` + counter + ` 2>/dev/null || echo 0); n=$((n+1)); echo $n > ` + counter + `; [ $n -ge 3 ]`
+
+	cmd := applyCmd()
+	cmd.SetArgs([]string{"--dir", tmpDir, "--workspace", "any", "--task", "task-1", "--cmd", command,
+		"--retries", "3", "--retry-backoff", "0"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("apply execute failed: %v", err)
+	}
+
+	progress, err := loadProgressData(filepath.Join(tmpDir, ".small", "progress.small.yml"))
+	if err != nil {
+		t.Fatalf("failed to load progress: %v", err)
+	}
+	completions := 0
+	for _, entry := range progress.Entries {
+		if stringVal(entry["status"]) == "completed" {
+			completions++
+		}
+	}
+	if completions != 1 {
+		t.Fatalf("expected a single completion entry, got %d", completions)
+	}
+	entry := progress.Entries[len(progress.Entries)-1]
+	evidence, ok := entry["evidence"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected structured evidence, got %#v", entry["evidence"])
+	}
+	if evidence["flaky"] != true {
+		t.Fatalf("expected flaky marker, got %#v", evidence["flaky"])
+	}
+	if evidence["summary"] != "Command succeeded on attempt 3 of 4 (flaky)" {
+		t.Fatalf("summary = %#v", evidence["summary"])
+	}
+	attempts, _ := evidence["attempts"].([]any)
+	if len(attempts) != 3 {
+		t.Fatalf("expected 3 attempts, got %#v", evidence["attempts"])
+	}
+	for i, raw := range attempts {
+		attempt := raw.(map[string]any)
+		wantExit := 1
+		if i == 2 {
+			wantExit = 0
+		}
+		if attempt["attempt"] != i+1 || attempt["exit_code"] != wantExit {
+			t.Fatalf("attempt %d = %#v", i+1, attempt)
+		}
+		if _, ok := attempt["duration_ms"]; !ok {
+			t.Fatalf("attempt %d missing duration_ms", i+1)
+		}
+	}
+
+	if code := runVerify(tmpDir, false, true, workspace.ScopeAny); code != ExitValid {
+		t.Fatalf("expected artifacts with attempt evidence to verify, got exit code %d", code)
+	}
+}
+
+func TestRunApplyAttemptsStopsOnUnlistedExitCode(t *testing.T) {
+	policy := applyRetryPolicy{Retries: 3, OnExitCodes: []int{75}}
+	attempts, err := runApplyAttempts(t.TempDir(), "", "", applyExecOptions{Command: "exit 2", Dir: t.TempDir()}, policy, true, &lockedBuffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attempts) != 1 || attempts[0].Result.ExitCode != 2 {
+		t.Fatalf("expected a single failed attempt, got %d", len(attempts))
+	}
+	if applyAttemptsFlaky(attempts) || applyAttemptsSummary(attempts, policy) != "" {
+		t.Fatal("a single attempt is neither flaky nor summarised")
+	}
+}
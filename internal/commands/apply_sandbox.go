@@ -0,0 +1,243 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+)
+
+// Sandbox backends, in order of preference.
+const (
+	applySandboxBwrap   = "bwrap"
+	applySandboxUnshare = "unshare"
+)
+
+// applySandboxProfile describes the namespace sandbox an apply command runs in.
+// The workspace is read-only except for the writable paths derived from
+// intent scope.include; read-only overlays inside them cover scope.exclude and .small/.
+type applySandboxProfile struct {
+	Backend string
+	Network bool
+	Root    string
+	// Writable and ReadOnly are workspace-relative; "" is the workspace root.
+	Writable []string
+	ReadOnly []string
+	// Missing lists include paths that do not exist yet and so cannot be mounted writable.
+	Missing []string
+}
+
+// newApplySandboxProfile derives the mount layout from intent scope and checks that the
+// sandbox backend can actually start with it.
+func newApplySandboxProfile(baseDir string, network bool) (*applySandboxProfile, error) {
+	backend, err := detectApplySandboxBackend()
+	if err != nil {
+		return nil, err
+	}
+	root, err := filepath.Abs(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	scope, _, err := small.LoadIntentScope(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read intent scope: %w", err)
+	}
+
+	profile := &applySandboxProfile{Backend: backend, Network: network, Root: root}
+	profile.layout(scope)
+	if err := profile.probe(); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// layout maps scope patterns to mount points. Glob patterns are approximated by their
+// literal directory prefix; the post-run scope check still applies the exact patterns.
+func (p *applySandboxProfile) layout(scope small.IntentScope) {
+	var writable []string
+	if len(scope.Include) == 0 {
+		writable = []string{""}
+	}
+	for _, pattern := range scope.Include {
+		prefix := small.ScopeLiteralPrefix(pattern)
+		if prefix != "" && !p.exists(prefix) {
+			p.Missing = append(p.Missing, prefix)
+			continue
+		}
+		writable = append(writable, prefix)
+	}
+	p.Writable = collapseSandboxPaths(writable)
+
+	var readOnly []string
+	for _, pattern := range append([]string{small.SmallDir}, scope.Exclude...) {
+		prefix := small.ScopeLiteralPrefix(pattern)
+		if prefix == "" || !p.exists(prefix) || !p.isWritable(prefix) {
+			continue
+		}
+		readOnly = append(readOnly, prefix)
+	}
+	p.ReadOnly = collapseSandboxPaths(readOnly)
+	sort.Strings(p.Missing)
+}
+
+func (p *applySandboxProfile) exists(rel string) bool {
+	_, err := os.Lstat(p.abs(rel))
+	return err == nil
+}
+
+func (p *applySandboxProfile) isWritable(rel string) bool {
+	for _, w := range p.Writable {
+		if w == "" || rel == w || strings.HasPrefix(rel, w+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *applySandboxProfile) rootWritable() bool {
+	return len(p.Writable) == 1 && p.Writable[0] == ""
+}
+
+func (p *applySandboxProfile) abs(rel string) string {
+	if rel == "" {
+		return p.Root
+	}
+	return filepath.Join(p.Root, filepath.FromSlash(rel))
+}
+
+// collapseSandboxPaths sorts and deduplicates paths, dropping any nested in another.
+func collapseSandboxPaths(paths []string) []string {
+	sort.Strings(paths)
+	var out []string
+	for _, rel := range paths {
+		covered := false
+		for _, kept := range out {
+			if kept == "" || rel == kept || strings.HasPrefix(rel, kept+"/") {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			out = append(out, rel)
+		}
+	}
+	return out
+}
+
+// command wraps argv so it runs inside the sandbox with dir as its working directory.
+func (p *applySandboxProfile) command(dir string, argv []string) []string {
+	if p.Backend == applySandboxBwrap {
+		return p.bwrapCommand(dir, argv)
+	}
+	return p.unshareCommand(dir, argv)
+}
+
+// bwrapCommand mounts the host root read-only with a private /tmp, then layers the
+// workspace, its writable paths, and the read-only overlays on top.
+func (p *applySandboxProfile) bwrapCommand(dir string, argv []string) []string {
+	args := []string{applySandboxBwrap, "--die-with-parent", "--unshare-user", "--unshare-ipc"}
+	if !p.Network {
+		args = append(args, "--unshare-net")
+	}
+	args = append(args, "--ro-bind", "/", "/", "--dev", "/dev", "--tmpfs", "/tmp")
+	if p.rootWritable() {
+		args = append(args, "--bind", p.Root, p.Root)
+	} else {
+		args = append(args, "--ro-bind", p.Root, p.Root)
+		for _, rel := range p.Writable {
+			args = append(args, "--bind", p.abs(rel), p.abs(rel))
+		}
+	}
+	for _, rel := range p.ReadOnly {
+		args = append(args, "--ro-bind", p.abs(rel), p.abs(rel))
+	}
+	args = append(args, "--chdir", dir, "--")
+	return append(args, argv...)
+}
+
+// unshareCommand enters new user and mount (and network) namespaces and remounts the
+// workspace with a shell script. Writable paths are bound to themselves first so the
+// recursive read-only bind of the workspace keeps them writable.
+func (p *applySandboxProfile) unshareCommand(dir string, argv []string) []string {
+	var script strings.Builder
+	script.WriteString("set -e\nmount --make-rprivate /\n")
+	if !p.rootWritable() {
+		for _, rel := range p.Writable {
+			fmt.Fprintf(&script, "mount --bind %s %s\n", shellQuote(p.abs(rel)), shellQuote(p.abs(rel)))
+		}
+		fmt.Fprintf(&script, "mount --rbind %s %s\n", shellQuote(p.Root), shellQuote(p.Root))
+		fmt.Fprintf(&script, "mount -o remount,bind,ro %s\n", shellQuote(p.Root))
+	}
+	for _, rel := range p.ReadOnly {
+		fmt.Fprintf(&script, "mount --bind %s %s\n", shellQuote(p.abs(rel)), shellQuote(p.abs(rel)))
+		fmt.Fprintf(&script, "mount -o remount,bind,ro %s\n", shellQuote(p.abs(rel)))
+	}
+	fmt.Fprintf(&script, "cd %s\nexec \"$@\"\n", shellQuote(dir))
+
+	args := []string{applySandboxUnshare, "--user", "--map-root-user", "--mount"}
+	if !p.Network {
+		args = append(args, "--net")
+	}
+	args = append(args, "sh", "-c", script.String(), "small-sandbox")
+	return append(args, argv...)
+}
+
+// probe starts the sandbox with a no-op command so an unusable backend is reported
+// before the real command runs rather than as a command failure.
+func (p *applySandboxProfile) probe() error {
+	argv := p.command(p.Root, []string{"true"})
+	output, err := exec.Command(argv[0], argv[1:]...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(output)); msg != "" {
+			return fmt.Errorf("sandbox backend %s unavailable: %s", p.Backend, msg)
+		}
+		return fmt.Errorf("sandbox backend %s unavailable: %w", p.Backend, err)
+	}
+	return nil
+}
+
+func (p *applySandboxProfile) summary() string {
+	network := "off"
+	if p.Network {
+		network = "on"
+	}
+	return fmt.Sprintf("Sandboxed with %s (network %s)", p.Backend, network)
+}
+
+// evidence returns the profile in the shape recorded on progress entries.
+func (p *applySandboxProfile) evidence() map[string]any {
+	hostRoot := "read-write"
+	if p.Backend == applySandboxBwrap {
+		hostRoot = "read-only"
+	}
+	out := map[string]any{
+		"backend":   p.Backend,
+		"network":   p.Network,
+		"host_root": hostRoot,
+		"writable":  small.StringsToAny(sandboxDisplayPaths(p.Writable)),
+		"read_only": small.StringsToAny(sandboxDisplayPaths(p.ReadOnly)),
+	}
+	if len(p.Missing) > 0 {
+		out["missing"] = small.StringsToAny(p.Missing)
+	}
+	return out
+}
+
+func sandboxDisplayPaths(paths []string) []string {
+	out := make([]string, len(paths))
+	for i, rel := range paths {
+		if rel == "" {
+			rel = "."
+		}
+		out[i] = rel
+	}
+	return out
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,18 @@
+//go:build linux
+
+package commands
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// detectApplySandboxBackend prefers bubblewrap and falls back to util-linux unshare.
+func detectApplySandboxBackend() (string, error) {
+	for _, backend := range []string{applySandboxBwrap, applySandboxUnshare} {
+		if _, err := exec.LookPath(backend); err == nil {
+			return backend, nil
+		}
+	}
+	return "", fmt.Errorf("--sandbox requires bwrap or unshare in PATH")
+}
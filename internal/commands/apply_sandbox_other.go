@@ -0,0 +1,9 @@
+//go:build !linux
+
+package commands
+
+import "fmt"
+
+func detectApplySandboxBackend() (string, error) {
+	return "", fmt.Errorf("--sandbox is only supported on Linux")
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func TestApplySandboxLayout(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{".small", "src/gen", "docs"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	profile := &applySandboxProfile{Backend: applySandboxUnshare, Root: root}
+	profile.layout(small.IntentScope{
+		Include: []string{"src/", "src/**/*.go", "docs/guide", "new/"},
+		Exclude: []string{"src/gen/", "docs/", "**/*.lock"},
+	})
+	if !reflect.DeepEqual(profile.Writable, []string{"src"}) {
+		t.Fatalf("writable = %v, want [src]", profile.Writable)
+	}
+	if !reflect.DeepEqual(profile.ReadOnly, []string{"src/gen"}) {
+		t.Fatalf("read_only = %v, want [src/gen]", profile.ReadOnly)
+	}
+	if !reflect.DeepEqual(profile.Missing, []string{"docs/guide", "new"}) {
+		t.Fatalf("missing = %v, want [docs/guide new]", profile.Missing)
+	}
+
+	open := &applySandboxProfile{Backend: applySandboxBwrap, Root: root}
+	open.layout(small.IntentScope{})
+	if !open.rootWritable() {
+		t.Fatalf("expected empty include to make the workspace writable, got %v", open.Writable)
+	}
+	if !reflect.DeepEqual(open.ReadOnly, []string{".small"}) {
+		t.Fatalf("expected .small to stay read-only, got %v", open.ReadOnly)
+	}
+}
+
+func TestApplySandboxCommands(t *testing.T) {
+	profile := &applySandboxProfile{
+		Backend:  applySandboxBwrap,
+		Root:     "/work",
+		Writable: []string{"src"},
+		ReadOnly: []string{"src/gen"},
+	}
+	got := strings.Join(profile.command("/work/src", []string{"sh", "-lc", "make"}), " ")
+	for _, want := range []string{
+		"--unshare-net",
+		"--ro-bind / /",
+		"--ro-bind /work /work --bind /work/src /work/src --ro-bind /work/src/gen /work/src/gen",
+		"--chdir /work/src -- sh -lc make",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("bwrap command missing %q:\n%s", want, got)
+		}
+	}
+
+	profile.Backend = applySandboxUnshare
+	profile.Network = true
+	argv := profile.command("/work/src", []string{"sh", "-lc", "make"})
+	if argv[0] != applySandboxUnshare || strings.Contains(strings.Join(argv[:5], " "), "--net") {
+		t.Fatalf("unexpected unshare prefix: %v", argv[:5])
+	}
+	script := argv[len(argv)-5]
+	for _, want := range []string{
+		"mount --bind '/work/src' '/work/src'\nmount --rbind '/work' '/work'\nmount -o remount,bind,ro '/work'\n",
+		"mount -o remount,bind,ro '/work/src/gen'\n",
+		"cd '/work/src'\nexec \"$@\"\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Fatalf("unshare script missing %q:\n%s", want, script)
+		}
+	}
+	if tail := argv[len(argv)-3:]; !reflect.DeepEqual(tail, []string{"sh", "-lc", "make"}) {
+		t.Fatalf("expected wrapped argv at the end, got %v", tail)
+	}
+}
+
+func TestApplySandboxEnforcesScope(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("sandbox is Linux-only")
+	}
+	t.Setenv(progressModeEnvVar, string(progressModeSignal))
+
+	tmpDir := t.TempDir()
+	artifacts := defaultArtifacts()
+	artifacts["intent.small.yml"] = strings.Replace(artifacts["intent.small.yml"], "include: []", `include: ["src/"]`, 1)
+	writeArtifacts(t, tmpDir, artifacts)
+	mustSaveWorkspace(t, tmpDir, workspace.KindRepoRoot)
+	if err := os.MkdirAll(filepath.Join(tmpDir, "src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newApplySandboxProfile(tmpDir, false); err != nil {
+		t.Skipf("sandbox unavailable: %v", err)
+	}
+
+	cmd := applyCmd()
+	cmd.SetArgs([]string{"--dir", tmpDir, "--workspace", "any", "--task", "task-1", "--sandbox",
+		"--cmd", "echo ok > src/out.txt; { echo no > outside.txt; } 2>/dev/null; true"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("apply execute failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "src", "out.txt")); err != nil {
+		t.Fatalf("expected in-scope write to succeed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "outside.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected out-of-scope write to be blocked by the sandbox")
+	}
+
+	progress, err := loadProgressData(filepath.Join(tmpDir, ".small", "progress.small.yml"))
+	if err != nil {
+		t.Fatalf("failed to load progress: %v", err)
+	}
+	entry := progress.Entries[len(progress.Entries)-1]
+	evidence, ok := entry["evidence"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected structured evidence, got %#v", entry["evidence"])
+	}
+	sandbox, ok := evidence["sandbox"].(map[string]any)
+	if !ok || sandbox["network"] != false {
+		t.Fatalf("expected sandbox profile in evidence, got %#v", evidence["sandbox"])
+	}
+	if writable, _ := sandbox["writable"].([]any); len(writable) != 1 || writable[0] != "src" {
+		t.Fatalf("sandbox.writable = %#v, want [src]", sandbox["writable"])
+	}
+	if !strings.HasPrefix(stringVal(evidence["summary"]), "Command completed successfully; Sandboxed with ") {
+		t.Fatalf("unexpected summary: %q", stringVal(evidence["summary"]))
+	}
+}
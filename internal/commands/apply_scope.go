@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+)
+
+// applyScopeGuard snapshots the workspace before an apply command runs so the
+// touched paths can be diffed afterwards and checked against intent scope.
+type applyScopeGuard struct {
+	baseDir  string
+	scope    small.IntentScope
+	hasScope bool
+	enforce  bool
+	before   small.WorkspaceSnapshot
+}
+
+func startApplyScopeGuard(baseDir string, enforce bool) (*applyScopeGuard, error) {
+	scope, hasScope, err := small.LoadIntentScope(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read intent scope: %w", err)
+	}
+	before, err := small.SnapshotWorkspace(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot workspace before apply: %w", err)
+	}
+	return &applyScopeGuard{
+		baseDir:  baseDir,
+		scope:    scope,
+		hasScope: hasScope,
+		enforce:  enforce,
+		before:   before,
+	}, nil
+}
+
+// finish snapshots the workspace again and returns the touched paths plus any
+// scope violations. Violations are only reported when enforcement is enabled.
+func (g *applyScopeGuard) finish() (small.TouchedPaths, []small.ScopeViolation, error) {
+	after, err := small.SnapshotWorkspace(g.baseDir)
+	if err != nil {
+		return small.TouchedPaths{}, nil, fmt.Errorf("failed to snapshot workspace after apply: %w", err)
+	}
+	touched := small.DiffWorkspaceSnapshots(g.before, after)
+	if !g.enforce || !g.hasScope {
+		return touched, nil, nil
+	}
+	return touched, g.scope.Violations(touched.All()), nil
+}
+
+func scopeViolationSummary(violations []small.ScopeViolation) string {
+	return fmt.Sprintf("Scope violation: %d path(s) outside intent scope", len(violations))
+}
+
+func buildScopeViolationEvidence(scope small.IntentScope, violations []small.ScopeViolation) map[string]any {
+	paths := make([]any, 0, len(violations))
+	for _, violation := range violations {
+		item := map[string]any{
+			"path":   violation.Path,
+			"reason": violation.Reason,
+		}
+		if violation.Pattern != "" {
+			item["pattern"] = violation.Pattern
+		}
+		paths = append(paths, item)
+	}
+	return map[string]any{
+		"include": small.StringsToAny(scope.Include),
+		"exclude": small.StringsToAny(scope.Exclude),
+		"paths":   paths,
+	}
+}
+
+func formatScopeViolations(violations []small.ScopeViolation) string {
+	var b strings.Builder
+	for _, violation := range violations {
+		fmt.Fprintf(&b, "  - %s\n", describeScopeViolation(violation))
+	}
+	return b.String()
+}
+
+func describeScopeViolation(violation small.ScopeViolation) string {
+	if violation.Reason == small.ScopeReasonExcluded {
+		return fmt.Sprintf("%s (matches scope.exclude %q)", violation.Path, violation.Pattern)
+	}
+	return fmt.Sprintf("%s (not covered by scope.include)", violation.Path)
+}
@@ -375,3 +375,141 @@ func TestApplyProgressSignalModeDeterministicShape(t *testing.T) {
 		}
 	}
 }
+
+func TestApplyRecordsTouchedPaths(t *testing.T) {
+	t.Setenv(progressModeEnvVar, string(progressModeSignal))
+
+	tmpDir := t.TempDir()
+	writeArtifacts(t, tmpDir, defaultArtifacts())
+	mustSaveWorkspace(t, tmpDir, workspace.KindRepoRoot)
+
+	cmd := applyCmd()
+	cmd.SetArgs([]string{"--dir", tmpDir, "--workspace", "any", "--task", "task-1", "--cmd", "mkdir -p src && echo hi > src/out.txt"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("apply execute failed: %v", err)
+	}
+
+	progress, err := loadProgressData(filepath.Join(tmpDir, ".small", "progress.small.yml"))
+	if err != nil {
+		t.Fatalf("failed to load progress: %v", err)
+	}
+	if len(progress.Entries) != 1 {
+		t.Fatalf("expected 1 progress entry, got %d", len(progress.Entries))
+	}
+	entry := progress.Entries[0]
+	if stringVal(entry["status"]) != "completed" {
+		t.Fatalf("status = %q, want completed", stringVal(entry["status"]))
+	}
+	touched, ok := entry["touched_paths"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected touched_paths object, got %#v", entry["touched_paths"])
+	}
+	created, _ := touched["created"].([]any)
+	if len(created) != 1 || created[0] != "src/out.txt" {
+		t.Fatalf("touched_paths.created = %#v, want [src/out.txt]", touched["created"])
+	}
+
+	if code := runVerify(tmpDir, false, true, workspace.ScopeAny); code != ExitValid {
+		t.Fatalf("expected artifacts to verify after apply, got exit code %d", code)
+	}
+}
+
+func TestApplyBlocksScopeViolation(t *testing.T) {
+	t.Setenv(progressModeEnvVar, string(progressModeSignal))
+
+	tmpDir := t.TempDir()
+	artifacts := cloneArtifacts(defaultArtifacts())
+	artifacts["intent.small.yml"] = `small_version: "1.0.0"
+owner: "human"
+intent: "Test intent"
+scope:
+  include:
+    - "src/"
+  exclude:
+    - "src/generated/"
+success_criteria: []
+`
+	writeArtifacts(t, tmpDir, artifacts)
+	mustSaveWorkspace(t, tmpDir, workspace.KindRepoRoot)
+
+	cmd := applyCmd()
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	cmd.SetArgs([]string{"--dir", tmpDir, "--workspace", "any", "--task", "task-1", "--cmd", "mkdir -p src/generated && touch src/ok.go src/generated/api.go README.md"})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "scope violation") {
+		t.Fatalf("expected scope violation error, got %v", err)
+	}
+
+	progress, err := loadProgressData(filepath.Join(tmpDir, ".small", "progress.small.yml"))
+	if err != nil {
+		t.Fatalf("failed to load progress: %v", err)
+	}
+	if len(progress.Entries) != 1 {
+		t.Fatalf("expected 1 progress entry, got %d", len(progress.Entries))
+	}
+	entry := progress.Entries[0]
+	if stringVal(entry["status"]) != "blocked" {
+		t.Fatalf("status = %q, want blocked", stringVal(entry["status"]))
+	}
+	evidence, ok := entry["evidence"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected structured evidence, got %#v", entry["evidence"])
+	}
+	violation, ok := evidence["scope_violation"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected scope_violation evidence, got %#v", evidence)
+	}
+	paths, _ := violation["paths"].([]any)
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 violating paths, got %#v", violation["paths"])
+	}
+	first, _ := paths[0].(map[string]any)
+	second, _ := paths[1].(map[string]any)
+	if first["path"] != "README.md" || first["reason"] != small.ScopeReasonOutsideInclude {
+		t.Fatalf("unexpected first violation: %#v", first)
+	}
+	if second["path"] != "src/generated/api.go" || second["reason"] != small.ScopeReasonExcluded {
+		t.Fatalf("unexpected second violation: %#v", second)
+	}
+
+	if code := runVerify(tmpDir, false, true, workspace.ScopeAny); code != ExitValid {
+		t.Fatalf("expected artifacts to verify after blocked apply, got exit code %d", code)
+	}
+}
+
+func TestApplySkipScopeCheck(t *testing.T) {
+	t.Setenv(progressModeEnvVar, string(progressModeSignal))
+
+	tmpDir := t.TempDir()
+	artifacts := cloneArtifacts(defaultArtifacts())
+	artifacts["intent.small.yml"] = `small_version: "1.0.0"
+owner: "human"
+intent: "Test intent"
+scope:
+  include:
+    - "src/"
+  exclude: []
+success_criteria: []
+`
+	writeArtifacts(t, tmpDir, artifacts)
+	mustSaveWorkspace(t, tmpDir, workspace.KindRepoRoot)
+
+	cmd := applyCmd()
+	cmd.SetArgs([]string{"--dir", tmpDir, "--workspace", "any", "--task", "task-1", "--skip-scope-check", "--cmd", "touch README.md"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("apply execute failed: %v", err)
+	}
+
+	progress, err := loadProgressData(filepath.Join(tmpDir, ".small", "progress.small.yml"))
+	if err != nil {
+		t.Fatalf("failed to load progress: %v", err)
+	}
+	entry := progress.Entries[len(progress.Entries)-1]
+	if stringVal(entry["status"]) != "completed" {
+		t.Fatalf("status = %q, want completed", stringVal(entry["status"]))
+	}
+	if _, ok := entry["touched_paths"].(map[string]any); !ok {
+		t.Fatalf("expected touched_paths to be recorded with --skip-scope-check")
+	}
+}
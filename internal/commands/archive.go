@@ -44,6 +44,7 @@ func archiveCmd() *cobra.Command {
 		"constraints.small.yml",
 		"plan.small.yml",
 		"progress.small.yml",
+		small.ProgressLedgerFileName,
 		"handoff.small.yml",
 		"workspace.small.yml",
 	}
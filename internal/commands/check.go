@@ -15,11 +15,17 @@ type checkStageResult struct {
 	Errors []string `json:"errors,omitempty"`
 }
 
+type checkScopeResult struct {
+	checkStageResult
+	scopeDriftReport
+}
+
 type checkOutput struct {
-	Validate checkStageResult `json:"validate"`
-	Lint     checkStageResult `json:"lint"`
-	Verify   checkStageResult `json:"verify"`
-	ExitCode int              `json:"exit_code"`
+	Validate checkStageResult  `json:"validate"`
+	Lint     checkStageResult  `json:"lint"`
+	Scope    *checkScopeResult `json:"scope,omitempty"`
+	Verify   checkStageResult  `json:"verify"`
+	ExitCode int               `json:"exit_code"`
 }
 
 func checkCmd() *cobra.Command {
@@ -29,6 +35,7 @@ func checkCmd() *cobra.Command {
 	var workspaceFlag string
 	var jsonOutput bool
 	var formatStrict bool
+	var scopeBase string
 
 	cmd := &cobra.Command{
 		Use:   "check",
@@ -44,7 +51,8 @@ func checkCmd() *cobra.Command {
 				os.Exit(ExitSystemError)
 			}
 
-			code, output, err := runCheck(dir, strict, ci, jsonOutput, scope, formatStrict)
+			opts := verifyOptions{strict: strict, ci: ci, scope: scope, scopeBase: scopeBase}
+			code, output, err := runCheckWithOptions(dir, opts, jsonOutput, formatStrict)
 			if err != nil {
 				p.PrintError(fmt.Sprintf("Error: %v", err))
 				os.Exit(ExitSystemError)
@@ -71,11 +79,17 @@ func checkCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
 	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
 	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root, examples, or any)")
+	cmd.Flags().StringVar(&scopeBase, "scope-base", "", "Git ref to diff against for intent scope drift")
 
 	return cmd
 }
 
 func runCheck(dir string, strict, ci, jsonOutput bool, scope workspace.Scope, formatStrict bool) (int, checkOutput, error) {
+	return runCheckWithOptions(dir, verifyOptions{strict: strict, ci: ci, scope: scope}, jsonOutput, formatStrict)
+}
+
+func runCheckWithOptions(dir string, opts verifyOptions, jsonOutput, formatStrict bool) (int, checkOutput, error) {
+	strict, ci, scope := opts.strict, opts.ci, opts.scope
 	artifactsDir := resolveArtifactsDir(dir)
 	p := currentPrinter()
 	if scope != workspace.ScopeAny {
@@ -176,6 +190,13 @@ func runCheck(dir string, strict, ci, jsonOutput bool, scope workspace.Scope, fo
 		}
 	}
 
+	if opts.scopeBase != "" {
+		code, err := runCheckScopeDrift(artifactsDir, opts.scopeBase, ci, jsonOutput, &result)
+		if err != nil || code != ExitValid {
+			return code, result, err
+		}
+	}
+
 	verifyCi := ci || jsonOutput
 	verifyCode := runVerify(artifactsDir, strict, verifyCi, scope)
 	if verifyCode != ExitValid {
@@ -187,6 +208,46 @@ func runCheck(dir string, strict, ci, jsonOutput bool, scope workspace.Scope, fo
 	return ExitValid, result, nil
 }
 
+func runCheckScopeDrift(artifactsDir, base string, ci, jsonOutput bool, result *checkOutput) (int, error) {
+	p := currentPrinter()
+	result.Scope = &checkScopeResult{checkStageResult: checkStageResult{Status: "ok"}}
+
+	intentScope, hasIntent, err := small.LoadIntentScope(artifactsDir)
+	if err == nil && hasIntent {
+		var report scopeDriftReport
+		report, err = evaluateScopeDrift(artifactsDir, intentScope, base)
+		result.Scope.scopeDriftReport = report
+	}
+	if err != nil {
+		result.Scope.Status = "error"
+		result.Scope.Errors = []string{err.Error()}
+		result.ExitCode = ExitSystemError
+		return ExitSystemError, err
+	}
+
+	if len(result.Scope.Violations) == 0 {
+		return ExitValid, nil
+	}
+
+	result.Scope.Status = "failed"
+	var lines []string
+	for _, violation := range result.Scope.Violations {
+		msg := describeScopeViolation(violation)
+		result.Scope.Errors = append(result.Scope.Errors, msg)
+		lines = append(lines, msg)
+	}
+	result.ExitCode = ExitInvalid
+	if !ci && !jsonOutput {
+		lines = append(lines, "", "Fix: revert the change, or have a human update scope in intent.small.yml")
+		p.PrintError(p.FormatBlock(fmt.Sprintf("Scope drift since %s (%d violation(s))", base, len(result.Scope.Violations)), lines))
+	} else if ci && !jsonOutput {
+		for _, msg := range result.Scope.Errors {
+			p.PrintError(fmt.Sprintf("Scope drift since %s: %s", base, msg))
+		}
+	}
+	return ExitInvalid, nil
+}
+
 func outputCheckJSON(payload any) error {
 	data, err := json.MarshalIndent(payload, "", "  ")
 	if err != nil {
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/justyn-clark/small-protocol/internal/small"
 	"github.com/justyn-clark/small-protocol/internal/workspace"
@@ -14,15 +15,16 @@ import (
 )
 
 type checkpointOutput struct {
-	TaskID       string         `json:"task_id"`
-	Status       string         `json:"status"`
-	Progress     map[string]any `json:"progress_entry"`
-	Files        []string       `json:"files"`
-	Validated    bool           `json:"validated"`
-	Workspace    string         `json:"workspace"`
-	PlanStatus   string         `json:"plan_status"`
-	Checkpoint   string         `json:"checkpoint"`
-	CheckpointAt string         `json:"checkpoint_at"`
+	TaskID       string            `json:"task_id"`
+	Status       string            `json:"status"`
+	Progress     map[string]any    `json:"progress_entry"`
+	Files        []string          `json:"files"`
+	Validated    bool              `json:"validated"`
+	Workspace    string            `json:"workspace"`
+	PlanStatus   string            `json:"plan_status"`
+	Checkpoint   string            `json:"checkpoint"`
+	CheckpointAt string            `json:"checkpoint_at"`
+	Acceptance   *acceptanceReport `json:"acceptance,omitempty"`
 }
 
 func checkpointCmd() *cobra.Command {
@@ -35,6 +37,8 @@ func checkpointCmd() *cobra.Command {
 	var dir string
 	var workspaceFlag string
 	var jsonOutput bool
+	var verifyAcceptance bool
+	var ifMatch string
 
 	cmd := &cobra.Command{
 		Use:   "checkpoint",
@@ -65,6 +69,10 @@ func checkpointCmd() *cobra.Command {
 				return fmt.Errorf("invalid status %q (must be completed or blocked)", status)
 			}
 
+			if verifyAcceptance && status != "completed" {
+				return fmt.Errorf("--verify-acceptance requires --status completed")
+			}
+
 			planPath := filepath.Join(smallDir, "plan.small.yml")
 			progressPath := filepath.Join(smallDir, "progress.small.yml")
 
@@ -72,6 +80,41 @@ func checkpointCmd() *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("failed to load plan.small.yml: %w", err)
 			}
+			if err := ensureTaskLeaseHolder(plan, strings.TrimSpace(taskID), currentAgentID(), time.Now()); err != nil {
+				return err
+			}
+
+			// Acceptance checks run before anything is written so a failing check leaves
+			// plan and progress untouched.
+			var acceptance *acceptanceReport
+			if verifyAcceptance {
+				report, err := verifyTaskAcceptance(artifactsDir, plan, strings.TrimSpace(taskID))
+				if err != nil {
+					return err
+				}
+				if !report.Passed {
+					printAcceptanceReport(os.Stderr, report)
+					executable, passed := report.counts()
+					return fmt.Errorf("refusing to mark %s completed: %d of %d acceptance checks failed", taskID, executable-passed, executable)
+				}
+				acceptance = &report
+			}
+
+			// The lock and revision check come after the acceptance checks so the lock is
+			// not held while they run; the plan is then reloaded under the lock so the
+			// update applies to what other writers left.
+			unlock, err := lockArtifactRevision(artifactsDir, "plan.small.yml", ifMatch)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+			if plan, err = loadPlan(planPath); err != nil {
+				return fmt.Errorf("failed to load plan.small.yml: %w", err)
+			}
+			if err := ensureTaskLeaseHolder(plan, strings.TrimSpace(taskID), currentAgentID(), time.Now()); err != nil {
+				return err
+			}
+
 			progress, err := loadProgressData(progressPath)
 			if err != nil {
 				return fmt.Errorf("failed to load progress.small.yml: %w", err)
@@ -94,9 +137,9 @@ func checkpointCmd() *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("failed to snapshot plan.small.yml: %w", err)
 			}
-			originalProgressData, err := yaml.Marshal(&progress)
+			originalProgress, err := snapshotProgress(artifactsDir)
 			if err != nil {
-				return fmt.Errorf("failed to snapshot progress.small.yml: %w", err)
+				return err
 			}
 
 			if err := setTaskStatus(plan, taskID, status); err != nil {
@@ -117,6 +160,9 @@ func checkpointCmd() *cobra.Command {
 			if strings.TrimSpace(evidence) == "" {
 				entry["evidence"] = "Recorded checkpoint via small checkpoint"
 			}
+			if acceptance != nil {
+				entry["verification"] = acceptance.verification()
+			}
 
 			if err := validateProgressEntry(entry); err != nil {
 				return err
@@ -126,21 +172,21 @@ func checkpointCmd() *cobra.Command {
 				return err
 			}
 
-			if err := appendProgressEntryWithData(artifactsDir, entry, progress); err != nil {
+			if err := appendProgressEntryLocked(artifactsDir, entry); err != nil {
 				_ = os.WriteFile(planPath, originalPlanData, 0o644)
-				_ = os.WriteFile(progressPath, originalProgressData, 0o644)
+				originalProgress.restore()
 				return err
 			}
 
 			if err := savePlan(planPath, plan); err != nil {
 				_ = os.WriteFile(planPath, originalPlanData, 0o644)
-				_ = os.WriteFile(progressPath, originalProgressData, 0o644)
+				originalProgress.restore()
 				return err
 			}
 
 			if err := validateCheckpointArtifacts(artifactsDir); err != nil {
 				_ = os.WriteFile(planPath, originalPlanData, 0o644)
-				_ = os.WriteFile(progressPath, originalProgressData, 0o644)
+				originalProgress.restore()
 				return err
 			}
 
@@ -163,6 +209,7 @@ func checkpointCmd() *cobra.Command {
 				PlanStatus:   status,
 				Checkpoint:   fmt.Sprintf("checkpoint: %s -> %s at %s", taskID, status, checkpointTimestamp),
 				CheckpointAt: checkpointTimestamp,
+				Acceptance:   acceptance,
 			}
 
 			if jsonOutput {
@@ -174,6 +221,9 @@ func checkpointCmd() *cobra.Command {
 				return nil
 			}
 
+			if acceptance != nil {
+				printAcceptanceReport(os.Stdout, *acceptance)
+			}
 			fmt.Printf("checkpoint: %s -> %s at %s\n", taskID, status, stringVal(entry["timestamp"]))
 			return nil
 		},
@@ -188,6 +238,8 @@ func checkpointCmd() *cobra.Command {
 	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
 	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root, examples, or any)")
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	cmd.Flags().BoolVar(&verifyAcceptance, "verify-acceptance", false, "Run the task's acceptance checks and refuse completion unless all pass")
+	registerIfMatchFlag(cmd, &ifMatch, "plan.small.yml")
 
 	_ = cmd.MarkFlagRequired("task")
 	_ = cmd.MarkFlagRequired("status")
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/justyn-clark/small-protocol/internal/small"
 	"github.com/spf13/cobra"
@@ -190,7 +191,7 @@ func runDoctor(dir string) []DiagnosticResult {
 	// Check 5: Version consistency
 	for artifactType, artifact := range artifacts {
 		if version, ok := artifact.Data["small_version"].(string); ok {
-			if version != small.ProtocolVersion {
+			if !small.ArtifactVersionSupported(artifactType, version) {
 				results = append(results, DiagnosticResult{
 					Category:   "Version",
 					Status:     "warning",
@@ -214,7 +215,7 @@ func analyzeRunState(artifacts map[string]*small.Artifact) []DiagnosticResult {
 	if plan, ok := artifacts["plan"]; ok {
 		tasks, _ := plan.Data["tasks"].([]any)
 
-		var pending, inProgress, completed, blocked int
+		var pending, inProgress, completed, blocked, cancelled int
 		for _, t := range tasks {
 			tm, _ := t.(map[string]any)
 			status, _ := tm["status"].(string)
@@ -227,6 +228,8 @@ func analyzeRunState(artifacts map[string]*small.Artifact) []DiagnosticResult {
 				completed++
 			case "blocked":
 				blocked++
+			case "cancelled":
+				cancelled++
 			}
 		}
 
@@ -234,6 +237,9 @@ func analyzeRunState(artifacts map[string]*small.Artifact) []DiagnosticResult {
 		if total > 0 {
 			msg := fmt.Sprintf("Tasks: %d total, %d completed, %d in_progress, %d pending, %d blocked",
 				total, completed, inProgress, pending, blocked)
+			if cancelled > 0 {
+				msg += fmt.Sprintf(", %d cancelled", cancelled)
+			}
 
 			status := "ok"
 			suggestion := ""
@@ -246,7 +252,7 @@ func analyzeRunState(artifacts map[string]*small.Artifact) []DiagnosticResult {
 				status = "warning"
 				suggestion = "All remaining tasks are blocked. Review blockers."
 			}
-			if completed == total {
+			if completed+cancelled == total {
 				suggestion = "All tasks complete! Run: small handoff"
 			}
 			if pending > 0 && inProgress == 0 {
@@ -260,6 +266,7 @@ func analyzeRunState(artifacts map[string]*small.Artifact) []DiagnosticResult {
 				Suggestion: suggestion,
 			})
 		}
+		results = append(results, analyzePlanLeases(tasks, time.Now())...)
 	}
 
 	// Analyze progress entries
@@ -371,3 +378,34 @@ func printDiagnostics(results []DiagnosticResult) {
 		p.PrintSuccess("Summary: All checks passed!")
 	}
 }
+
+// analyzePlanLeases warns about task leases that have expired without being released.
+func analyzePlanLeases(tasks []any, now time.Time) []DiagnosticResult {
+	var results []DiagnosticResult
+	for _, t := range tasks {
+		tm, _ := t.(map[string]any)
+		lm, ok := tm["lease"].(map[string]any)
+		if !ok {
+			continue
+		}
+		id, _ := tm["id"].(string)
+		lease := &PlanTaskLease{}
+		lease.AgentID, _ = lm["agent_id"].(string)
+		switch expires := lm["expires"].(type) {
+		case string:
+			lease.Expires = expires
+		case time.Time:
+			lease.Expires = expires.Format(time.RFC3339Nano)
+		}
+		if lease.live(now) {
+			continue
+		}
+		results = append(results, DiagnosticResult{
+			Category:   "Leases",
+			Status:     "warning",
+			Message:    fmt.Sprintf("Task %s has an expired lease held by %s (expires: %q)", id, lease.AgentID, lease.Expires),
+			Suggestion: fmt.Sprintf("Run: small plan release %s --agent %s (or claim it with another agent)", id, lease.AgentID),
+		})
+	}
+	return results
+}
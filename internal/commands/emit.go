@@ -19,11 +19,13 @@ type emitOutput struct {
 	SpecVersion []string               `json:"specVersion"`
 	Workspace   string                 `json:"workspace"`
 	Paths       emitPaths              `json:"paths"`
+	Revisions   *emitArtifactRevisions `json:"revisions,omitempty"`
 	Status      emitStatusSummary      `json:"status,omitempty"`
 	Progress    emitProgressSummary    `json:"progress,omitempty"`
 	Intent      emitIntentSummary      `json:"intent,omitempty"`
 	Constraints emitConstraintsSummary `json:"constraints,omitempty"`
 	Plan        *emitPlanDetails       `json:"plan,omitempty"`
+	Handoff     *emitHandoffSummary    `json:"handoff,omitempty"`
 	Enforcement *emitEnforcement       `json:"enforcement,omitempty"`
 }
 
@@ -41,6 +43,17 @@ type emitArtifactPaths struct {
 	Handoff     string `json:"handoff"`
 }
 
+// emitArtifactRevisions holds the sha256 of each canonical artifact, for use with the
+// writers' --if-match flag. Missing artifacts are omitted.
+type emitArtifactRevisions struct {
+	Intent      string `json:"intent,omitempty"`
+	Constraints string `json:"constraints,omitempty"`
+	Plan        string `json:"plan,omitempty"`
+	Progress    string `json:"progress,omitempty"`
+	Handoff     string `json:"handoff,omitempty"`
+	Workspace   string `json:"workspace,omitempty"`
+}
+
 type emitStatusSummary struct {
 	TotalTasks     int            `json:"totalTasks"`
 	TasksByStatus  map[string]int `json:"tasksByStatus"`
@@ -79,6 +92,13 @@ type emitPlanDetails struct {
 	Tasks        []PlanTask `json:"tasks"`
 }
 
+type emitHandoffSummary struct {
+	Summary       string             `json:"summary,omitempty"`
+	CurrentTaskID string             `json:"currentTaskId,omitempty"`
+	NextSteps     []string           `json:"nextSteps"`
+	Context       *handoffContextOut `json:"context,omitempty"`
+}
+
 type emitEnforcement struct {
 	Validate checkStageResult `json:"validate"`
 	Lint     checkStageResult `json:"lint"`
@@ -102,6 +122,7 @@ func emitCmd() *cobra.Command {
 	var recent int
 	var tasks int
 	var include string
+	var byAgent string
 	var runCheckFlag bool
 
 	cmd := &cobra.Command{
@@ -146,7 +167,7 @@ includes enforcement results in the JSON output. The output is JSON only.`,
 				rootPath = dir
 			}
 
-			output, exitCode, err := buildEmitOutput(rootPath, artifactsDir, includeSet, scope, recent, tasks, runCheckFlag)
+			output, exitCode, err := buildEmitOutput(rootPath, artifactsDir, includeSet, scope, recent, tasks, byAgent, runCheckFlag)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 				os.Exit(ExitSystemError)
@@ -169,7 +190,9 @@ includes enforcement results in the JSON output. The output is JSON only.`,
 	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root, examples, or any)")
 	cmd.Flags().IntVar(&recent, "recent", 5, "Number of recent progress entries to include")
 	cmd.Flags().IntVar(&tasks, "tasks", 3, "Number of next actionable tasks to include")
-	cmd.Flags().StringVar(&include, "include", "", "Comma-separated sections to include (status, intent, constraints, plan, progress, paths, enforcement)")
+	cmd.Flags().StringVar(&include, "include", "", "Comma-separated sections to include (status, intent, constraints, plan, progress, handoff, paths, revisions, enforcement)")
+	// Shadows the global --agent: emit writes nothing, so the flag selects whose entries to show.
+	cmd.Flags().StringVar(&byAgent, "agent", "", "Only include progress entries written by this agent id")
 	cmd.Flags().BoolVar(&runCheckFlag, "check", false, "Run small check and include enforcement results")
 
 	return cmd
@@ -188,7 +211,9 @@ func parseEmitInclude(value string) (emitInclude, error) {
 		"constraints": true,
 		"plan":        true,
 		"progress":    true,
+		"handoff":     true,
 		"paths":       true,
+		"revisions":   true,
 		"enforcement": true,
 	}
 
@@ -207,7 +232,26 @@ func parseEmitInclude(value string) (emitInclude, error) {
 	return includeSet, nil
 }
 
-func buildEmitOutput(rootDir, artifactsDir string, include emitInclude, scope workspace.Scope, recent, tasks int, runCheckFlag bool) (emitOutput, int, error) {
+func loadArtifactRevisions(artifactsDir string) (*emitArtifactRevisions, error) {
+	revisions := &emitArtifactRevisions{}
+	for filename, target := range map[string]*string{
+		"intent.small.yml":      &revisions.Intent,
+		"constraints.small.yml": &revisions.Constraints,
+		"plan.small.yml":        &revisions.Plan,
+		"progress.small.yml":    &revisions.Progress,
+		"handoff.small.yml":     &revisions.Handoff,
+		"workspace.small.yml":   &revisions.Workspace,
+	} {
+		revision, err := artifactRevision(artifactsDir, filename)
+		if err != nil {
+			return nil, err
+		}
+		*target = revision
+	}
+	return revisions, nil
+}
+
+func buildEmitOutput(rootDir, artifactsDir string, include emitInclude, scope workspace.Scope, recent, tasks int, agent string, runCheckFlag bool) (emitOutput, int, error) {
 	workspaceInfo, err := workspace.Load(artifactsDir)
 	if err != nil {
 		return emitOutput{}, ExitSystemError, err
@@ -235,6 +279,14 @@ func buildEmitOutput(rootDir, artifactsDir string, include emitInclude, scope wo
 		output.Paths = pathsPayload
 	}
 
+	if include.Has("revisions") || len(include) == 0 {
+		revisions, err := loadArtifactRevisions(artifactsDir)
+		if err != nil {
+			return emitOutput{}, ExitSystemError, err
+		}
+		output.Revisions = revisions
+	}
+
 	if include.Has("status") || len(include) == 0 {
 		output.Status = emitStatusSummary{
 			TasksByStatus:  map[string]int{},
@@ -267,7 +319,7 @@ func buildEmitOutput(rootDir, artifactsDir string, include emitInclude, scope wo
 	if include.Has("progress") || len(include) == 0 {
 		output.Progress = emitProgressSummary{Recent: []ProgressEntry{}}
 		if small.ArtifactExists(artifactsDir, "progress.small.yml") {
-			recentEntries, err := getRecentProgress(artifactsDir, recent, false)
+			recentEntries, err := getRecentProgressByAgent(artifactsDir, recent, false, agent)
 			if err != nil {
 				return emitOutput{}, ExitSystemError, err
 			}
@@ -285,7 +337,7 @@ func buildEmitOutput(rootDir, artifactsDir string, include emitInclude, scope wo
 				output.Progress.LastTimestamp = formatProgressTimestamp(lastTimestamp)
 			}
 			if include.Has("progress") {
-				output.Progress.Entries = progressData.Entries
+				output.Progress.Entries = filterProgressEntriesByAgent(progressData.Entries, agent)
 			}
 		}
 	}
@@ -318,6 +370,16 @@ func buildEmitOutput(rootDir, artifactsDir string, include emitInclude, scope wo
 		}
 	}
 
+	if include.Has("handoff") || len(include) == 0 {
+		if small.ArtifactExists(artifactsDir, "handoff.small.yml") {
+			handoffArtifact, err := small.LoadArtifact(artifactsDir, "handoff.small.yml")
+			if err != nil {
+				return emitOutput{}, ExitSystemError, err
+			}
+			output.Handoff = buildHandoffSummary(handoffArtifact)
+		}
+	}
+
 	if runCheckFlag {
 		checkCode, checkOutput, err := runCheck(artifactsDir, false, true, true, scope, false)
 		if err != nil {
@@ -355,6 +417,26 @@ func buildIntentSummary(artifact *small.Artifact) emitIntentSummary {
 	return summary
 }
 
+func buildHandoffSummary(artifact *small.Artifact) *emitHandoffSummary {
+	summary := &emitHandoffSummary{NextSteps: []string{}}
+	if artifact == nil || artifact.Data == nil {
+		return summary
+	}
+
+	summary.Summary = stringVal(artifact.Data["summary"])
+	if resume, ok := artifact.Data["resume"].(map[string]any); ok {
+		summary.CurrentTaskID = strings.TrimSpace(stringVal(resume["current_task_id"]))
+		if steps, ok := resume["next_steps"].([]any); ok {
+			for _, step := range steps {
+				summary.NextSteps = append(summary.NextSteps, stringVal(step))
+			}
+		}
+	}
+	summary.Context = parseHandoffContext(artifact.Data["context"])
+
+	return summary
+}
+
 func buildConstraintsSummary(artifact *small.Artifact) emitConstraintsSummary {
 	summary := emitConstraintsSummary{}
 	if artifact == nil || artifact.Data == nil {
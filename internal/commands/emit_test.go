@@ -25,7 +25,7 @@ func TestEmitIncludesProgressSummary(t *testing.T) {
 		t.Fatalf("parseEmitInclude error: %v", err)
 	}
 
-	output, exitCode, err := buildEmitOutput(tmpDir, tmpDir, include, workspace.ScopeRoot, 1, 3, false)
+	output, exitCode, err := buildEmitOutput(tmpDir, tmpDir, include, workspace.ScopeRoot, 1, 3, "", false)
 	if err != nil {
 		t.Fatalf("buildEmitOutput error: %v", err)
 	}
@@ -60,7 +60,7 @@ func TestEmitCheckDoesNotMutateArtifacts(t *testing.T) {
 		t.Fatalf("parseEmitInclude error: %v", err)
 	}
 
-	output, exitCode, err := buildEmitOutput(tmpDir, tmpDir, include, workspace.ScopeRoot, 5, 3, true)
+	output, exitCode, err := buildEmitOutput(tmpDir, tmpDir, include, workspace.ScopeRoot, 5, 3, "", true)
 	if err != nil {
 		t.Fatalf("buildEmitOutput error: %v", err)
 	}
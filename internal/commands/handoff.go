@@ -17,13 +17,15 @@ import (
 
 // handoffOut represents the v1.0.0 handoff structure
 type handoffOut struct {
-	SmallVersion string      `yaml:"small_version"`
-	Owner        string      `yaml:"owner"`
-	Summary      string      `yaml:"summary"`
-	Resume       resumeOut   `yaml:"resume"`
-	Links        []linkOut   `yaml:"links"`
-	ReplayId     replayIdOut `yaml:"replayId"`
-	Run          *runOut     `yaml:"run,omitempty"`
+	SmallVersion string             `yaml:"small_version"`
+	Owner        string             `yaml:"owner"`
+	Summary      string             `yaml:"summary"`
+	Resume       resumeOut          `yaml:"resume"`
+	Context      *handoffContextOut `yaml:"context,omitempty"`
+	Links        []linkOut          `yaml:"links"`
+	ReplayId     replayIdOut        `yaml:"replayId"`
+	Run          *runOut            `yaml:"run,omitempty"`
+	Agent        *AgentIdentity     `yaml:"agent,omitempty"`
 }
 
 // replayIdOut represents the required deterministic identifier for replay and session tracking
@@ -55,6 +57,13 @@ func handoffCmd() *cobra.Command {
 		dir           string
 		replayId      string
 		workspaceFlag string
+		ifMatch       string
+		sign          bool
+		signingKey    string
+		contextFile   string
+		clearContext  bool
+		decisions     []string
+		added         handoffContextOut
 	)
 
 	cmd := &cobra.Command{
@@ -64,7 +73,26 @@ func handoffCmd() *cobra.Command {
 
 	ReplayId is required in handoff.small.yml. It is generated automatically by
 	hashing the run-defining artifacts (intent + plan + optional constraints).
-	Use --replay-id to override with a manual value if needed.`,
+	Use --replay-id to override with a manual value if needed.
+
+	--sign writes a detached ed25519 signature of the file to
+	.small/handoff.small.yml.sig in the OpenSSH SSHSIG format (namespace "small"),
+	checked by 'small verify --require-signatures'.
+
+	The optional context section records decisions, open questions, risks,
+	touched files, and failing checks. Add entries with the repeatable flags, or
+	pass a YAML file (or - for stdin) of the same shape with --context-file:
+
+	  decisions:
+	    - decision: Store sessions in sqlite
+	      rationale: No extra service to run in CI
+	      progress_refs: [task-3]
+	  open_questions:
+	    - Should expired sessions be purged on startup?
+
+	Entries are added to the context of the existing handoff for the same run;
+	--clear-context starts from an empty context. Files recorded as touched_paths
+	on the run's progress entries are always listed under touched_files.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if dir == "" {
 				dir = baseDir
@@ -86,6 +114,28 @@ func handoffCmd() *cobra.Command {
 				}
 			}
 
+			signer, err := resolveSigningKey(sign, signingKey)
+			if err != nil {
+				return err
+			}
+
+			for _, decision := range decisions {
+				added.Decisions = append(added.Decisions, handoffDecision{Decision: decision})
+			}
+			if strings.TrimSpace(contextFile) != "" {
+				fromFile, err := readHandoffContextFile(contextFile)
+				if err != nil {
+					return err
+				}
+				added = *mergeHandoffContext(fromFile, &added)
+			}
+
+			unlock, err := lockArtifactRevision(artifactsDir, "handoff.small.yml", ifMatch)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
 			planArtifact, err := small.LoadArtifact(artifactsDir, "plan.small.yml")
 			if err != nil {
 				return fmt.Errorf("failed to load plan.small.yml: %w", err)
@@ -134,12 +184,34 @@ func handoffCmd() *cobra.Command {
 			if err := setWorkspaceRunReplayIDIfPresent(artifactsDir, h.ReplayId.Value); err != nil {
 				return err
 			}
+			var carried *handoffContextOut
+			if !clearContext {
+				carried = carriedHandoffContext(artifactsDir, h.ReplayId.Value)
+			}
+			if err := attachHandoffContext(artifactsDir, &h, mergeHandoffContext(carried, &added)); err != nil {
+				return err
+			}
 
+			if signer != nil {
+				unlockSign, err := small.LockWorkspace(artifactsDir)
+				if err != nil {
+					return err
+				}
+				defer unlockSign()
+			}
 			if err := writeHandoff(artifactsDir, h); err != nil {
 				return err
 			}
+			if signer != nil {
+				if err := signHandoff(artifactsDir, signer); err != nil {
+					return err
+				}
+			}
 
 			p.PrintInfo(fmt.Sprintf("Generated handoff.small.yml with %d next steps", len(h.Resume.NextSteps)))
+			if signer != nil {
+				p.PrintInfo(fmt.Sprintf("Signed handoff.small.yml with %s", signingKeyFingerprint(signer)))
+			}
 			p.PrintInfo(fmt.Sprintf("replayId: %s (source: %s)", h.ReplayId.Value[:16]+"...", h.ReplayId.Source))
 			yml, _ := small.MarshalYAMLWithQuotedVersion(h)
 			p.PrintInfo(string(yml))
@@ -151,6 +223,17 @@ func handoffCmd() *cobra.Command {
 	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
 	cmd.Flags().StringVar(&replayId, "replay-id", "", "Manual replayId override (64 hex chars, normalized to lowercase)")
 	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root or any)")
+	cmd.Flags().StringArrayVar(&decisions, "decision", nil, "Record a decision made during the run (repeatable)")
+	cmd.Flags().StringArrayVar(&added.OpenQuestions, "question", nil, "Record an open question for the human (repeatable)")
+	cmd.Flags().StringArrayVar(&added.Risks, "risk", nil, "Record a known risk (repeatable)")
+	cmd.Flags().StringArrayVar(&added.TouchedFiles, "touched", nil, "Record a file touched during the run (repeatable)")
+	cmd.Flags().StringArrayVar(&added.FailingChecks, "failing-check", nil, "Record a failing check (repeatable)")
+	cmd.Flags().StringVar(&contextFile, "context-file", "", "YAML file of handoff context to add (- for stdin)")
+	cmd.Flags().BoolVar(&clearContext, "clear-context", false, "Drop the context carried over from the existing handoff")
+	registerIfMatchFlag(cmd, &ifMatch, "handoff.small.yml")
+	registerSigningFlags(cmd, &sign, &signingKey, "handoff.small.yml")
+
+	cmd.AddCommand(handoffRenderCmd())
 
 	return cmd
 }
@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"gopkg.in/yaml.v3"
+)
+
+// handoffContextOut is the optional context section of a handoff: the why behind the
+// resume state, for the agent or human who picks the run up next.
+type handoffContextOut struct {
+	Decisions     []handoffDecision `yaml:"decisions,omitempty" json:"decisions,omitempty"`
+	OpenQuestions []string          `yaml:"open_questions,omitempty" json:"open_questions,omitempty"`
+	Risks         []string          `yaml:"risks,omitempty" json:"risks,omitempty"`
+	TouchedFiles  []string          `yaml:"touched_files,omitempty" json:"touched_files,omitempty"`
+	FailingChecks []string          `yaml:"failing_checks,omitempty" json:"failing_checks,omitempty"`
+}
+
+type handoffDecision struct {
+	Decision     string   `yaml:"decision" json:"decision"`
+	Rationale    string   `yaml:"rationale,omitempty" json:"rationale,omitempty"`
+	ProgressRefs []string `yaml:"progress_refs,omitempty" json:"progress_refs,omitempty"`
+}
+
+func (c *handoffContextOut) isEmpty() bool {
+	return c == nil || (len(c.Decisions) == 0 && len(c.OpenQuestions) == 0 && len(c.Risks) == 0 &&
+		len(c.TouchedFiles) == 0 && len(c.FailingChecks) == 0)
+}
+
+// parseHandoffContext converts the context section of a loaded handoff, returning nil
+// when it is missing or malformed.
+func parseHandoffContext(raw any) *handoffContextOut {
+	if raw == nil {
+		return nil
+	}
+	data, err := yaml.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var ctx handoffContextOut
+	if err := yaml.Unmarshal(data, &ctx); err != nil || ctx.isEmpty() {
+		return nil
+	}
+	return &ctx
+}
+
+// readHandoffContextFile reads a context section from a YAML file, or from stdin when
+// source is "-".
+func readHandoffContextFile(source string) (*handoffContextOut, error) {
+	data, err := readDraftSource(source)
+	if err != nil {
+		return nil, err
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	var ctx handoffContextOut
+	if err := decoder.Decode(&ctx); err != nil {
+		return nil, fmt.Errorf("invalid handoff context: %w", err)
+	}
+	for i, decision := range ctx.Decisions {
+		if strings.TrimSpace(decision.Decision) == "" {
+			return nil, fmt.Errorf("invalid handoff context: decisions[%d] has no decision", i)
+		}
+	}
+	return &ctx, nil
+}
+
+// mergeHandoffContext appends the entries of add to base, skipping ones already present.
+// A decision already present is matched by its text and keeps its rationale and refs.
+func mergeHandoffContext(base, add *handoffContextOut) *handoffContextOut {
+	merged := &handoffContextOut{}
+	for _, ctx := range []*handoffContextOut{base, add} {
+		if ctx == nil {
+			continue
+		}
+		for _, decision := range ctx.Decisions {
+			decision.Decision = strings.TrimSpace(decision.Decision)
+			decision.Rationale = strings.TrimSpace(decision.Rationale)
+			decision.ProgressRefs = appendUniqueStrings(nil, decision.ProgressRefs...)
+			if decision.Decision == "" || hasHandoffDecision(merged.Decisions, decision.Decision) {
+				continue
+			}
+			merged.Decisions = append(merged.Decisions, decision)
+		}
+		merged.OpenQuestions = appendUniqueStrings(merged.OpenQuestions, ctx.OpenQuestions...)
+		merged.Risks = appendUniqueStrings(merged.Risks, ctx.Risks...)
+		merged.TouchedFiles = appendUniqueStrings(merged.TouchedFiles, ctx.TouchedFiles...)
+		merged.FailingChecks = appendUniqueStrings(merged.FailingChecks, ctx.FailingChecks...)
+	}
+	sort.Strings(merged.TouchedFiles)
+	return merged
+}
+
+func hasHandoffDecision(decisions []handoffDecision, text string) bool {
+	for _, decision := range decisions {
+		if decision.Decision == text {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUniqueStrings(list []string, values ...string) []string {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || containsString(list, value) {
+			continue
+		}
+		list = append(list, value)
+	}
+	return list
+}
+
+// runTouchedFiles collects the touched_paths recorded on progress entries of the run
+// identified by replayID. Entries that record no replayId count toward any run.
+func runTouchedFiles(artifactsDir, replayID string) ([]string, error) {
+	progressPath := filepath.Join(artifactsDir, small.SmallDir, "progress.small.yml")
+	if !small.ArtifactExists(artifactsDir, "progress.small.yml") {
+		return nil, nil
+	}
+	progress, err := loadProgressData(progressPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load progress.small.yml: %w", err)
+	}
+	var files []string
+	for _, entry := range progress.Entries {
+		if entryReplayID := stringVal(entry["replayId"]); replayID != "" && entryReplayID != "" && !strings.EqualFold(entryReplayID, replayID) {
+			continue
+		}
+		touched, ok := entry["touched_paths"].(map[string]any)
+		if !ok {
+			continue
+		}
+		for _, key := range []string{"created", "modified", "deleted"} {
+			paths, _ := touched[key].([]any)
+			for _, path := range paths {
+				files = appendUniqueStrings(files, stringVal(path))
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// attachHandoffContext merges ctx with the files touched during the handoff's run and
+// sets it on h when anything is left, along with the small_version that allows it.
+func attachHandoffContext(artifactsDir string, h *handoffOut, ctx *handoffContextOut) error {
+	touched, err := runTouchedFiles(artifactsDir, h.ReplayId.Value)
+	if err != nil {
+		return err
+	}
+	merged := mergeHandoffContext(ctx, &handoffContextOut{TouchedFiles: touched})
+	if merged.isEmpty() {
+		h.Context = nil
+		h.SmallVersion = small.ProtocolVersion
+		return nil
+	}
+	h.Context = merged
+	h.SmallVersion = small.HandoffContextVersion
+	return nil
+}
+
+// carriedHandoffContext returns the context of the existing handoff when it belongs to
+// the run identified by replayID. A new run starts without the old run's context.
+func carriedHandoffContext(artifactsDir, replayID string) *handoffContextOut {
+	if !small.ArtifactExists(artifactsDir, "handoff.small.yml") {
+		return nil
+	}
+	existing, err := loadExistingHandoff(artifactsDir)
+	if err != nil || existing.ReplayId == nil || !strings.EqualFold(existing.ReplayId.Value, replayID) {
+		return nil
+	}
+	return existing.Context
+}
@@ -35,6 +35,7 @@ type existingHandoff struct {
 	Summary  string
 	Links    []linkOut
 	ReplayId *replayIdOut
+	Context  *handoffContextOut
 }
 
 func buildHandoff(artifactsDir string, summary string, manualReplayId string, links []linkOut, replayId *replayIdOut, run *runOut, nextStepsLimit int) (handoffOut, error) {
@@ -97,6 +98,7 @@ func buildHandoff(artifactsDir string, summary string, manualReplayId string, li
 		Links:        links,
 		ReplayId:     *replayId,
 		Run:          run,
+		Agent:        recordedAgentIdentity(),
 	}, nil
 }
 
@@ -105,6 +107,11 @@ func writeHandoff(artifactsDir string, handoff handoffOut) error {
 	if err := os.MkdirAll(smallDir, 0o755); err != nil {
 		return fmt.Errorf("failed to create .small directory: %w", err)
 	}
+	unlock, err := small.LockWorkspace(artifactsDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	yml, err := small.MarshalYAMLWithQuotedVersion(handoff)
 	if err != nil {
@@ -238,7 +245,11 @@ func firstTaskIDByStatus(plan *PlanData, status string) string {
 		return ""
 	}
 	target := strings.TrimSpace(status)
+	children := planChildren(plan.Tasks)
 	for _, task := range plan.Tasks {
+		if len(children[strings.TrimSpace(task.ID)]) > 0 {
+			continue
+		}
 		if normalizePlanStatus(task.Status) == target {
 			return strings.TrimSpace(task.ID)
 		}
@@ -251,8 +262,7 @@ func nextIncompleteTaskID(plan *PlanData) string {
 		return ""
 	}
 	for _, task := range plan.Tasks {
-		status := normalizePlanStatus(task.Status)
-		if status == "completed" {
+		if planStatusDone(task.Status) {
 			continue
 		}
 		return strings.TrimSpace(task.ID)
@@ -265,7 +275,7 @@ func allPlanTasksCompleted(plan *PlanData) bool {
 		return false
 	}
 	for _, task := range plan.Tasks {
-		if normalizePlanStatus(task.Status) != "completed" {
+		if !planStatusDone(task.Status) {
 			return false
 		}
 	}
@@ -303,6 +313,7 @@ func loadExistingHandoff(artifactsDir string) (*existingHandoff, error) {
 		Summary:  stringVal(payload["summary"]),
 		Links:    parseLinks(payload["links"]),
 		ReplayId: parseReplayId(payload["replayId"]),
+		Context:  parseHandoffContext(payload["context"]),
 	}, nil
 }
 
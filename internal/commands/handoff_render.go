@@ -0,0 +1,427 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+const (
+	handoffRenderPrompt   = "prompt"
+	handoffRenderMarkdown = "markdown"
+)
+
+// resumeSection is one section of a rendered resume document. When the document is
+// over its token budget, items are dropped from the end of the lowest-priority
+// section first, and a section with no items left is omitted.
+type resumeSection struct {
+	key      string
+	title    string
+	priority int
+	items    []string
+	omitted  int
+}
+
+// resumeDocument is the composed handoff, independent of the output format.
+type resumeDocument struct {
+	summary       string
+	replayID      string
+	currentTaskID string
+	sections      []*resumeSection
+	maxTokens     int
+	omitted       int
+}
+
+func handoffRenderCmd() *cobra.Command {
+	var (
+		dir           string
+		workspaceFlag string
+		format        string
+		maxTokens     int
+		recent        int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Render the handoff as a resume document for an agent",
+		Long: `Composes handoff.small.yml with the intent, constraints (grouped by
+severity), the current task's steps and acceptance criteria, recent signal
+progress, next steps, handoff context, and links into one resume document.
+
+--format prompt wraps each section in tags under a short instruction for an LLM
+agent; --format markdown renders headings and lists. Output depends only on the
+artifacts, so the same state always renders the same document.
+
+--max-tokens budgets the document using an estimate of about four characters per
+token (at least one per word). Over budget, items are dropped from the least
+important sections first: links, touched files, recent progress, warn
+constraints, risks, decisions, failing checks, open questions, intent, error
+constraints, next steps, then the current task. The summary header is always
+kept, and a final note counts what was omitted; if the document still does not
+fit, the command fails.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dir == "" {
+				dir = baseDir
+			}
+			artifactsDir := resolveArtifactsDir(dir)
+
+			scope, err := workspace.ParseScope(workspaceFlag)
+			if err != nil {
+				return err
+			}
+			if scope != workspace.ScopeAny {
+				if err := enforceWorkspaceScope(artifactsDir, scope); err != nil {
+					return err
+				}
+			}
+			if format != handoffRenderPrompt && format != handoffRenderMarkdown {
+				return fmt.Errorf("invalid --format %q (expected prompt or markdown)", format)
+			}
+			if maxTokens < 0 {
+				return fmt.Errorf("--max-tokens must not be negative")
+			}
+
+			doc, err := buildResumeDocument(artifactsDir, recent)
+			if err != nil {
+				return err
+			}
+			if err := doc.fit(format, maxTokens); err != nil {
+				return err
+			}
+			fmt.Fprint(cmd.OutOrStdout(), doc.render(format))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", handoffRenderPrompt, "Output format (prompt or markdown)")
+	cmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Estimated token budget for the document (0 for no limit)")
+	cmd.Flags().IntVar(&recent, "recent", 5, "Number of recent signal progress entries to include")
+	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
+	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root, examples, or any)")
+
+	return cmd
+}
+
+func buildResumeDocument(artifactsDir string, recent int) (*resumeDocument, error) {
+	if !small.ArtifactExists(artifactsDir, "handoff.small.yml") {
+		return nil, fmt.Errorf("handoff.small.yml not found. Run 'small handoff' first")
+	}
+	handoff, err := small.LoadArtifact(artifactsDir, "handoff.small.yml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load handoff.small.yml: %w", err)
+	}
+
+	doc := &resumeDocument{summary: strings.TrimSpace(stringVal(handoff.Data["summary"]))}
+	if replayID := parseReplayId(handoff.Data["replayId"]); replayID != nil {
+		doc.replayID = replayID.Value
+	}
+	var nextSteps []string
+	if resume, ok := handoff.Data["resume"].(map[string]any); ok {
+		doc.currentTaskID = strings.TrimSpace(stringVal(resume["current_task_id"]))
+		if steps, ok := resume["next_steps"].([]any); ok {
+			for _, step := range steps {
+				nextSteps = append(nextSteps, stringVal(step))
+			}
+		}
+	}
+	ctx := parseHandoffContext(handoff.Data["context"])
+	if ctx == nil {
+		ctx = &handoffContextOut{}
+	}
+
+	currentTask, err := resumeCurrentTaskItems(artifactsDir, doc.currentTaskID)
+	if err != nil {
+		return nil, err
+	}
+	intent, err := resumeIntentItems(artifactsDir)
+	if err != nil {
+		return nil, err
+	}
+	errorConstraints, warnConstraints, err := resumeConstraintItems(artifactsDir)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := resumeProgressItems(artifactsDir, recent)
+	if err != nil {
+		return nil, err
+	}
+	var decisions []string
+	for _, decision := range ctx.Decisions {
+		line := decision.Decision
+		if decision.Rationale != "" {
+			line += " (why: " + decision.Rationale + ")"
+		}
+		if len(decision.ProgressRefs) > 0 {
+			line += " [" + strings.Join(decision.ProgressRefs, ", ") + "]"
+		}
+		decisions = append(decisions, line)
+	}
+	var links []string
+	for _, link := range parseLinks(handoff.Data["links"]) {
+		switch {
+		case link.URL != "" && link.Description != "":
+			links = append(links, link.Description+": "+link.URL)
+		case link.URL != "":
+			links = append(links, link.URL)
+		default:
+			links = append(links, link.Description)
+		}
+	}
+
+	// Sections in document order; priority decides what survives truncation.
+	doc.sections = []*resumeSection{
+		{key: "current_task", title: "Current task", priority: 12, items: currentTask},
+		{key: "next_steps", title: "Next steps", priority: 11, items: nextSteps},
+		{key: "intent", title: "Intent", priority: 9, items: intent},
+		{key: "constraints_error", title: "Constraints (error)", priority: 10, items: errorConstraints},
+		{key: "constraints_warn", title: "Constraints (warn)", priority: 4, items: warnConstraints},
+		{key: "open_questions", title: "Open questions", priority: 8, items: ctx.OpenQuestions},
+		{key: "failing_checks", title: "Failing checks", priority: 7, items: ctx.FailingChecks},
+		{key: "decisions", title: "Decisions", priority: 6, items: decisions},
+		{key: "risks", title: "Risks", priority: 5, items: ctx.Risks},
+		{key: "recent_progress", title: "Recent progress", priority: 3, items: progress},
+		{key: "touched_files", title: "Touched files", priority: 2, items: ctx.TouchedFiles},
+		{key: "links", title: "Links", priority: 1, items: links},
+	}
+	return doc, nil
+}
+
+func resumeCurrentTaskItems(artifactsDir, taskID string) ([]string, error) {
+	if taskID == "" {
+		return nil, nil
+	}
+	if !small.ArtifactExists(artifactsDir, "plan.small.yml") {
+		return []string{taskID}, nil
+	}
+	plan, err := loadPlan(filepath.Join(artifactsDir, small.SmallDir, "plan.small.yml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plan.small.yml: %w", err)
+	}
+	task, _ := findTask(plan, taskID)
+	if task == nil {
+		return []string{taskID}, nil
+	}
+
+	items := []string{fmt.Sprintf("%s: %s (status: %s)", taskID, strings.TrimSpace(task.Title), normalizePlanStatus(task.Status))}
+	if len(task.Dependencies) > 0 {
+		items = append(items, "Depends on: "+strings.Join(task.Dependencies, ", "))
+	}
+	for i, step := range task.Steps {
+		items = append(items, fmt.Sprintf("Step %d: %s", i+1, strings.TrimSpace(step)))
+	}
+	for _, criterion := range task.Acceptance {
+		items = append(items, "Acceptance: "+describeAcceptanceCriterion(criterion))
+	}
+	return items, nil
+}
+
+// describeAcceptanceCriterion renders a criterion as one line of prose.
+func describeAcceptanceCriterion(c AcceptanceCriterion) string {
+	var check string
+	switch kind, _ := acceptanceCheckType(c); kind {
+	case acceptanceCheckCommand:
+		expect := 0
+		if c.ExpectExit != nil {
+			expect = *c.ExpectExit
+		}
+		check = fmt.Sprintf("`%s` exits %d", c.Command, expect)
+	case acceptanceCheckFileExists:
+		check = fmt.Sprintf("%s exists", c.FileExists)
+	case acceptanceCheckContains:
+		check = fmt.Sprintf("%s matches /%s/", c.File, c.Contains)
+	case acceptanceCheckJSONPath:
+		check = fmt.Sprintf("%s %s equals %s", c.File, c.JSONPath, c.Equals.Value)
+	}
+	description := strings.TrimSpace(c.Description)
+	switch {
+	case check == "":
+		return description
+	case description == "":
+		return check
+	default:
+		return description + " (" + check + ")"
+	}
+}
+
+func resumeIntentItems(artifactsDir string) ([]string, error) {
+	if !small.ArtifactExists(artifactsDir, "intent.small.yml") {
+		return nil, nil
+	}
+	intent, err := small.LoadArtifact(artifactsDir, "intent.small.yml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load intent.small.yml: %w", err)
+	}
+	var items []string
+	if text := strings.TrimSpace(stringVal(intent.Data["intent"])); text != "" {
+		items = append(items, text)
+	}
+	if scope, ok := intent.Data["scope"].(map[string]any); ok {
+		for _, key := range []string{"include", "exclude"} {
+			if paths := anyStrings(scope[key]); len(paths) > 0 {
+				items = append(items, fmt.Sprintf("Scope %s: %s", key, strings.Join(paths, ", ")))
+			}
+		}
+	}
+	for _, criterion := range anyStrings(intent.Data["success_criteria"]) {
+		items = append(items, "Success: "+criterion)
+	}
+	return items, nil
+}
+
+func resumeConstraintItems(artifactsDir string) (errs, warns []string, err error) {
+	if !small.ArtifactExists(artifactsDir, "constraints.small.yml") {
+		return nil, nil, nil
+	}
+	constraints, err := small.LoadArtifact(artifactsDir, "constraints.small.yml")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load constraints.small.yml: %w", err)
+	}
+	items, _ := constraints.Data["constraints"].([]any)
+	for _, raw := range items {
+		constraint, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		line := fmt.Sprintf("%s: %s", strings.TrimSpace(stringVal(constraint["id"])), strings.TrimSpace(stringVal(constraint["rule"])))
+		if stringVal(constraint["severity"]) == "warn" {
+			warns = append(warns, line)
+		} else {
+			errs = append(errs, line)
+		}
+	}
+	return errs, warns, nil
+}
+
+func resumeProgressItems(artifactsDir string, recent int) ([]string, error) {
+	if recent <= 0 || !small.ArtifactExists(artifactsDir, "progress.small.yml") {
+		return nil, nil
+	}
+	entries, err := getRecentProgressByAgent(artifactsDir, recent, true, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load progress.small.yml: %w", err)
+	}
+	var items []string
+	for _, entry := range entries {
+		line := fmt.Sprintf("[%s] %s", formatTimestamp(entry.Timestamp), entry.TaskID)
+		if entry.Status != "" {
+			line += ": " + entry.Status
+		}
+		if entry.Agent != "" {
+			line += " by " + entry.Agent
+		}
+		if evidence := summarizeStatusEvidence(entry); evidence != "" {
+			line += " - " + evidence
+		}
+		items = append(items, line)
+	}
+	return items, nil
+}
+
+func anyStrings(raw any) []string {
+	values, _ := raw.([]any)
+	var out []string
+	for _, value := range values {
+		if s := strings.TrimSpace(stringVal(value)); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// estimateTokens approximates a tokenizer: about four characters per token, and at
+// least one token per word.
+func estimateTokens(text string) int {
+	tokens := 0
+	for _, word := range strings.Fields(text) {
+		tokens += (utf8.RuneCountInString(word) + 3) / 4
+	}
+	return tokens
+}
+
+// fit drops items, lowest priority first, until the rendered document is within
+// maxTokens. It fails when the document is still over budget with nothing left to drop.
+func (d *resumeDocument) fit(format string, maxTokens int) error {
+	d.maxTokens = maxTokens
+	if maxTokens <= 0 {
+		return nil
+	}
+	for {
+		tokens := estimateTokens(d.render(format))
+		if tokens <= maxTokens {
+			return nil
+		}
+		var victim *resumeSection
+		for _, section := range d.sections {
+			if len(section.items) > 0 && (victim == nil || section.priority < victim.priority) {
+				victim = section
+			}
+		}
+		if victim == nil {
+			return fmt.Errorf("handoff does not fit in --max-tokens %d: with every section dropped it is still about %d tokens", maxTokens, tokens)
+		}
+		victim.items = victim.items[:len(victim.items)-1]
+		victim.omitted++
+		d.omitted++
+	}
+}
+
+func (d *resumeDocument) render(format string) string {
+	var b strings.Builder
+	summary := d.summary
+	if summary == "" {
+		summary = "No summary recorded."
+	}
+	currentTask := d.currentTaskID
+	if currentTask == "" {
+		currentTask = "none"
+	}
+
+	if format == handoffRenderMarkdown {
+		fmt.Fprintf(&b, "# Resume: %s\n\n", summary)
+		if d.replayID != "" {
+			fmt.Fprintf(&b, "- ReplayId: `%s`\n", d.replayID)
+		}
+		fmt.Fprintf(&b, "- Current task: %s\n", currentTask)
+	} else {
+		b.WriteString("You are resuming a SMALL run. Read this handoff before acting: continue the\n")
+		b.WriteString("current task, stay within the intent scope, follow every error constraint, and\n")
+		b.WriteString("record progress with small checkpoint.\n\n")
+		fmt.Fprintf(&b, "Summary: %s\n", summary)
+		if d.replayID != "" {
+			fmt.Fprintf(&b, "ReplayId: %s\n", d.replayID)
+		}
+		fmt.Fprintf(&b, "Current task: %s\n", currentTask)
+	}
+
+	for _, section := range d.sections {
+		if len(section.items) == 0 {
+			continue
+		}
+		b.WriteString("\n")
+		if format == handoffRenderMarkdown {
+			fmt.Fprintf(&b, "## %s\n\n", section.title)
+		} else {
+			fmt.Fprintf(&b, "<%s>\n", section.key)
+		}
+		for _, item := range section.items {
+			fmt.Fprintf(&b, "- %s\n", item)
+		}
+		if section.omitted > 0 {
+			fmt.Fprintf(&b, "- ... %d more omitted\n", section.omitted)
+		}
+		if format != handoffRenderMarkdown {
+			fmt.Fprintf(&b, "</%s>\n", section.key)
+		}
+	}
+
+	if d.omitted > 0 {
+		fmt.Fprintf(&b, "\n(%d items omitted to fit %d tokens)\n", d.omitted, d.maxTokens)
+	}
+	return b.String()
+}
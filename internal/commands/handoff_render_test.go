@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func setupRenderWorkspace(t *testing.T) string {
+	t.Helper()
+	setAgentFlag(t, "")
+	dir := t.TempDir()
+	artifacts := defaultArtifacts()
+	artifacts["intent.small.yml"] = `small_version: "1.0.0"
+owner: "human"
+intent: "Add session storage"
+scope:
+  include: ["store/**"]
+  exclude: ["vendor/**"]
+success_criteria:
+  - "Sessions survive a restart"
+`
+	artifacts["constraints.small.yml"] = `small_version: "1.0.0"
+owner: "human"
+constraints:
+  - id: "no-secrets"
+    rule: "Never commit credentials"
+    severity: "error"
+  - id: "small-diffs"
+    rule: "Prefer small diffs"
+    severity: "warn"
+`
+	artifacts["plan.small.yml"] = `small_version: "1.0.0"
+owner: "agent"
+tasks:
+  - id: "task-1"
+    title: "Write the session store"
+    steps:
+      - "Create store/session.go"
+      - "Add tests"
+    acceptance:
+      - "Reviewed by a human"
+      - command: "go test ./store"
+`
+	writeArtifacts(t, dir, artifacts)
+	mustSaveWorkspace(t, dir, workspace.KindRepoRoot)
+
+	cmd := handoffCmd()
+	cmd.SetArgs([]string{"--dir", dir, "--question", "Purge expired sessions?", "--risk", "No migration path yet"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("handoff failed: %v", err)
+	}
+	return dir
+}
+
+func renderHandoff(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := handoffRenderCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs(append([]string{"--dir", dir}, args...))
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("handoff render %v failed: %v", args, err)
+	}
+	return out.String()
+}
+
+func TestHandoffRender(t *testing.T) {
+	dir := setupRenderWorkspace(t)
+
+	prompt := renderHandoff(t, dir)
+	for _, want := range []string{
+		"You are resuming a SMALL run.",
+		"Current task: task-1",
+		"<current_task>\n- task-1: Write the session store (status: pending)\n- Step 1: Create store/session.go\n- Step 2: Add tests\n- Acceptance: Reviewed by a human\n- Acceptance: `go test ./store` exits 0\n</current_task>",
+		"<constraints_error>\n- no-secrets: Never commit credentials\n</constraints_error>",
+		"<constraints_warn>\n- small-diffs: Prefer small diffs\n</constraints_warn>",
+		"- Scope include: store/**",
+		"- Success: Sessions survive a restart",
+		"<open_questions>\n- Purge expired sessions?\n</open_questions>",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+	if again := renderHandoff(t, dir); again != prompt {
+		t.Fatalf("expected rendering to be deterministic")
+	}
+
+	markdown := renderHandoff(t, dir, "--format", "markdown")
+	if !strings.HasPrefix(markdown, "# Resume: ") || !strings.Contains(markdown, "## Constraints (error)\n\n- no-secrets: Never commit credentials\n") {
+		t.Fatalf("unexpected markdown rendering:\n%s", markdown)
+	}
+
+	budget := estimateTokens(prompt) - 1
+	trimmed := renderHandoff(t, dir, "--max-tokens", strconv.Itoa(budget))
+	if estimateTokens(trimmed) > budget || strings.Contains(trimmed, "<constraints_warn>") ||
+		!strings.Contains(trimmed, "<current_task>") || !strings.Contains(trimmed, "omitted to fit") {
+		t.Fatalf("expected warn constraints to be dropped before the current task within %d tokens, got:\n%s", budget, trimmed)
+	}
+
+	headerOnly := renderHandoff(t, dir, "--max-tokens", "100")
+	if strings.Contains(headerOnly, "<current_task>") || !strings.Contains(headerOnly, "Current task: task-1") {
+		t.Fatalf("expected only the header to survive a small budget, got:\n%s", headerOnly)
+	}
+
+	var out bytes.Buffer
+	cmd := handoffRenderCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--dir", dir, "--max-tokens", "5"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "does not fit") {
+		t.Fatalf("expected an over-budget render to fail, got err=%v output:\n%s", err, out.String())
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
 )
 
 func TestGenerateReplayId(t *testing.T) {
@@ -352,6 +355,8 @@ entries:
   - task_id: task-1
     timestamp: "2025-01-01T00:00:00.000000000Z"
     evidence: "Completed the work"
+    agent:
+      id: agent-test
 `
 
 	if err := os.WriteFile(filepath.Join(smallDir, "intent.small.yml"), []byte(intentContent), 0644); err != nil {
@@ -449,6 +454,8 @@ entries:
     status: "completed"
     timestamp: "2026-01-01T00:00:00.000000000Z"
     evidence: "done"
+    agent:
+      id: "agent-test"
 `,
 	}
 	for name, content := range artifacts {
@@ -687,6 +694,8 @@ entries:
     status: "blocked"
     timestamp: "2026-01-01T00:00:00.000000000Z"
     evidence: "Blocked task preserved for historical release work"
+    agent:
+      id: "agent-test"
 `,
 	}
 	for name, content := range artifacts {
@@ -720,3 +729,102 @@ entries:
 		t.Fatalf("expected current_task_id task-37, got %v", state.CurrentTaskID)
 	}
 }
+
+func TestHandoffContext(t *testing.T) {
+	setAgentFlag(t, "")
+	dir := t.TempDir()
+	writeArtifacts(t, dir, defaultArtifacts())
+	mustSaveWorkspace(t, dir, workspace.KindRepoRoot)
+	if err := appendProgressEntry(dir, map[string]any{
+		"task_id":       "meta/apply",
+		"evidence":      "Ran the migration",
+		"touched_paths": map[string]any{"created": []any{"db/schema.sql"}, "modified": []any{"go.mod"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	contextPath := filepath.Join(t.TempDir(), "context.yml")
+	contextYAML := `decisions:
+  - decision: Store sessions in sqlite
+    rationale: No extra service to run in CI
+    progress_refs: [task-1]
+risks:
+  - Migration is not reversible
+`
+	if err := os.WriteFile(contextPath, []byte(contextYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := handoffCmd()
+	cmd.SetArgs([]string{"--dir", dir, "--context-file", contextPath, "--question", "Purge expired sessions?", "--failing-check", "go test ./store"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("handoff failed: %v", err)
+	}
+
+	existing, err := loadExistingHandoff(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := existing.Context
+	if ctx == nil || len(ctx.Decisions) != 1 || ctx.Decisions[0].Rationale != "No extra service to run in CI" ||
+		strings.Join(ctx.OpenQuestions, ",") != "Purge expired sessions?" ||
+		strings.Join(ctx.Risks, ",") != "Migration is not reversible" ||
+		strings.Join(ctx.FailingChecks, ",") != "go test ./store" ||
+		strings.Join(ctx.TouchedFiles, ",") != "db/schema.sql,go.mod" {
+		t.Fatalf("unexpected handoff context: %+v", ctx)
+	}
+	if code := runVerify(dir, false, true, workspace.ScopeAny); code != ExitValid {
+		t.Fatalf("expected handoff with context to validate, got %d", code)
+	}
+	artifact, err := small.LoadArtifact(dir, "handoff.small.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version := artifact.Data["small_version"]; version != small.HandoffContextVersion {
+		t.Fatalf("expected a handoff with context to declare small_version %s, got %v", small.HandoffContextVersion, version)
+	}
+	artifact.Data["small_version"] = small.ProtocolVersion
+	if err := small.ValidateArtifactWithConfig(artifact, small.SchemaConfig{BaseDir: dir}); err == nil {
+		t.Fatal("expected the v1.0.0 handoff schema to reject context")
+	}
+
+	cmd = handoffCmd()
+	cmd.SetArgs([]string{"--dir", dir, "--decision", "Keep the v1 API"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("handoff failed: %v", err)
+	}
+	existing, err = loadExistingHandoff(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(existing.Context.Decisions) != 2 || len(existing.Context.Risks) != 1 {
+		t.Fatalf("expected context to carry over within the run, got %+v", existing.Context)
+	}
+
+	status, err := getHandoffStatusSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Context == nil || len(status.Context.Decisions) != 2 {
+		t.Fatalf("expected status to surface the handoff context, got %+v", status.Context)
+	}
+	output, _, err := buildEmitOutput(dir, dir, emitInclude{"handoff": true}, workspace.ScopeAny, 5, 3, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.Handoff == nil || output.Handoff.Context == nil || len(output.Handoff.Context.TouchedFiles) != 2 {
+		t.Fatalf("expected emit to include the handoff context, got %+v", output.Handoff)
+	}
+
+	cmd = handoffCmd()
+	cmd.SetArgs([]string{"--dir", dir, "--clear-context"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("handoff failed: %v", err)
+	}
+	existing, err = loadExistingHandoff(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if existing.Context == nil || len(existing.Context.Decisions) != 0 || len(existing.Context.TouchedFiles) != 2 {
+		t.Fatalf("expected --clear-context to keep only derived touched files, got %+v", existing.Context)
+	}
+}
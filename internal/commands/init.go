@@ -20,6 +20,7 @@ func initCmd() *cobra.Command {
 	var noAgents bool
 	var overwriteAgents bool
 	var agentsModeStr string
+	var progressStorageStr string
 
 	cmd := &cobra.Command{
 		Use:   "init",
@@ -50,6 +51,10 @@ regardless of append/prepend mode.`,
 			if err := ValidateAgentsModeFlags(agentsMode, noAgents, overwriteAgents); err != nil {
 				return err
 			}
+			progressStorageStr = strings.ToLower(strings.TrimSpace(progressStorageStr))
+			if progressStorageStr != progressStorageYAML && progressStorageStr != progressStorageJSONL {
+				return fmt.Errorf("invalid --progress-storage %q (must be yaml or jsonl)", progressStorageStr)
+			}
 
 			smallDir := filepath.Join(targetDir, small.SmallDir)
 			if !force {
@@ -85,9 +90,17 @@ regardless of append/prepend mode.`,
 				}
 			}
 
+			if err := initProgressStorage(targetDir, progressStorageStr); err != nil {
+				return err
+			}
+
 			if err := workspace.Save(targetDir, workspace.KindRepoRoot); err != nil {
 				return err
 			}
+			// Progress starts empty, so it is hash-chained from the first entry.
+			if err := workspace.SetProgressChain(targetDir); err != nil {
+				return err
+			}
 
 			handoff, err := buildHandoff(targetDir, "", "", nil, nil, nil, defaultNextStepsLimit)
 			if err != nil {
@@ -156,6 +169,7 @@ regardless of append/prepend mode.`,
 	cmd.Flags().BoolVar(&noAgents, "no-agents", false, "Skip creating AGENTS.md")
 	cmd.Flags().BoolVar(&overwriteAgents, "overwrite-agents", false, "Overwrite existing AGENTS.md entirely")
 	cmd.Flags().StringVar(&agentsModeStr, "agents-mode", "", "How to handle existing AGENTS.md (append, prepend)")
+	cmd.Flags().StringVar(&progressStorageStr, "progress-storage", progressStorageYAML, "How new progress entries are stored (yaml, or jsonl for an append-only ledger)")
 
 	return cmd
 }
@@ -225,6 +239,7 @@ var initGitignoreLines = []string{
 	".small-cache/",
 	".small-runs/",
 	".small-archive/",
+	".small/.lock",
 }
 
 func ensureInitGitignore(targetDir string) error {
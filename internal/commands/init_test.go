@@ -33,6 +33,9 @@ func TestInitCommandWritesWorkspaceMetadata(t *testing.T) {
 	if info.Kind != workspace.KindRepoRoot {
 		t.Fatalf("expected workspace kind %q, got %q", workspace.KindRepoRoot, info.Kind)
 	}
+	if !info.ProgressChain {
+		t.Fatal("expected init to record that progress is hash-chained")
+	}
 
 	progressPath := filepath.Join(tmpDir, ".small", "progress.small.yml")
 	data, err := os.ReadFile(progressPath)
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/spf13/cobra"
+)
+
+type lockStatusOutput struct {
+	Path        string            `json:"path"`
+	Held        bool              `json:"held"`
+	Holder      *small.LockHolder `json:"holder,omitempty"`
+	HolderAlive bool              `json:"holder_alive,omitempty"`
+}
+
+func init() {
+	small.LockAgentID = currentAgentID
+}
+
+func lockCmd() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Inspect or break the workspace write lock",
+		Long: `Every command that writes .small/ artifacts holds an advisory lock on .small/.lock
+while it writes. A writer that cannot get the lock within --lock-timeout (or
+SMALL_LOCK_TIMEOUT, default 10s) fails and names the holder instead of
+overwriting its changes.
+
+The lock is released when its holder exits, even if it crashes. Break it only
+when a holder is hung.`,
+	}
+
+	cmd.PersistentFlags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
+
+	cmd.AddCommand(lockStatusCmd(&dir))
+	cmd.AddCommand(lockBreakCmd(&dir))
+
+	return cmd
+}
+
+func lockStatusCmd(dir *string) *cobra.Command {
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the workspace lock is held and by whom",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, err := small.InspectWorkspaceLock(resolveLockDir(*dir))
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				data, err := json.MarshalIndent(lockStatusOutput{
+					Path:        status.Path,
+					Held:        status.Held,
+					Holder:      status.Holder,
+					HolderAlive: status.HolderAlive,
+				}, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+			if !status.Held {
+				fmt.Printf("%s: not held\n", status.Path)
+				return nil
+			}
+			fmt.Printf("%s: held by %s\n", status.Path, status.Holder)
+			if status.Holder != nil && !status.HolderAlive {
+				fmt.Println("The holder process is no longer running; run: small lock break --force")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	return cmd
+}
+
+func lockBreakCmd(dir *string) *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "break",
+		Short: "Remove a stale workspace lock",
+		Long: `Removes .small/.lock so new writers take a fresh lock. A hung holder keeps its
+lock on the removed file and may still write, so --force is required.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			artifactsDir := resolveLockDir(*dir)
+			status, err := small.InspectWorkspaceLock(artifactsDir)
+			if err != nil {
+				return err
+			}
+			if !status.Held {
+				fmt.Printf("%s: not held\n", status.Path)
+				return nil
+			}
+			if !force {
+				return fmt.Errorf("%s is held by %s; pass --force to break it", status.Path, status.Holder)
+			}
+			if err := small.BreakWorkspaceLock(artifactsDir); err != nil {
+				return err
+			}
+			fmt.Printf("Broke lock held by %s\n", status.Holder)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Break the lock even though a process holds it")
+	return cmd
+}
+
+func resolveLockDir(dir string) string {
+	if dir == "" {
+		dir = baseDir
+	}
+	return resolveArtifactsDir(dir)
+}
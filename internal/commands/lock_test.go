@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func TestLockBreakRequiresForce(t *testing.T) {
+	dir := t.TempDir()
+	writeArtifacts(t, dir, defaultArtifacts())
+	mustSaveWorkspace(t, dir, workspace.KindRepoRoot)
+
+	unlock, err := small.LockWorkspace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	cmd := lockCmd()
+	cmd.SetArgs([]string{"break", "--dir", dir})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected break without --force to fail, got %v", err)
+	}
+
+	cmd = lockCmd()
+	cmd.SetArgs([]string{"break", "--force", "--dir", dir})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("lock break --force failed: %v", err)
+	}
+	status, err := small.InspectWorkspaceLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Held {
+		t.Fatalf("expected the lock to be free after break, got %+v", status)
+	}
+}
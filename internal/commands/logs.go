@@ -0,0 +1,587 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// commandLogRecord is a command log set joined with the progress entry that references it.
+type commandLogRecord struct {
+	small.CommandLogSet
+	TaskID  string `json:"task_id,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Command string `json:"command,omitempty"`
+}
+
+type logsListOutput struct {
+	Logs []commandLogRecord `json:"logs"`
+}
+
+// commandLogLine is one timestamped line from a stdout or stderr log.
+type commandLogLine struct {
+	Timestamp string
+	Stream    string
+	Text      string
+}
+
+type logsFilter struct {
+	replayId string
+	taskID   string
+}
+
+const logsFollowInterval = 250 * time.Millisecond
+
+func logsCmd() *cobra.Command {
+	var (
+		dir           string
+		workspaceFlag string
+		filter        logsFilter
+	)
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "List, show, tail, and prune command output logs",
+		Long: `Inspect command logs captured by small apply under .small-cache/logs/<replayId>/commands/.
+
+Each apply run stores the command text (<id>.txt) and timestamped stdout and stderr
+logs (<id>.stdout.log, <id>.stderr.log). Logs are matched to tasks through the
+progress entries that reference them.`,
+	}
+
+	cmd.PersistentFlags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
+	cmd.PersistentFlags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root, examples, or any)")
+	cmd.PersistentFlags().StringVar(&filter.replayId, "replay-id", "", "Only include logs for this replayId (prefix match)")
+	cmd.PersistentFlags().StringVar(&filter.taskID, "task", "", "Only include logs recorded for this task")
+
+	cmd.AddCommand(logsListCmd(&dir, &workspaceFlag, &filter))
+	cmd.AddCommand(logsShowCmd(&dir, &workspaceFlag, &filter))
+	cmd.AddCommand(logsTailCmd(&dir, &workspaceFlag, &filter))
+	cmd.AddCommand(logsPruneCmd(&dir, &workspaceFlag))
+
+	return cmd
+}
+
+func logsListCmd(dir, workspaceFlag *string, filter *logsFilter) *cobra.Command {
+	var (
+		limit      int
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List command logs (newest first)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			artifactsDir, _, err := resolveRunContext(*dir, "", *workspaceFlag)
+			if err != nil {
+				return err
+			}
+			records, err := loadCommandLogRecords(artifactsDir, *filter)
+			if err != nil {
+				return err
+			}
+			sort.SliceStable(records, func(i, j int) bool {
+				return records[i].ID > records[j].ID
+			})
+			if limit > 0 && len(records) > limit {
+				records = records[:limit]
+			}
+
+			output, err := formatLogsListOutput(records, jsonOutput)
+			if err != nil {
+				return err
+			}
+			fmt.Print(output)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 20, "Maximum number of logs to show")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	return cmd
+}
+
+func logsShowCmd(dir, workspaceFlag *string, filter *logsFilter) *cobra.Command {
+	var stream string
+
+	cmd := &cobra.Command{
+		Use:   "show [log-id]",
+		Short: "Print a command log (default: latest matching log)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			artifactsDir, _, err := resolveRunContext(*dir, "", *workspaceFlag)
+			if err != nil {
+				return err
+			}
+			record, err := selectCommandLog(artifactsDir, *filter, args)
+			if err != nil {
+				return err
+			}
+			lines, err := readCommandLogLines(artifactsDir, record, stream)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("log: %s (replayId %s)\n", record.ID, shortID(record.ReplayID, 8))
+			if record.TaskID != "" {
+				fmt.Printf("task: %s\n", record.TaskID)
+			}
+			if record.Command != "" {
+				fmt.Printf("command: %s\n", record.Command)
+			}
+			fmt.Println()
+			writeCommandLogLines(os.Stdout, lines, stream)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&stream, "stream", "all", "Stream to print (stdout, stderr, or all)")
+	return cmd
+}
+
+func logsTailCmd(dir, workspaceFlag *string, filter *logsFilter) *cobra.Command {
+	var (
+		stream string
+		lines  int
+		follow bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tail [log-id]",
+		Short: "Print the last lines of a command log, optionally following it",
+		Long: `Print the last lines of a command log (default: latest matching log).
+
+With --follow, new lines are printed as they are written until a progress entry
+records the command's completion, or until interrupted.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			artifactsDir, _, err := resolveRunContext(*dir, "", *workspaceFlag)
+			if err != nil {
+				return err
+			}
+			record, err := selectCommandLog(artifactsDir, *filter, args)
+			if err != nil {
+				return err
+			}
+			return tailCommandLog(os.Stdout, artifactsDir, record, stream, lines, follow)
+		},
+	}
+
+	cmd.Flags().StringVar(&stream, "stream", "all", "Stream to print (stdout, stderr, or all)")
+	cmd.Flags().IntVarP(&lines, "lines", "n", 20, "Number of lines to print")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Keep printing new lines until the command completes")
+	return cmd
+}
+
+func logsPruneCmd(dir, workspaceFlag *string) *cobra.Command {
+	var (
+		olderThan string
+		dryRun    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete command logs older than a retention window",
+		Long: `Delete command logs whose files were last modified before the retention window.
+
+Logs for the current run (workspace run.replay_id) are always kept.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			artifactsDir, _, err := resolveRunContext(*dir, "", *workspaceFlag)
+			if err != nil {
+				return err
+			}
+			retention, err := parseRetentionDuration(olderThan)
+			if err != nil {
+				return err
+			}
+			currentReplayID, err := currentWorkspaceRunReplayID(artifactsDir)
+			if err != nil {
+				return err
+			}
+
+			pruned, err := pruneCommandLogs(artifactsDir, time.Now().Add(-retention), currentReplayID, dryRun)
+			if err != nil {
+				return err
+			}
+			verb := "Pruned"
+			if dryRun {
+				verb = "Would prune"
+			}
+			fmt.Printf("%s %d command log(s) older than %s\n", verb, pruned, olderThan)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&olderThan, "older-than", "", "Retention window (e.g. 72h, 14d)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Report what would be deleted without deleting")
+	_ = cmd.MarkFlagRequired("older-than")
+	return cmd
+}
+
+// loadCommandLogRecords lists command logs and annotates them from progress entries.
+func loadCommandLogRecords(artifactsDir string, filter logsFilter) ([]commandLogRecord, error) {
+	sets, err := small.ListCommandLogs(artifactsDir, "")
+	if err != nil {
+		return nil, err
+	}
+
+	byRef := map[string]map[string]any{}
+	if small.ArtifactExists(artifactsDir, "progress.small.yml") {
+		progress, err := loadProgressData(filepath.Join(artifactsDir, small.SmallDir, "progress.small.yml"))
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range progress.Entries {
+			for _, field := range []string{"command_ref", "stdout_ref", "stderr_ref"} {
+				if ref := stringVal(entry[field]); ref != "" {
+					byRef[ref] = entry
+				}
+			}
+		}
+	}
+
+	records := make([]commandLogRecord, 0, len(sets))
+	for _, set := range sets {
+		if filter.replayId != "" && !strings.HasPrefix(set.ReplayID, filter.replayId) {
+			continue
+		}
+		record := commandLogRecord{CommandLogSet: set}
+		// Output logs are named after the start timestamp, so prefer their entry.
+		for _, ref := range []string{set.StdoutRef, set.StderrRef, set.CommandRef} {
+			if entry, ok := byRef[ref]; ok && ref != "" {
+				record.TaskID = stringVal(entry["task_id"])
+				record.Status = stringVal(entry["status"])
+				record.Command = stringVal(entry["command_summary"])
+				break
+			}
+		}
+		if record.Command == "" && set.CommandRef != "" {
+			if data, err := os.ReadFile(filepath.Join(artifactsDir, filepath.FromSlash(set.CommandRef))); err == nil {
+				record.Command = small.SummarizeCommand(string(data), 60)
+			}
+		}
+		if filter.taskID != "" && record.TaskID != filter.taskID {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// selectCommandLog returns the log named by args[0] (id, id prefix, or ref path),
+// or the newest log with output that matches the filter.
+func selectCommandLog(artifactsDir string, filter logsFilter, args []string) (commandLogRecord, error) {
+	records, err := loadCommandLogRecords(artifactsDir, filter)
+	if err != nil {
+		return commandLogRecord{}, err
+	}
+
+	if len(args) == 1 {
+		selector := strings.TrimSpace(args[0])
+		var matches []commandLogRecord
+		for _, record := range records {
+			if record.ID == selector || strings.HasPrefix(record.ID, selector) || containsString(record.Refs(), selector) {
+				matches = append(matches, record)
+			}
+		}
+		switch len(matches) {
+		case 0:
+			return commandLogRecord{}, fmt.Errorf("no command log matches %q (see: small logs list)", selector)
+		case 1:
+			return matches[0], nil
+		default:
+			return commandLogRecord{}, fmt.Errorf("log id %q is ambiguous (%d matches); use a longer id or --replay-id", selector, len(matches))
+		}
+	}
+
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].StdoutRef != "" || records[i].StderrRef != "" {
+			return records[i], nil
+		}
+	}
+	return commandLogRecord{}, fmt.Errorf("no command output logs found (run small apply --cmd first)")
+}
+
+func readCommandLogLines(artifactsDir string, record commandLogRecord, stream string) ([]commandLogLine, error) {
+	streams, err := selectedLogStreams(record, stream)
+	if err != nil {
+		return nil, err
+	}
+	var lines []commandLogLine
+	for _, s := range streams {
+		data, err := os.ReadFile(filepath.Join(artifactsDir, filepath.FromSlash(s.ref)))
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, parseCommandLogLines(data, s.name)...)
+	}
+	sortCommandLogLines(lines)
+	return lines, nil
+}
+
+type logStreamRef struct {
+	name string
+	ref  string
+}
+
+func selectedLogStreams(record commandLogRecord, stream string) ([]logStreamRef, error) {
+	var streams []logStreamRef
+	switch stream {
+	case "all", "":
+		if record.StdoutRef != "" {
+			streams = append(streams, logStreamRef{small.CommandStreamStdout, record.StdoutRef})
+		}
+		if record.StderrRef != "" {
+			streams = append(streams, logStreamRef{small.CommandStreamStderr, record.StderrRef})
+		}
+	case small.CommandStreamStdout:
+		if record.StdoutRef == "" {
+			return nil, fmt.Errorf("log %s has no stdout capture", record.ID)
+		}
+		streams = append(streams, logStreamRef{stream, record.StdoutRef})
+	case small.CommandStreamStderr:
+		if record.StderrRef == "" {
+			return nil, fmt.Errorf("log %s has no stderr capture", record.ID)
+		}
+		streams = append(streams, logStreamRef{stream, record.StderrRef})
+	default:
+		return nil, fmt.Errorf("invalid --stream %q (expected stdout, stderr, or all)", stream)
+	}
+	if len(streams) == 0 {
+		return nil, fmt.Errorf("log %s has no output captures", record.ID)
+	}
+	return streams, nil
+}
+
+func parseCommandLogLines(data []byte, stream string) []commandLogLine {
+	var lines []commandLogLine
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, parseCommandLogLine(scanner.Text(), stream))
+	}
+	return lines
+}
+
+func parseCommandLogLine(raw, stream string) commandLogLine {
+	ts, text, ok := strings.Cut(raw, " ")
+	if !ok {
+		if _, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+			return commandLogLine{Timestamp: raw, Stream: stream}
+		}
+		return commandLogLine{Stream: stream, Text: raw}
+	}
+	if _, err := time.Parse(time.RFC3339Nano, ts); err != nil {
+		return commandLogLine{Stream: stream, Text: raw}
+	}
+	return commandLogLine{Timestamp: ts, Stream: stream, Text: text}
+}
+
+// sortCommandLogLines interleaves stdout and stderr lines by timestamp.
+func sortCommandLogLines(lines []commandLogLine) {
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].Timestamp < lines[j].Timestamp
+	})
+}
+
+func writeCommandLogLines(w io.Writer, lines []commandLogLine, stream string) {
+	for _, line := range lines {
+		if stream == "all" || stream == "" {
+			_, _ = fmt.Fprintf(w, "%s [%s] %s\n", line.Timestamp, line.Stream, line.Text)
+		} else {
+			_, _ = fmt.Fprintf(w, "%s %s\n", line.Timestamp, line.Text)
+		}
+	}
+}
+
+func tailCommandLog(w io.Writer, artifactsDir string, record commandLogRecord, stream string, n int, follow bool) error {
+	streams, err := selectedLogStreams(record, stream)
+	if err != nil {
+		return err
+	}
+
+	offsets := make([]int64, len(streams))
+	var lines []commandLogLine
+	for i, s := range streams {
+		data, err := os.ReadFile(filepath.Join(artifactsDir, filepath.FromSlash(s.ref)))
+		if err != nil {
+			return err
+		}
+		complete := completeLinesLength(data)
+		offsets[i] = int64(complete)
+		lines = append(lines, parseCommandLogLines(data[:complete], s.name)...)
+	}
+	sortCommandLogLines(lines)
+	if n >= 0 && len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	writeCommandLogLines(w, lines, stream)
+
+	if !follow {
+		return nil
+	}
+	for {
+		finished := commandLogFinished(artifactsDir, streams)
+		var fresh []commandLogLine
+		for i, s := range streams {
+			data, next, err := readLogFrom(filepath.Join(artifactsDir, filepath.FromSlash(s.ref)), offsets[i], finished)
+			if err != nil {
+				return err
+			}
+			offsets[i] = next
+			fresh = append(fresh, parseCommandLogLines(data, s.name)...)
+		}
+		sortCommandLogLines(fresh)
+		writeCommandLogLines(w, fresh, stream)
+		if finished {
+			return nil
+		}
+		time.Sleep(logsFollowInterval)
+	}
+}
+
+// readLogFrom returns complete lines written after offset. When final is set, a
+// trailing partial line is returned as well.
+func readLogFrom(path string, offset int64, final bool) ([]byte, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, offset, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, offset, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, offset, err
+	}
+	if !final {
+		data = data[:completeLinesLength(data)]
+	}
+	return data, offset + int64(len(data)), nil
+}
+
+func completeLinesLength(data []byte) int {
+	idx := bytes.LastIndexByte(data, '\n')
+	return idx + 1
+}
+
+// commandLogFinished reports whether every followed stream has been closed by its
+// writer. Apply closes the logs when the command (or retry attempt) exits, whether or
+// not a progress entry references them.
+func commandLogFinished(artifactsDir string, streams []logStreamRef) bool {
+	for _, s := range streams {
+		if !small.CommandOutputLogDone(artifactsDir, s.ref) {
+			return false
+		}
+	}
+	return true
+}
+
+func pruneCommandLogs(artifactsDir string, cutoff time.Time, keepReplayID string, dryRun bool) (int, error) {
+	sets, err := small.ListCommandLogs(artifactsDir, "")
+	if err != nil {
+		return 0, err
+	}
+	pruned := 0
+	for _, set := range sets {
+		if set.ReplayID == keepReplayID || !set.ModTime.Before(cutoff) {
+			continue
+		}
+		pruned++
+		if dryRun {
+			continue
+		}
+		if err := small.RemoveCommandLogSet(artifactsDir, set); err != nil {
+			return pruned, err
+		}
+	}
+	return pruned, nil
+}
+
+// parseRetentionDuration accepts Go durations plus a whole-day suffix ("14d").
+func parseRetentionDuration(value string) (time.Duration, error) {
+	trimmed := strings.TrimSpace(value)
+	if strings.HasSuffix(trimmed, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(trimmed, "d"))
+		if err != nil || days < 0 {
+			return 0, fmt.Errorf("invalid retention %q (expected e.g. 72h or 14d)", value)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(trimmed)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid retention %q (expected e.g. 72h or 14d)", value)
+	}
+	return d, nil
+}
+
+func formatLogsListOutput(records []commandLogRecord, jsonOutput bool) (string, error) {
+	if jsonOutput {
+		if records == nil {
+			records = []commandLogRecord{}
+		}
+		data, err := json.MarshalIndent(logsListOutput{Logs: records}, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	}
+
+	if len(records) == 0 {
+		return "no command logs found\n", nil
+	}
+
+	var buffer bytes.Buffer
+	writer := tabwriter.NewWriter(&buffer, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(writer, "id\treplayId\ttask\tstatus\tstdout\tstderr\tcommand")
+	for _, record := range records {
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			record.ID,
+			shortID(record.ReplayID, 8),
+			dashIfEmpty(record.TaskID),
+			dashIfEmpty(record.Status),
+			formatLogSize(record.StdoutRef, record.StdoutSize),
+			formatLogSize(record.StderrRef, record.StderrSize),
+			dashIfEmpty(record.Command),
+		)
+	}
+	_ = writer.Flush()
+	return buffer.String(), nil
+}
+
+func formatLogSize(ref string, size int64) string {
+	if ref == "" {
+		return "-"
+	}
+	return fmt.Sprintf("%dB", size)
+}
+
+func dashIfEmpty(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,188 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func TestApplyCapturesOutputLogs(t *testing.T) {
+	t.Setenv(progressModeEnvVar, string(progressModeSignal))
+
+	tmpDir := t.TempDir()
+	writeArtifacts(t, tmpDir, defaultArtifacts())
+	mustSaveWorkspace(t, tmpDir, workspace.KindRepoRoot)
+
+	cmd := applyCmd()
+	cmd.SetArgs([]string{"--dir", tmpDir, "--workspace", "any", "--task", "task-1", "--cmd", "echo out-line; echo err-line >&2"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("apply execute failed: %v", err)
+	}
+
+	progress, err := loadProgressData(filepath.Join(tmpDir, ".small", "progress.small.yml"))
+	if err != nil {
+		t.Fatalf("failed to load progress: %v", err)
+	}
+	entry := progress.Entries[len(progress.Entries)-1]
+	for stream, want := range map[string]string{"stdout": "out-line", "stderr": "err-line"} {
+		ref := stringVal(entry[stream+"_ref"])
+		if ref == "" {
+			t.Fatalf("expected %s_ref on progress entry", stream)
+		}
+		content, err := os.ReadFile(filepath.Join(tmpDir, filepath.FromSlash(ref)))
+		if err != nil {
+			t.Fatalf("failed to read %s log: %v", stream, err)
+		}
+		if !strings.Contains(string(content), want) {
+			t.Fatalf("%s log = %q, want it to contain %q", stream, content, want)
+		}
+		if entry[stream+"_bytes"] != len(want)+1 {
+			t.Fatalf("%s_bytes = %v, want %d", stream, entry[stream+"_bytes"], len(want)+1)
+		}
+		if len(stringVal(entry[stream+"_sha256"])) != 64 {
+			t.Fatalf("expected %s_sha256 to be recorded", stream)
+		}
+	}
+
+	commandRef := stringVal(entry["command_ref"])
+	stdoutRef := stringVal(entry["stdout_ref"])
+	if strings.TrimSuffix(commandRef, ".txt") != strings.TrimSuffix(stdoutRef, ".stdout.log") {
+		t.Fatalf("expected output logs beside the command log, got %q and %q", commandRef, stdoutRef)
+	}
+
+	if code := runVerify(tmpDir, true, true, workspace.ScopeAny); code != ExitValid {
+		t.Fatalf("expected strict verify to pass with output log fields, got %d", code)
+	}
+
+	records, err := loadCommandLogRecords(tmpDir, logsFilter{taskID: "task-1"})
+	if err != nil {
+		t.Fatalf("loadCommandLogRecords error: %v", err)
+	}
+	if len(records) != 1 || records[0].Status != "completed" {
+		t.Fatalf("expected one completed task-1 log, got %#v", records)
+	}
+
+	record, err := selectCommandLog(tmpDir, logsFilter{}, nil)
+	if err != nil {
+		t.Fatalf("selectCommandLog error: %v", err)
+	}
+	var out bytes.Buffer
+	if err := tailCommandLog(&out, tmpDir, record, "all", 10, true); err != nil {
+		t.Fatalf("tailCommandLog error: %v", err)
+	}
+	if !strings.Contains(out.String(), "[stdout] out-line") || !strings.Contains(out.String(), "[stderr] err-line") {
+		t.Fatalf("unexpected tail output: %q", out.String())
+	}
+}
+
+func TestLogsTailLastLines(t *testing.T) {
+	tmpDir := t.TempDir()
+	replayId := strings.Repeat("d", 64)
+	timestamp := formatProgressTimestamp(time.Date(2026, 1, 22, 10, 0, 0, 0, time.UTC))
+	log, err := small.CreateCommandOutputLog(tmpDir, replayId, timestamp, small.CommandStreamStdout)
+	if err != nil {
+		t.Fatalf("CreateCommandOutputLog error: %v", err)
+	}
+	_, _ = log.Write([]byte("one\ntwo\nthree\n"))
+	_ = log.Close()
+
+	record, err := selectCommandLog(tmpDir, logsFilter{replayId: "dddd"}, nil)
+	if err != nil {
+		t.Fatalf("selectCommandLog error: %v", err)
+	}
+	var out bytes.Buffer
+	if err := tailCommandLog(&out, tmpDir, record, "stdout", 2, false); err != nil {
+		t.Fatalf("tailCommandLog error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], " two") || !strings.HasSuffix(lines[1], " three") {
+		t.Fatalf("unexpected tail output: %q", out.String())
+	}
+
+	if _, err := selectCommandLog(tmpDir, logsFilter{}, []string{"nope"}); err == nil {
+		t.Fatal("expected error for unknown log id")
+	}
+}
+
+func TestLogsTailFollowStopsWhenLogCloses(t *testing.T) {
+	// No progress entry ever references this log, as with apply --cmd outside a task.
+	tmpDir := t.TempDir()
+	replayId := strings.Repeat("e", 64)
+	timestamp := formatProgressTimestamp(time.Date(2026, 1, 22, 10, 0, 0, 0, time.UTC))
+	log, err := small.CreateCommandOutputLog(tmpDir, replayId, timestamp, small.CommandStreamStdout)
+	if err != nil {
+		t.Fatalf("CreateCommandOutputLog error: %v", err)
+	}
+	_, _ = log.Write([]byte("first\n"))
+
+	record, err := selectCommandLog(tmpDir, logsFilter{replayId: "eeee"}, nil)
+	if err != nil {
+		t.Fatalf("selectCommandLog error: %v", err)
+	}
+	var out bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		done <- tailCommandLog(&out, tmpDir, record, "stdout", -1, true)
+	}()
+
+	_, _ = log.Write([]byte("second\n"))
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("tailCommandLog error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("tail --follow did not stop after the log was closed")
+	}
+	if !strings.Contains(out.String(), " first\n") || !strings.Contains(out.String(), " second\n") {
+		t.Fatalf("unexpected follow output: %q", out.String())
+	}
+}
+
+func TestLogsPruneKeepsCurrentRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	oldReplay := strings.Repeat("e", 64)
+	currentReplay := strings.Repeat("f", 64)
+	timestamp := formatProgressTimestamp(time.Date(2026, 1, 22, 10, 0, 0, 0, time.UTC))
+	for _, replayId := range []string{oldReplay, currentReplay} {
+		if _, _, err := small.WriteCommandLog(tmpDir, replayId, timestamp, "echo hi"); err != nil {
+			t.Fatalf("WriteCommandLog error: %v", err)
+		}
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	for _, replayId := range []string{oldReplay, currentReplay} {
+		dir := small.CacheCommandLogsDir(tmpDir, replayId)
+		entries, _ := os.ReadDir(dir)
+		for _, entry := range entries {
+			_ = os.Chtimes(filepath.Join(dir, entry.Name()), old, old)
+		}
+	}
+
+	retention, err := parseRetentionDuration("1d")
+	if err != nil {
+		t.Fatalf("parseRetentionDuration error: %v", err)
+	}
+	pruned, err := pruneCommandLogs(tmpDir, time.Now().Add(-retention), currentReplay, false)
+	if err != nil {
+		t.Fatalf("pruneCommandLogs error: %v", err)
+	}
+	if pruned != 1 {
+		t.Fatalf("expected 1 pruned log, got %d", pruned)
+	}
+	sets, err := small.ListCommandLogs(tmpDir, "")
+	if err != nil {
+		t.Fatalf("ListCommandLogs error: %v", err)
+	}
+	if len(sets) != 1 || sets[0].ReplayID != currentReplay {
+		t.Fatalf("expected only the current run's logs to remain, got %#v", sets)
+	}
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+)
+
+// auditOwnership runs the git ownership audit for verify and turns each agent-attributed
+// commit to a human-owned artifact into a verify error.
+func auditOwnership(artifactsDir string, artifacts map[string]*small.Artifact, opts verifyOptions) ([]verifyError, error) {
+	auditOpts := small.OwnershipAuditOptions{
+		Since:    opts.ownershipBase,
+		AgentIDs: recordedAgentIDs(artifacts),
+	}
+	if strings.TrimSpace(opts.humanIdentities) != "" {
+		humans, err := loadHumanIdentities(opts.humanIdentities)
+		if err != nil {
+			return nil, err
+		}
+		auditOpts.Humans = humans
+	}
+
+	violations, err := small.AuditHumanOwnership(artifactsDir, auditOpts)
+	if err != nil {
+		return nil, err
+	}
+	errs := make([]verifyError, 0, len(violations))
+	for _, v := range violations {
+		errs = append(errs, verifyError{
+			message: fmt.Sprintf("Ownership [%s]: commit %s by %s: %s", v.Artifact, shortID(v.Commit, 12), v.Author, v.Reason),
+			fix:     fmt.Sprintf("Revert the change and have a human make it, or add the human to --human-identities. %s is owner: human", v.Artifact),
+		})
+	}
+	return errs, nil
+}
+
+// recordedAgentIDs returns the agent ids SMALL recorded on progress entries and the handoff.
+func recordedAgentIDs(artifacts map[string]*small.Artifact) []string {
+	seen := map[string]bool{}
+	var ids []string
+	add := func(value any) {
+		agent, _ := value.(map[string]any)
+		id := strings.TrimSpace(stringVal(agent["id"]))
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if progress, ok := artifacts["progress"]; ok && progress.Data != nil {
+		entries, _ := progress.Data["entries"].([]any)
+		for _, raw := range entries {
+			entry, _ := raw.(map[string]any)
+			add(entry["agent"])
+		}
+	}
+	if handoff, ok := artifacts["handoff"]; ok && handoff.Data != nil {
+		add(handoff.Data["agent"])
+	}
+	return ids
+}
+
+// loadHumanIdentities reads one name, email, or "Name <email>" per line; blank lines and
+// lines starting with # are ignored.
+func loadHumanIdentities(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read human identities: %w", err)
+	}
+	defer file.Close()
+
+	var identities []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		identities = append(identities, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read human identities: %w", err)
+	}
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("%s lists no human identities", path)
+	}
+	return identities, nil
+}
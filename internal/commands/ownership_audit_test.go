@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func TestVerifyOwnershipAudit(t *testing.T) {
+	setAgentFlag(t, "builder-1")
+	tmpDir := setupScopeDriftRepo(t)
+	if err := appendProgressEntry(tmpDir, map[string]any{"task_id": "meta/note", "status": "in_progress", "evidence": "agent at work"}); err != nil {
+		t.Fatal(err)
+	}
+	mustGit(t, tmpDir, "add", "-A")
+	mustGit(t, tmpDir, "commit", "-q", "-m", "record progress")
+
+	opts := verifyOptions{ci: true, scope: workspace.ScopeRoot, ownershipAudit: true}
+	if code := runVerifyWithOptions(tmpDir, opts); code != ExitValid {
+		t.Fatalf("expected human-authored history to pass, got %d", code)
+	}
+
+	intentPath := filepath.Join(tmpDir, ".small", "intent.small.yml")
+	if err := os.WriteFile(intentPath, []byte(scopedIntent+"# widened by an agent\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mustGit(t, tmpDir, "-c", "user.name=builder-1", "commit", "-q", "-a", "-m", "edit intent")
+	if code := runVerifyWithOptions(tmpDir, opts); code != ExitInvalid {
+		t.Fatalf("expected an intent edit by a recorded agent to fail, got %d", code)
+	}
+
+	opts.ownershipBase = "HEAD"
+	if code := runVerifyWithOptions(tmpDir, opts); code != ExitValid {
+		t.Fatalf("expected --ownership-base HEAD to skip the edit, got %d", code)
+	}
+
+	opts.ownershipBase = ""
+	opts.humanIdentities = filepath.Join(tmpDir, "missing-humans")
+	if code := runVerifyWithOptions(tmpDir, opts); code != ExitSystemError {
+		t.Fatalf("expected a missing identities file to be a system error, got %d", code)
+	}
+}
@@ -23,14 +23,110 @@ type PlanData struct {
 }
 
 // PlanTask represents a task in the plan
-// id and title are required by v1.0.0; steps, acceptance, status, dependencies are optional CLI conveniences
+// id and title are required by v1.0.0; steps, acceptance, status, dependencies, run,
+// parent, milestone, estimate, superseded_by, and lease are optional CLI conveniences. A
+// task named as another task's parent derives its status from its subtasks; superseded_by
+// names the task that replaced a cancelled one.
 type PlanTask struct {
-	ID           string   `yaml:"id"`
-	Title        string   `yaml:"title"`
-	Steps        []string `yaml:"steps,omitempty"`
-	Acceptance   []string `yaml:"acceptance,omitempty"`
-	Status       string   `yaml:"status,omitempty"`
-	Dependencies []string `yaml:"dependencies,omitempty"`
+	ID           string                `yaml:"id"`
+	Title        string                `yaml:"title"`
+	Parent       string                `yaml:"parent,omitempty"`
+	Milestone    string                `yaml:"milestone,omitempty"`
+	Estimate     string                `yaml:"estimate,omitempty"`
+	SupersededBy string                `yaml:"superseded_by,omitempty"`
+	Lease        *PlanTaskLease        `yaml:"lease,omitempty"`
+	Steps        []string              `yaml:"steps,omitempty"`
+	Acceptance   []AcceptanceCriterion `yaml:"acceptance,omitempty"`
+	Status       string                `yaml:"status,omitempty"`
+	Dependencies []string              `yaml:"dependencies,omitempty"`
+	Run          *PlanTaskRun          `yaml:"run,omitempty"`
+}
+
+// AcceptanceCriterion is a task acceptance criterion. A bare string is prose that is
+// reported but never executed; a mapping declares one machine-checkable check:
+// command (with expect_exit), file_exists, file with contains (regex), or file with
+// json_path and equals.
+type AcceptanceCriterion struct {
+	Description string    `yaml:"description,omitempty"`
+	Command     string    `yaml:"command,omitempty"`
+	ExpectExit  *int      `yaml:"expect_exit,omitempty"`
+	Timeout     string    `yaml:"timeout,omitempty"`
+	FileExists  string    `yaml:"file_exists,omitempty"`
+	File        string    `yaml:"file,omitempty"`
+	Contains    string    `yaml:"contains,omitempty"`
+	JSONPath    string    `yaml:"json_path,omitempty"`
+	Equals      yaml.Node `yaml:"equals,omitempty"`
+}
+
+// UnmarshalYAML accepts either a prose string or a check mapping.
+func (c *AcceptanceCriterion) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		c.Description = node.Value
+		return nil
+	}
+	type plain AcceptanceCriterion
+	var decoded plain
+	if err := node.Decode(&decoded); err != nil {
+		return err
+	}
+	*c = AcceptanceCriterion(decoded)
+	return nil
+}
+
+// MarshalYAML writes prose criteria back as plain strings.
+func (c AcceptanceCriterion) MarshalYAML() (any, error) {
+	if c.isProse() {
+		return c.Description, nil
+	}
+	type plain AcceptanceCriterion
+	return plain(c), nil
+}
+
+func (c AcceptanceCriterion) isProse() bool {
+	return c.Command == "" && c.ExpectExit == nil && c.Timeout == "" && c.FileExists == "" &&
+		c.File == "" && c.Contains == "" && c.JSONPath == "" && c.Equals.Kind == 0
+}
+
+// PlanTaskRun is an executable recipe for a task, run step by step by small apply --task.
+// dir and env apply to every command; timeout is the default per-command timeout.
+type PlanTaskRun struct {
+	Dir      string            `yaml:"dir,omitempty"`
+	Env      map[string]string `yaml:"env,omitempty"`
+	Timeout  string            `yaml:"timeout,omitempty"`
+	Commands []PlanRunCommand  `yaml:"commands"`
+}
+
+// PlanRunCommand is a single recipe step. A bare string is shorthand for {cmd: ...}.
+type PlanRunCommand struct {
+	Cmd        string            `yaml:"cmd"`
+	Dir        string            `yaml:"dir,omitempty"`
+	Env        map[string]string `yaml:"env,omitempty"`
+	ExpectExit *int              `yaml:"expect_exit,omitempty"`
+	Timeout    string            `yaml:"timeout,omitempty"`
+}
+
+// UnmarshalYAML accepts either a command string or a mapping.
+func (c *PlanRunCommand) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		c.Cmd = node.Value
+		return nil
+	}
+	type plain PlanRunCommand
+	var decoded plain
+	if err := node.Decode(&decoded); err != nil {
+		return err
+	}
+	*c = PlanRunCommand(decoded)
+	return nil
+}
+
+// MarshalYAML writes the string shorthand back when only cmd is set.
+func (c PlanRunCommand) MarshalYAML() (any, error) {
+	if c.Dir == "" && len(c.Env) == 0 && c.ExpectExit == nil && c.Timeout == "" {
+		return c.Cmd, nil
+	}
+	type plain PlanRunCommand
+	return plain(c), nil
 }
 
 type planProgressRecord struct {
@@ -47,12 +143,20 @@ func planCmd() *cobra.Command {
 		reset         bool
 		yes           bool
 		addTask       string
+		parentID      string
+		milestone     string
+		estimate      string
 		doneID        string
 		pendingID     string
 		blockedID     string
+		cancelID      string
+		supersedeID   string
+		supersededBy  string
+		reason        string
 		dependsArg    string
 		dir           string
 		workspaceFlag string
+		ifMatch       string
 	)
 
 	cmd := &cobra.Command{
@@ -84,6 +188,12 @@ func planCmd() *cobra.Command {
 				}
 			}
 
+			unlock, err := lockArtifactRevision(artifactsDir, "plan.small.yml", ifMatch)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
 			planPath := filepath.Join(smallDir, "plan.small.yml")
 
 			planExists := small.ArtifactExists(artifactsDir, "plan.small.yml")
@@ -119,13 +229,36 @@ func planCmd() *cobra.Command {
 			modified := false
 			progressRecords := make([]planProgressRecord, 0)
 
+			if (parentID != "" || milestone != "" || estimate != "") && addTask == "" {
+				return fmt.Errorf("--parent, --milestone, and --estimate require --add")
+			}
+			if (supersedeID == "") != (supersededBy == "") {
+				return fmt.Errorf("--supersede and --by must be used together")
+			}
+			if reason != "" && cancelID == "" && supersedeID == "" {
+				return fmt.Errorf("--reason requires --cancel or --supersede")
+			}
+			if estimate != "" {
+				if _, err := small.ParseTaskEstimate(estimate); err != nil {
+					return err
+				}
+			}
+
 			// Handle --add flag
 			if addTask != "" {
+				if parentID != "" {
+					if parent, _ := findTask(&plan, parentID); parent == nil {
+						return fmt.Errorf("parent task %s not found", parentID)
+					}
+				}
 				newID := generateNextTaskID(plan.Tasks)
 				newTask := PlanTask{
-					ID:     newID,
-					Title:  addTask,
-					Status: "pending",
+					ID:        newID,
+					Title:     addTask,
+					Parent:    parentID,
+					Milestone: strings.TrimSpace(milestone),
+					Estimate:  strings.TrimSpace(estimate),
+					Status:    "pending",
 				}
 				plan.Tasks = append(plan.Tasks, newTask)
 				progressRecords = append(progressRecords, planProgressRecord{
@@ -177,6 +310,43 @@ func planCmd() *cobra.Command {
 				fmt.Printf("Marked task %s as blocked\n", blockedID)
 			}
 
+			// Handle --cancel flag
+			if cancelID != "" {
+				if strings.TrimSpace(reason) == "" {
+					return fmt.Errorf("--cancel requires --reason")
+				}
+				if err := cancelPlanTask(&plan, cancelID, ""); err != nil {
+					return err
+				}
+				progressRecords = append(progressRecords, planProgressRecord{
+					TaskID:   cancelID,
+					Status:   "cancelled",
+					Evidence: strings.TrimSpace(reason),
+					Notes:    "small plan --cancel",
+				})
+				modified = true
+				fmt.Printf("Cancelled task %s\n", cancelID)
+			}
+
+			// Handle --supersede flag
+			if supersedeID != "" {
+				if err := cancelPlanTask(&plan, supersedeID, supersededBy); err != nil {
+					return err
+				}
+				evidence := fmt.Sprintf("Superseded by %s", supersededBy)
+				if strings.TrimSpace(reason) != "" {
+					evidence += ": " + strings.TrimSpace(reason)
+				}
+				progressRecords = append(progressRecords, planProgressRecord{
+					TaskID:   supersedeID,
+					Status:   "cancelled",
+					Evidence: evidence,
+					Notes:    "small plan --supersede",
+				})
+				modified = true
+				fmt.Printf("Task %s superseded by %s\n", supersedeID, supersededBy)
+			}
+
 			// Handle --depends flag
 			if dependsArg != "" {
 				parts := strings.SplitN(dependsArg, ":", 2)
@@ -234,12 +404,31 @@ func planCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&reset, "reset", false, "Reset plan to template (requires --yes)")
 	cmd.Flags().BoolVar(&yes, "yes", false, "Confirm destructive operations (required with --reset)")
 	cmd.Flags().StringVar(&addTask, "add", "", "Add a new task with the given title")
+	cmd.Flags().StringVar(&parentID, "parent", "", "Add the new task as a subtask of this task (with --add)")
+	cmd.Flags().StringVar(&milestone, "milestone", "", "Milestone for the new task (with --add)")
+	cmd.Flags().StringVar(&estimate, "estimate", "", "Effort estimate for the new task, e.g. 90m or 2h (with --add)")
 	cmd.Flags().StringVar(&doneID, "done", "", "Mark task as completed by ID")
 	cmd.Flags().StringVar(&pendingID, "pending", "", "Mark task as pending by ID")
 	cmd.Flags().StringVar(&blockedID, "blocked", "", "Mark task as blocked by ID")
+	cmd.Flags().StringVar(&cancelID, "cancel", "", "Mark task as cancelled by ID (requires --reason)")
+	cmd.Flags().StringVar(&supersedeID, "supersede", "", "Cancel task by ID in favour of the task given by --by")
+	cmd.Flags().StringVar(&supersededBy, "by", "", "Replacement task ID for --supersede")
+	cmd.Flags().StringVar(&reason, "reason", "", "Why the task was cancelled or superseded (recorded as evidence)")
 	cmd.Flags().StringVar(&dependsArg, "depends", "", "Add dependency edge (format: <task-id>:<dep-id>)")
 	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
 	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root or any)")
+	registerIfMatchFlag(cmd, &ifMatch, "plan.small.yml")
+
+	cmd.AddCommand(planAcceptCheckCmd())
+	cmd.AddCommand(planGraphCmd())
+	cmd.AddCommand(planEditCmd())
+	cmd.AddCommand(planRmCmd())
+	cmd.AddCommand(planMoveCmd())
+	cmd.AddCommand(planSplitCmd())
+	cmd.AddCommand(planMergeCmd())
+	cmd.AddCommand(planClaimCmd())
+	cmd.AddCommand(planRenewCmd())
+	cmd.AddCommand(planReleaseCmd())
 
 	return cmd
 }
@@ -277,15 +466,21 @@ func loadPlan(path string) (*PlanData, error) {
 }
 
 func savePlan(path string, plan *PlanData) error {
+	rollUpPlanStatuses(plan.Tasks)
 	data, err := small.MarshalYAMLWithQuotedVersion(plan)
 	if err != nil {
 		return err
 	}
 
-	if err := os.WriteFile(path, data, 0o644); err != nil {
+	artifactsDir := filepath.Dir(filepath.Dir(path))
+	unlock, err := small.LockWorkspace(artifactsDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := small.WriteFileAtomic(path, data, 0o644); err != nil {
 		return err
 	}
-	artifactsDir := filepath.Dir(filepath.Dir(path))
 	if err := touchWorkspaceUpdatedAt(artifactsDir); err != nil {
 		return err
 	}
@@ -322,7 +517,16 @@ func setTaskStatus(plan *PlanData, taskID, status string) error {
 	if task == nil {
 		return fmt.Errorf("task %s not found", taskID)
 	}
+	if len(planChildren(plan.Tasks)[taskID]) > 0 {
+		return fmt.Errorf("task %s has subtasks; its status is derived from them", taskID)
+	}
 	task.Status = status
+	if status != "cancelled" {
+		task.SupersededBy = ""
+	}
+	if planStatusDone(status) {
+		task.Lease = nil
+	}
 	return nil
 }
 
@@ -351,12 +555,33 @@ func addDependency(plan *PlanData, taskID, depID string) error {
 	}
 
 	task.Dependencies = append(task.Dependencies, depID)
+	if cycles := small.DependencyCycles(planTaskIDs(plan.Tasks), planDependencyMap(plan.Tasks)); len(cycles) > 0 {
+		task.Dependencies = task.Dependencies[:len(task.Dependencies)-1]
+		return fmt.Errorf("dependency %s would create a cycle: %s", depID, strings.Join(cycles[0], " -> "))
+	}
 	return nil
 }
 
+func planTaskIDs(tasks []PlanTask) []string {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, strings.TrimSpace(task.ID))
+	}
+	return ids
+}
+
+func planDependencyMap(tasks []PlanTask) map[string][]string {
+	deps := make(map[string][]string, len(tasks))
+	for _, task := range tasks {
+		for _, dep := range task.Dependencies {
+			deps[strings.TrimSpace(task.ID)] = append(deps[strings.TrimSpace(task.ID)], strings.TrimSpace(dep))
+		}
+	}
+	return deps
+}
+
 func ensureProgressEvidence(artifactsDir, taskID string) error {
 	progressPath := filepath.Join(artifactsDir, small.SmallDir, "progress.small.yml")
-	var data map[string]any
 
 	if !small.ArtifactExists(artifactsDir, "progress.small.yml") {
 		progress := ProgressData{
@@ -373,15 +598,12 @@ func ensureProgressEvidence(artifactsDir, taskID string) error {
 		}
 	}
 
-	raw, err := os.ReadFile(progressPath)
+	progressArtifact, err := small.LoadArtifact(artifactsDir, "progress.small.yml")
 	if err != nil {
 		return err
 	}
-	if err := yaml.Unmarshal(raw, &data); err != nil {
-		return err
-	}
 
-	entries, _ := data["entries"].([]any)
+	entries, _ := progressArtifact.Data["entries"].([]any)
 	if entries == nil {
 		entries = []any{}
 	}
@@ -0,0 +1,459 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// Acceptance check types reported in verification results.
+const (
+	acceptanceCheckManual     = "manual"
+	acceptanceCheckCommand    = "command"
+	acceptanceCheckFileExists = "file_exists"
+	acceptanceCheckContains   = "file_contains"
+	acceptanceCheckJSONPath   = "json_path_equals"
+	acceptanceCheckInvalid    = "invalid"
+)
+
+// acceptanceCheckResult is the outcome of a single acceptance criterion.
+// Manual (prose) criteria are reported but never fail the run.
+type acceptanceCheckResult struct {
+	Index       int    `json:"index"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Target      string `json:"target,omitempty"`
+	Passed      bool   `json:"passed"`
+	Detail      string `json:"detail"`
+	ExitCode    *int   `json:"exit_code,omitempty"`
+}
+
+// acceptanceReport collects the results for one task.
+type acceptanceReport struct {
+	TaskID string                  `json:"task_id"`
+	Passed bool                    `json:"passed"`
+	Checks []acceptanceCheckResult `json:"checks"`
+}
+
+func (r acceptanceReport) counts() (executable, passed int) {
+	for _, check := range r.Checks {
+		if check.Type == acceptanceCheckManual {
+			continue
+		}
+		executable++
+		if check.Passed {
+			passed++
+		}
+	}
+	return executable, passed
+}
+
+func (r acceptanceReport) summary() string {
+	executable, passed := r.counts()
+	if executable == 0 {
+		return "Acceptance: no executable checks"
+	}
+	return fmt.Sprintf("Acceptance: %d/%d checks passed", passed, executable)
+}
+
+// verification returns the report in the shape stored on progress entries.
+func (r acceptanceReport) verification() map[string]any {
+	checks := make([]any, 0, len(r.Checks))
+	for _, check := range r.Checks {
+		item := map[string]any{
+			"index":  check.Index,
+			"type":   check.Type,
+			"passed": check.Passed,
+			"detail": check.Detail,
+		}
+		if check.Description != "" {
+			item["description"] = check.Description
+		}
+		if check.Target != "" {
+			item["target"] = check.Target
+		}
+		if check.ExitCode != nil {
+			item["exit_code"] = *check.ExitCode
+		}
+		checks = append(checks, item)
+	}
+	return map[string]any{
+		"summary": r.summary(),
+		"passed":  r.Passed,
+		"checks":  checks,
+	}
+}
+
+func planAcceptCheckCmd() *cobra.Command {
+	var (
+		dir           string
+		workspaceFlag string
+		jsonOutput    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "accept-check <task-id>",
+		Short: "Run a task's machine-checkable acceptance criteria",
+		Long: `Runs the executable acceptance criteria declared on a plan task and reports
+each result. Prose criteria are listed as manual and never fail the check.
+Exits non-zero when any executable check fails. Nothing is recorded; use
+small checkpoint --status completed --verify-acceptance to gate completion.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dir == "" {
+				dir = baseDir
+			}
+			artifactsDir := resolveArtifactsDir(dir)
+
+			scope, err := workspace.ParseScope(workspaceFlag)
+			if err != nil {
+				return err
+			}
+			if scope != workspace.ScopeAny {
+				if err := enforceWorkspaceScope(artifactsDir, scope); err != nil {
+					return err
+				}
+			}
+
+			plan, err := loadPlan(filepath.Join(artifactsDir, small.SmallDir, "plan.small.yml"))
+			if err != nil {
+				return fmt.Errorf("failed to load plan.small.yml: %w", err)
+			}
+			taskID := strings.TrimSpace(args[0])
+			task, _ := findTask(plan, taskID)
+			if task == nil {
+				return fmt.Errorf("task %s not found", taskID)
+			}
+
+			report := runAcceptanceChecks(artifactsDir, task)
+			if jsonOutput {
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(data))
+			} else {
+				printAcceptanceReport(cmd.OutOrStdout(), report)
+			}
+			if !report.Passed {
+				executable, passed := report.counts()
+				return fmt.Errorf("acceptance checks failed for task %s: %d of %d failed", taskID, executable-passed, executable)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
+	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root, examples, or any)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+
+	return cmd
+}
+
+func printAcceptanceReport(w io.Writer, report acceptanceReport) {
+	if len(report.Checks) == 0 {
+		fmt.Fprintf(w, "Task %s has no acceptance criteria\n", report.TaskID)
+		return
+	}
+	for _, check := range report.Checks {
+		label := "FAIL"
+		switch {
+		case check.Type == acceptanceCheckManual:
+			label = "MANUAL"
+		case check.Passed:
+			label = "PASS"
+		}
+		name := check.Description
+		if name == "" {
+			name = check.Target
+		}
+		fmt.Fprintf(w, "[%s] %d. %s: %s\n", label, check.Index, name, check.Detail)
+	}
+	fmt.Fprintln(w, report.summary())
+}
+
+// runAcceptanceChecks evaluates every acceptance criterion on the task in order.
+// Paths and commands are resolved relative to the workspace root.
+func runAcceptanceChecks(baseDir string, task *PlanTask) acceptanceReport {
+	report := acceptanceReport{TaskID: task.ID, Passed: true, Checks: []acceptanceCheckResult{}}
+	for i, criterion := range task.Acceptance {
+		result := evaluateAcceptanceCriterion(baseDir, criterion)
+		result.Index = i + 1
+		result.Description = criterion.Description
+		if result.Type != acceptanceCheckManual && !result.Passed {
+			report.Passed = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+// acceptanceCheckType returns the check a criterion declares, rejecting criteria that
+// mix check kinds or leave one half-specified.
+func acceptanceCheckType(c AcceptanceCriterion) (string, error) {
+	var kinds []string
+	if c.Command != "" {
+		kinds = append(kinds, acceptanceCheckCommand)
+	}
+	if c.FileExists != "" {
+		kinds = append(kinds, acceptanceCheckFileExists)
+	}
+	if c.Contains != "" {
+		kinds = append(kinds, acceptanceCheckContains)
+	}
+	if c.JSONPath != "" {
+		kinds = append(kinds, acceptanceCheckJSONPath)
+	}
+	switch {
+	case len(kinds) > 1:
+		return "", fmt.Errorf("criterion declares more than one check (%s)", strings.Join(kinds, ", "))
+	case len(kinds) == 0:
+		if c.File != "" || c.Equals.Kind != 0 || c.ExpectExit != nil || c.Timeout != "" {
+			return "", fmt.Errorf("criterion has check fields but no command, file_exists, contains, or json_path")
+		}
+		return acceptanceCheckManual, nil
+	}
+	kind := kinds[0]
+	if (kind == acceptanceCheckContains || kind == acceptanceCheckJSONPath) && c.File == "" {
+		return "", fmt.Errorf("%s requires file", kind)
+	}
+	if (kind == acceptanceCheckCommand || kind == acceptanceCheckFileExists) && c.File != "" {
+		return "", fmt.Errorf("file only applies to contains and json_path checks")
+	}
+	if kind == acceptanceCheckJSONPath && c.Equals.Kind == 0 {
+		return "", fmt.Errorf("json_path requires equals")
+	}
+	if kind != acceptanceCheckCommand && (c.ExpectExit != nil || c.Timeout != "") {
+		return "", fmt.Errorf("expect_exit and timeout only apply to command checks")
+	}
+	if kind != acceptanceCheckJSONPath && c.Equals.Kind != 0 {
+		return "", fmt.Errorf("equals only applies to json_path checks")
+	}
+	return kind, nil
+}
+
+func evaluateAcceptanceCriterion(baseDir string, c AcceptanceCriterion) acceptanceCheckResult {
+	kind, err := acceptanceCheckType(c)
+	if err != nil {
+		return acceptanceCheckResult{Type: acceptanceCheckInvalid, Detail: err.Error()}
+	}
+	result := acceptanceCheckResult{Type: kind}
+	switch kind {
+	case acceptanceCheckManual:
+		result.Detail = "not machine-checkable"
+	case acceptanceCheckCommand:
+		result.Target = c.Command
+		evaluateAcceptanceCommand(baseDir, c, &result)
+	case acceptanceCheckFileExists:
+		result.Target = c.FileExists
+		full, err := resolveAcceptanceFile(baseDir, c.FileExists)
+		if err != nil {
+			result.Detail = err.Error()
+			break
+		}
+		if _, err := os.Stat(full); err != nil {
+			result.Detail = "file does not exist"
+			break
+		}
+		result.Passed = true
+		result.Detail = "file exists"
+	case acceptanceCheckContains:
+		result.Target = c.File
+		evaluateAcceptanceContains(baseDir, c, &result)
+	case acceptanceCheckJSONPath:
+		result.Target = c.File + " " + c.JSONPath
+		evaluateAcceptanceJSONPath(baseDir, c, &result)
+	}
+	return result
+}
+
+func evaluateAcceptanceCommand(baseDir string, c AcceptanceCriterion, result *acceptanceCheckResult) {
+	expectExit := 0
+	if c.ExpectExit != nil {
+		expectExit = *c.ExpectExit
+	}
+	opts := applyExecOptions{Command: c.Command, Dir: baseDir}
+	if strings.TrimSpace(c.Timeout) != "" {
+		timeout, err := parseRecipeTimeout(c.Timeout)
+		if err != nil {
+			result.Detail = fmt.Sprintf("timeout: %v", err)
+			return
+		}
+		opts.Timeout = timeout
+	}
+	var output lockedBuffer
+	opts.Stdout = &output
+	opts.Stderr = &output
+
+	run := runApplyCommand(opts)
+	exitCode := run.ExitCode
+	result.ExitCode = &exitCode
+	if run.terminated() {
+		result.Detail = applyTerminationSummary(run, opts.Timeout)
+		return
+	}
+	if run.startFailed() {
+		result.Detail = fmt.Sprintf("command failed to start: %v", run.Err)
+		return
+	}
+	if exitCode != expectExit {
+		result.Detail = fmt.Sprintf("exit code %d, expected %d", exitCode, expectExit)
+		if tail := lastOutputLine(output.String()); tail != "" {
+			result.Detail += ": " + tail
+		}
+		return
+	}
+	result.Passed = true
+	result.Detail = fmt.Sprintf("exit code %d", exitCode)
+}
+
+func evaluateAcceptanceContains(baseDir string, c AcceptanceCriterion, result *acceptanceCheckResult) {
+	pattern, err := regexp.Compile("(?m)" + c.Contains)
+	if err != nil {
+		result.Detail = fmt.Sprintf("invalid contains pattern: %v", err)
+		return
+	}
+	full, err := resolveAcceptanceFile(baseDir, c.File)
+	if err != nil {
+		result.Detail = err.Error()
+		return
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		result.Detail = fmt.Sprintf("cannot read file: %v", err)
+		return
+	}
+	if !pattern.Match(data) {
+		result.Detail = fmt.Sprintf("no match for %q", c.Contains)
+		return
+	}
+	result.Passed = true
+	result.Detail = fmt.Sprintf("matches %q", c.Contains)
+}
+
+func evaluateAcceptanceJSONPath(baseDir string, c AcceptanceCriterion, result *acceptanceCheckResult) {
+	full, err := resolveAcceptanceFile(baseDir, c.File)
+	if err != nil {
+		result.Detail = err.Error()
+		return
+	}
+	data, err := os.ReadFile(full)
+	if err != nil {
+		result.Detail = fmt.Sprintf("cannot read file: %v", err)
+		return
+	}
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		result.Detail = fmt.Sprintf("invalid JSON: %v", err)
+		return
+	}
+	actual, err := lookupJSONPath(doc, c.JSONPath)
+	if err != nil {
+		result.Detail = err.Error()
+		return
+	}
+	var expected any
+	if err := c.Equals.Decode(&expected); err != nil {
+		result.Detail = fmt.Sprintf("invalid equals value: %v", err)
+		return
+	}
+	actualJSON, err := json.Marshal(actual)
+	if err != nil {
+		result.Detail = err.Error()
+		return
+	}
+	expectedJSON, err := json.Marshal(expected)
+	if err != nil {
+		result.Detail = fmt.Sprintf("invalid equals value: %v", err)
+		return
+	}
+	if string(actualJSON) != string(expectedJSON) {
+		result.Detail = fmt.Sprintf("got %s, expected %s", actualJSON, expectedJSON)
+		return
+	}
+	result.Passed = true
+	result.Detail = fmt.Sprintf("equals %s", expectedJSON)
+}
+
+func resolveAcceptanceFile(baseDir, rel string) (string, error) {
+	resolved, err := resolveWorkspaceRelPath(baseDir, rel)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(baseDir, filepath.FromSlash(resolved)), nil
+}
+
+// lookupJSONPath resolves a dotted path such as "$.build.targets[0].name" against a
+// decoded JSON document. The leading "$" is optional.
+func lookupJSONPath(doc any, jsonPath string) (any, error) {
+	p := strings.TrimPrefix(strings.TrimSpace(jsonPath), "$")
+	current := doc
+	for p != "" {
+		switch {
+		case strings.HasPrefix(p, "."):
+			p = p[1:]
+		case strings.HasPrefix(p, "["):
+			end := strings.Index(p, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("json_path %q: unterminated index", jsonPath)
+			}
+			index, err := strconv.Atoi(p[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("json_path %q: invalid index %q", jsonPath, p[1:end])
+			}
+			items, ok := current.([]any)
+			if !ok || index < 0 || index >= len(items) {
+				return nil, fmt.Errorf("json_path %q: index %d not found", jsonPath, index)
+			}
+			current = items[index]
+			p = p[end+1:]
+		default:
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			key := p[:end]
+			object, ok := current.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("json_path %q: %q is not an object", jsonPath, key)
+			}
+			value, ok := object[key]
+			if !ok {
+				return nil, fmt.Errorf("json_path %q: key %q not found", jsonPath, key)
+			}
+			current = value
+			p = p[end:]
+		}
+	}
+	return current, nil
+}
+
+// verifyTaskAcceptance loads a task's acceptance criteria and runs them for checkpoint.
+func verifyTaskAcceptance(baseDir string, plan *PlanData, taskID string) (acceptanceReport, error) {
+	task, _ := findTask(plan, taskID)
+	if task == nil {
+		return acceptanceReport{}, fmt.Errorf("task %s not found", taskID)
+	}
+	return runAcceptanceChecks(baseDir, task), nil
+}
+
+func lastOutputLine(output string) string {
+	trimmed := strings.TrimRight(output, "\n")
+	if i := strings.LastIndex(trimmed, "\n"); i >= 0 {
+		trimmed = trimmed[i+1:]
+	}
+	const maxLen = 200
+	if len(trimmed) > maxLen {
+		trimmed = trimmed[:maxLen]
+	}
+	return strings.TrimSpace(trimmed)
+}
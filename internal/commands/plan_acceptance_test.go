@@ -0,0 +1,234 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+	"gopkg.in/yaml.v3"
+)
+
+func writeAcceptancePlan(t *testing.T, dir, acceptance string) {
+	t.Helper()
+	artifacts := defaultArtifacts()
+	artifacts["plan.small.yml"] = `small_version: "1.0.0"
+owner: "agent"
+tasks:
+  - id: "task-1"
+    title: "Test task"
+    acceptance:
+` + acceptance
+	writeArtifacts(t, dir, artifacts)
+	mustSaveWorkspace(t, dir, workspace.KindRepoRoot)
+}
+
+func TestAcceptanceCriterionYAML(t *testing.T) {
+	var criteria []AcceptanceCriterion
+	input := `- "Reads well"
+- description: "config version"
+  file: "package.json"
+  json_path: "$.version"
+  equals: "1.2.0"
+`
+	if err := yaml.Unmarshal([]byte(input), &criteria); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+	if criteria[0].Description != "Reads well" || !criteria[0].isProse() {
+		t.Fatalf("expected prose criterion, got %+v", criteria[0])
+	}
+	if criteria[1].JSONPath != "$.version" || criteria[1].Equals.Kind == 0 {
+		t.Fatalf("expected json_path criterion, got %+v", criteria[1])
+	}
+
+	out, err := yaml.Marshal(criteria)
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	if !strings.Contains(string(out), "- Reads well\n") || !strings.Contains(string(out), `equals: "1.2.0"`) {
+		t.Fatalf("unexpected round-trip output:\n%s", out)
+	}
+}
+
+func TestAcceptanceCheckType(t *testing.T) {
+	one := 1
+	cases := []struct {
+		criterion AcceptanceCriterion
+		want      string
+		wantErr   bool
+	}{
+		{AcceptanceCriterion{Description: "prose"}, acceptanceCheckManual, false},
+		{AcceptanceCriterion{Command: "true", ExpectExit: &one}, acceptanceCheckCommand, false},
+		{AcceptanceCriterion{FileExists: "a"}, acceptanceCheckFileExists, false},
+		{AcceptanceCriterion{File: "a", Contains: "x"}, acceptanceCheckContains, false},
+		{AcceptanceCriterion{File: "a", JSONPath: "x", Equals: yaml.Node{Kind: yaml.ScalarNode, Value: "1"}}, acceptanceCheckJSONPath, false},
+		{AcceptanceCriterion{Command: "true", FileExists: "a"}, "", true},
+		{AcceptanceCriterion{Contains: "x"}, "", true},
+		{AcceptanceCriterion{File: "a", JSONPath: "x"}, "", true},
+		{AcceptanceCriterion{FileExists: "a", ExpectExit: &one}, "", true},
+		{AcceptanceCriterion{File: "a"}, "", true},
+	}
+	for _, tc := range cases {
+		got, err := acceptanceCheckType(tc.criterion)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("expected error for %+v", tc.criterion)
+			}
+			continue
+		}
+		if err != nil || got != tc.want {
+			t.Fatalf("acceptanceCheckType(%+v) = %q, %v; want %q", tc.criterion, got, err, tc.want)
+		}
+	}
+}
+
+func TestLookupJSONPath(t *testing.T) {
+	doc := map[string]any{
+		"build": map[string]any{
+			"targets": []any{map[string]any{"name": "linux"}},
+		},
+	}
+	got, err := lookupJSONPath(doc, "$.build.targets[0].name")
+	if err != nil || got != "linux" {
+		t.Fatalf("lookup = %v, %v; want linux", got, err)
+	}
+	if _, err := lookupJSONPath(doc, "build.targets[3]"); err == nil {
+		t.Fatal("expected out-of-range index error")
+	}
+	if _, err := lookupJSONPath(doc, "build.missing"); err == nil {
+		t.Fatal("expected missing key error")
+	}
+}
+
+func TestRunAcceptanceChecks(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("# Title\n## Usage\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "package.json"), []byte(`{"version":"1.2.0","count":3}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var task PlanTask
+	input := `id: task-1
+title: Test
+acceptance:
+  - "Manual review"
+  - command: "exit 2"
+    expect_exit: 2
+  - file_exists: "README.md"
+  - file: "README.md"
+    contains: "^## Usage$"
+  - file: "package.json"
+    json_path: "count"
+    equals: 3
+  - file: "package.json"
+    json_path: "$.version"
+    equals: "2.0.0"
+`
+	if err := yaml.Unmarshal([]byte(input), &task); err != nil {
+		t.Fatalf("unmarshal failed: %v", err)
+	}
+
+	report := runAcceptanceChecks(tmpDir, &task)
+	if report.Passed {
+		t.Fatal("expected report to fail on the version mismatch")
+	}
+	wantPassed := []bool{false, true, true, true, true, false}
+	for i, check := range report.Checks {
+		if check.Passed != wantPassed[i] {
+			t.Fatalf("check %d (%s) passed = %v, want %v: %s", i+1, check.Type, check.Passed, wantPassed[i], check.Detail)
+		}
+	}
+	if report.Checks[0].Type != acceptanceCheckManual {
+		t.Fatalf("expected prose criterion to be manual, got %s", report.Checks[0].Type)
+	}
+	if got := report.summary(); got != "Acceptance: 4/5 checks passed" {
+		t.Fatalf("summary = %q", got)
+	}
+}
+
+func TestCheckpointVerifyAcceptance(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeAcceptancePlan(t, tmpDir, `      - file_exists: "done.txt"
+`)
+	planPath := filepath.Join(tmpDir, ".small", "plan.small.yml")
+	progressPath := filepath.Join(tmpDir, ".small", "progress.small.yml")
+	originalPlan, _ := os.ReadFile(planPath)
+	originalProgress, _ := os.ReadFile(progressPath)
+
+	cmd := checkpointCmd()
+	cmd.SetArgs([]string{"--dir", tmpDir, "--workspace", "any", "--task", "task-1", "--status", "completed", "--verify-acceptance"})
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "refusing to mark task-1 completed") {
+		t.Fatalf("expected checkpoint to be refused, got %v", err)
+	}
+	finalPlan, _ := os.ReadFile(planPath)
+	finalProgress, _ := os.ReadFile(progressPath)
+	if string(finalPlan) != string(originalPlan) || string(finalProgress) != string(originalProgress) {
+		t.Fatal("expected plan and progress to be unchanged after refused checkpoint")
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "done.txt"), []byte("ok"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd = checkpointCmd()
+	cmd.SetArgs([]string{"--dir", tmpDir, "--workspace", "any", "--task", "task-1", "--status", "completed", "--verify-acceptance"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected checkpoint to succeed, got %v", err)
+	}
+
+	progress, err := loadProgressData(progressPath)
+	if err != nil {
+		t.Fatalf("failed to load progress: %v", err)
+	}
+	entry := progress.Entries[len(progress.Entries)-1]
+	verification, ok := entry["verification"].(map[string]any)
+	if !ok {
+		t.Fatalf("expected structured verification, got %#v", entry["verification"])
+	}
+	if verification["passed"] != true || verification["summary"] != "Acceptance: 1/1 checks passed" {
+		t.Fatalf("unexpected verification: %#v", verification)
+	}
+	checks, _ := verification["checks"].([]any)
+	if len(checks) != 1 {
+		t.Fatalf("expected 1 recorded check, got %#v", verification["checks"])
+	}
+
+	if code := runVerify(tmpDir, false, true, workspace.ScopeAny); code != ExitValid {
+		t.Fatalf("expected artifacts to verify after checkpoint, got exit code %d", code)
+	}
+}
+
+func TestCheckpointVerifyAcceptanceRequiresCompleted(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeAcceptancePlan(t, tmpDir, `      - "Manual"
+`)
+	cmd := checkpointCmd()
+	cmd.SetArgs([]string{"--dir", tmpDir, "--workspace", "any", "--task", "task-1", "--status", "blocked", "--verify-acceptance"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "requires --status completed") {
+		t.Fatalf("expected --verify-acceptance to require completed, got %v", err)
+	}
+}
+
+func TestPlanAcceptCheckCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeAcceptancePlan(t, tmpDir, `      - command: "test -f ready.txt"
+`)
+
+	cmd := planCmd()
+	cmd.SetArgs([]string{"accept-check", "task-1", "--dir", tmpDir, "--workspace", "any"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "1 of 1 failed") {
+		t.Fatalf("expected accept-check failure, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "ready.txt"), []byte("ok"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd = planCmd()
+	cmd.SetArgs([]string{"accept-check", "task-1", "--dir", tmpDir, "--workspace", "any", "--json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected accept-check to pass, got %v", err)
+	}
+}
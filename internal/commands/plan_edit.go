@@ -0,0 +1,523 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// planMetaTaskID records plan edits for tasks that no longer exist in the plan.
+const planMetaTaskID = "meta/plan"
+
+// planEditFlags are the flags shared by the plan editing subcommands.
+type planEditFlags struct {
+	dir           string
+	workspaceFlag string
+	ifMatch       string
+}
+
+func (f *planEditFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.dir, "dir", ".", "Directory containing .small/ artifacts")
+	cmd.Flags().StringVar(&f.workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root or any)")
+	registerIfMatchFlag(cmd, &f.ifMatch, "plan.small.yml")
+}
+
+// mutatePlan loads the plan, applies change, checks the dependency graph, saves the plan,
+// and appends the audit progress records change returns. Nothing is written on error.
+func (f *planEditFlags) mutatePlan(change func(artifactsDir string, plan *PlanData) ([]planProgressRecord, error)) error {
+	dir := f.dir
+	if dir == "" {
+		dir = baseDir
+	}
+	artifactsDir := resolveArtifactsDir(dir)
+
+	scope, err := workspace.ParseScope(f.workspaceFlag)
+	if err != nil {
+		return err
+	}
+	if scope == workspace.ScopeExamples {
+		return fmt.Errorf("--workspace examples is not supported for plan (use --workspace any to bypass)")
+	}
+	if scope != workspace.ScopeAny {
+		if err := enforceWorkspaceScope(artifactsDir, workspace.ScopeRoot); err != nil {
+			return err
+		}
+	}
+
+	unlock, err := lockArtifactRevision(artifactsDir, "plan.small.yml", f.ifMatch)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	planPath := filepath.Join(artifactsDir, small.SmallDir, "plan.small.yml")
+	plan, err := loadPlan(planPath)
+	if err != nil {
+		return fmt.Errorf("failed to load plan.small.yml: %w", err)
+	}
+	records, err := change(artifactsDir, plan)
+	if err != nil {
+		return err
+	}
+	if len(plan.Tasks) == 0 {
+		return fmt.Errorf("plan must keep at least one task")
+	}
+	if err := validatePlanDependencyGraph(plan.Tasks); err != nil {
+		return err
+	}
+
+	if err := savePlan(planPath, plan); err != nil {
+		return fmt.Errorf("failed to save plan: %w", err)
+	}
+	if _, err := ensureWorkspaceRunReplayID(artifactsDir); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := appendPlanProgress(artifactsDir, record.TaskID, record.Status, record.Evidence, record.Notes); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func planEditCmd() *cobra.Command {
+	var (
+		flags           planEditFlags
+		title           string
+		steps           []string
+		acceptance      []string
+		clearSteps      bool
+		clearAcceptance bool
+		estimate        string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "edit <task-id>",
+		Short: "Edit a task's title, steps, acceptance criteria, or estimate",
+		Long: `Edits a plan task in place. --step and --acceptance append prose entries;
+use --clear-steps or --clear-acceptance to replace the existing lists. --estimate
+sets the effort estimate; pass an empty value to clear it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID := strings.TrimSpace(args[0])
+			return flags.mutatePlan(func(_ string, plan *PlanData) ([]planProgressRecord, error) {
+				task, _ := findTask(plan, taskID)
+				if task == nil {
+					return nil, fmt.Errorf("task %s not found", taskID)
+				}
+
+				var changes []string
+				if cmd.Flags().Changed("title") {
+					if strings.TrimSpace(title) == "" {
+						return nil, fmt.Errorf("--title must not be empty")
+					}
+					task.Title = strings.TrimSpace(title)
+					changes = append(changes, "title")
+				}
+				if clearSteps {
+					task.Steps = nil
+				}
+				task.Steps = append(task.Steps, steps...)
+				if clearSteps || len(steps) > 0 {
+					changes = append(changes, "steps")
+				}
+				if clearAcceptance {
+					task.Acceptance = nil
+				}
+				for _, description := range acceptance {
+					task.Acceptance = append(task.Acceptance, AcceptanceCriterion{Description: description})
+				}
+				if clearAcceptance || len(acceptance) > 0 {
+					changes = append(changes, "acceptance")
+				}
+				if cmd.Flags().Changed("estimate") {
+					if strings.TrimSpace(estimate) != "" {
+						if _, err := small.ParseTaskEstimate(estimate); err != nil {
+							return nil, err
+						}
+					}
+					task.Estimate = strings.TrimSpace(estimate)
+					changes = append(changes, "estimate")
+				}
+				if len(changes) == 0 {
+					return nil, fmt.Errorf("nothing to edit (use --title, --step, --acceptance, --estimate, --clear-steps, or --clear-acceptance)")
+				}
+
+				fmt.Printf("Edited task %s (%s)\n", taskID, strings.Join(changes, ", "))
+				return []planProgressRecord{{
+					TaskID:   taskID,
+					Status:   task.Status,
+					Evidence: fmt.Sprintf("Edited task %s: %s", taskID, strings.Join(changes, ", ")),
+					Notes:    "small plan edit",
+				}}, nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&title, "title", "", "New task title")
+	cmd.Flags().StringArrayVar(&steps, "step", nil, "Append a step (repeatable)")
+	cmd.Flags().StringArrayVar(&acceptance, "acceptance", nil, "Append a prose acceptance criterion (repeatable)")
+	cmd.Flags().BoolVar(&clearSteps, "clear-steps", false, "Remove existing steps before appending")
+	cmd.Flags().BoolVar(&clearAcceptance, "clear-acceptance", false, "Remove existing acceptance criteria before appending")
+	cmd.Flags().StringVar(&estimate, "estimate", "", "Effort estimate, e.g. 90m or 2h (empty clears it)")
+	flags.register(cmd)
+
+	return cmd
+}
+
+func planRmCmd() *cobra.Command {
+	var flags planEditFlags
+
+	cmd := &cobra.Command{
+		Use:   "rm <task-id>",
+		Short: "Remove a task from the plan",
+		Long: `Removes a task. Tasks that depended on it inherit its dependencies, so the
+remaining order is preserved, and its subtasks move up to its parent. A task that
+progress entries reference can only be removed once it is cancelled.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID := strings.TrimSpace(args[0])
+			return flags.mutatePlan(func(artifactsDir string, plan *PlanData) ([]planProgressRecord, error) {
+				task, _ := findTask(plan, taskID)
+				if task == nil {
+					return nil, fmt.Errorf("task %s not found", taskID)
+				}
+				if err := ensurePlanTaskRemovable(artifactsDir, *task); err != nil {
+					return nil, err
+				}
+				removed := *task
+				removePlanTask(plan, taskID, removed.Dependencies)
+
+				fmt.Printf("Removed task %s: %s\n", taskID, removed.Title)
+				return []planProgressRecord{{
+					TaskID:   planMetaTaskID,
+					Evidence: fmt.Sprintf("Removed task %s (%s)", taskID, removed.Title),
+					Notes:    "small plan rm",
+				}}, nil
+			})
+		},
+	}
+
+	flags.register(cmd)
+	return cmd
+}
+
+func planMoveCmd() *cobra.Command {
+	var (
+		flags  planEditFlags
+		before string
+		after  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "move <task-id>",
+		Short: "Reorder a task before or after another task",
+		Long:  "Moves a task within plan order. Dependencies are unchanged.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID := strings.TrimSpace(args[0])
+			if (before == "") == (after == "") {
+				return fmt.Errorf("exactly one of --before or --after is required")
+			}
+			return flags.mutatePlan(func(_ string, plan *PlanData) ([]planProgressRecord, error) {
+				task, index := findTask(plan, taskID)
+				if task == nil {
+					return nil, fmt.Errorf("task %s not found", taskID)
+				}
+				anchorID, placement := strings.TrimSpace(before), "before"
+				if after != "" {
+					anchorID, placement = strings.TrimSpace(after), "after"
+				}
+				if anchorID == taskID {
+					return nil, fmt.Errorf("task cannot be moved relative to itself")
+				}
+				if anchor, _ := findTask(plan, anchorID); anchor == nil {
+					return nil, fmt.Errorf("task %s not found", anchorID)
+				}
+
+				moved := *task
+				plan.Tasks = append(plan.Tasks[:index], plan.Tasks[index+1:]...)
+				_, target := findTask(plan, anchorID)
+				if placement == "after" {
+					target++
+				}
+				plan.Tasks = append(plan.Tasks[:target], append([]PlanTask{moved}, plan.Tasks[target:]...)...)
+
+				fmt.Printf("Moved task %s %s %s\n", taskID, placement, anchorID)
+				return []planProgressRecord{{
+					TaskID:   taskID,
+					Status:   moved.Status,
+					Evidence: fmt.Sprintf("Moved task %s %s %s", taskID, placement, anchorID),
+					Notes:    "small plan move",
+				}}, nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&before, "before", "", "Place the task before this task")
+	cmd.Flags().StringVar(&after, "after", "", "Place the task after this task")
+	flags.register(cmd)
+
+	return cmd
+}
+
+func planSplitCmd() *cobra.Command {
+	var (
+		flags planEditFlags
+		into  []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "split <task-id>",
+		Short: "Split part of a task into new tasks",
+		Long: `Creates a new task for each --into title, placed after the original. The
+original keeps its id, history, steps, acceptance, and run recipe. New tasks
+inherit the original's dependencies, and tasks that depended on the original
+now depend on every part.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID := strings.TrimSpace(args[0])
+			if len(into) == 0 {
+				return fmt.Errorf("--into is required")
+			}
+			return flags.mutatePlan(func(_ string, plan *PlanData) ([]planProgressRecord, error) {
+				task, index := findTask(plan, taskID)
+				if task == nil {
+					return nil, fmt.Errorf("task %s not found", taskID)
+				}
+				dependencies := append([]string{}, task.Dependencies...)
+
+				var parts []PlanTask
+				var newIDs []string
+				for _, title := range into {
+					if strings.TrimSpace(title) == "" {
+						return nil, fmt.Errorf("--into titles must not be empty")
+					}
+					part := PlanTask{
+						ID:           generateNextTaskID(append(plan.Tasks, parts...)),
+						Title:        strings.TrimSpace(title),
+						Parent:       task.Parent,
+						Milestone:    task.Milestone,
+						Status:       "pending",
+						Dependencies: append([]string{}, dependencies...),
+					}
+					parts = append(parts, part)
+					newIDs = append(newIDs, part.ID)
+				}
+				for i := range plan.Tasks {
+					if planTaskDependsOn(plan.Tasks[i], taskID) {
+						plan.Tasks[i].Dependencies = appendMissingDependencies(plan.Tasks[i].Dependencies, newIDs...)
+					}
+				}
+				plan.Tasks = append(plan.Tasks[:index+1], append(parts, plan.Tasks[index+1:]...)...)
+
+				records := []planProgressRecord{{
+					TaskID:   taskID,
+					Status:   plan.Tasks[index].Status,
+					Evidence: fmt.Sprintf("Split task %s into %s", taskID, strings.Join(newIDs, ", ")),
+					Notes:    "small plan split",
+				}}
+				for _, part := range parts {
+					fmt.Printf("Added task %s: %s\n", part.ID, part.Title)
+					records = append(records, planProgressRecord{
+						TaskID:   part.ID,
+						Status:   "pending",
+						Evidence: fmt.Sprintf("Added task %s via small plan split %s", part.ID, taskID),
+						Notes:    part.Title,
+					})
+				}
+				return records, nil
+			})
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&into, "into", nil, "Title of a new task split from the original (repeatable)")
+	flags.register(cmd)
+
+	return cmd
+}
+
+func planMergeCmd() *cobra.Command {
+	var (
+		flags planEditFlags
+		title string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "merge <task-id> <other-task-id>",
+		Short: "Merge a task into another",
+		Long: `Merges the second task into the first and removes the second. Steps,
+acceptance criteria, and dependencies are combined, and tasks that depended on
+the second task now depend on the first. If the first task is completed and the
+second is not, the merged task takes the second task's status. Like rm, the
+second task can only be merged if no progress references it or it is cancelled.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keepID, mergeID := strings.TrimSpace(args[0]), strings.TrimSpace(args[1])
+			if keepID == mergeID {
+				return fmt.Errorf("cannot merge a task into itself")
+			}
+			return flags.mutatePlan(func(artifactsDir string, plan *PlanData) ([]planProgressRecord, error) {
+				keep, _ := findTask(plan, keepID)
+				if keep == nil {
+					return nil, fmt.Errorf("task %s not found", keepID)
+				}
+				merged, _ := findTask(plan, mergeID)
+				if merged == nil {
+					return nil, fmt.Errorf("task %s not found", mergeID)
+				}
+				if keep.Run != nil && merged.Run != nil {
+					return nil, fmt.Errorf("tasks %s and %s both have run recipes; combine them by hand first", keepID, mergeID)
+				}
+				if err := ensurePlanTaskRemovable(artifactsDir, *merged); err != nil {
+					return nil, err
+				}
+
+				other := *merged
+				if strings.TrimSpace(title) != "" {
+					keep.Title = strings.TrimSpace(title)
+				}
+				keep.Steps = append(keep.Steps, other.Steps...)
+				keep.Acceptance = append(keep.Acceptance, other.Acceptance...)
+				if keep.Run == nil {
+					keep.Run = other.Run
+				}
+				if normalizePlanStatus(keep.Status) == "completed" && normalizePlanStatus(other.Status) != "completed" {
+					keep.Status = other.Status
+				}
+				for _, dep := range other.Dependencies {
+					if strings.TrimSpace(dep) != keepID {
+						keep.Dependencies = appendMissingDependencies(keep.Dependencies, dep)
+					}
+				}
+				for i := range plan.Tasks {
+					if strings.TrimSpace(plan.Tasks[i].SupersededBy) == mergeID && plan.Tasks[i].ID != keepID {
+						plan.Tasks[i].SupersededBy = keepID
+					}
+					if strings.TrimSpace(plan.Tasks[i].Parent) != mergeID {
+						continue
+					}
+					if plan.Tasks[i].ID == keepID {
+						plan.Tasks[i].Parent = other.Parent
+					} else {
+						plan.Tasks[i].Parent = keepID
+					}
+				}
+				removePlanTask(plan, mergeID, []string{keepID})
+
+				fmt.Printf("Merged task %s into %s\n", mergeID, keepID)
+				keep, _ = findTask(plan, keepID)
+				return []planProgressRecord{{
+					TaskID:   keepID,
+					Status:   keep.Status,
+					Evidence: fmt.Sprintf("Merged task %s (%s) into %s", mergeID, other.Title, keepID),
+					Notes:    "small plan merge",
+				}}, nil
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&title, "title", "", "Title for the merged task (default: keep the first task's title)")
+	flags.register(cmd)
+
+	return cmd
+}
+
+// ensurePlanTaskRemovable refuses to drop a task that progress still references, unless
+// the task was cancelled in the plan or by its latest progress entry.
+func ensurePlanTaskRemovable(artifactsDir string, task PlanTask) error {
+	if normalizePlanStatus(task.Status) == "cancelled" {
+		return nil
+	}
+	progress, err := loadProgressData(filepath.Join(artifactsDir, small.SmallDir, "progress.small.yml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to load progress.small.yml: %w", err)
+	}
+	referenced, lastStatus := 0, ""
+	for _, entry := range progress.Entries {
+		if strings.TrimSpace(stringVal(entry["task_id"])) != task.ID {
+			continue
+		}
+		referenced++
+		if status := strings.TrimSpace(stringVal(entry["status"])); status != "" {
+			lastStatus = status
+		}
+	}
+	if referenced > 0 && lastStatus != "cancelled" {
+		return fmt.Errorf("task %s is referenced by %d progress entries; cancel it first", task.ID, referenced)
+	}
+	return nil
+}
+
+// removePlanTask deletes taskID and replaces every dependency on it with replacement.
+// Subtasks and superseded_by links move to the removed task's own parent and replacement.
+func removePlanTask(plan *PlanData, taskID string, replacement []string) {
+	removed, _ := findTask(plan, taskID)
+	parent, supersededBy := "", ""
+	if removed != nil {
+		parent, supersededBy = removed.Parent, removed.SupersededBy
+	}
+	tasks := plan.Tasks[:0]
+	for _, task := range plan.Tasks {
+		if task.ID == taskID {
+			continue
+		}
+		if strings.TrimSpace(task.Parent) == taskID {
+			task.Parent = parent
+		}
+		if strings.TrimSpace(task.SupersededBy) == taskID {
+			task.SupersededBy = supersededBy
+		}
+		if planTaskDependsOn(task, taskID) {
+			var kept []string
+			for _, dep := range task.Dependencies {
+				if strings.TrimSpace(dep) != taskID {
+					kept = append(kept, dep)
+				}
+			}
+			var inherited []string
+			for _, dep := range replacement {
+				if strings.TrimSpace(dep) != task.ID {
+					inherited = append(inherited, dep)
+				}
+			}
+			task.Dependencies = appendMissingDependencies(kept, inherited...)
+		}
+		tasks = append(tasks, task)
+	}
+	plan.Tasks = tasks
+}
+
+func planTaskDependsOn(task PlanTask, depID string) bool {
+	for _, dep := range task.Dependencies {
+		if strings.TrimSpace(dep) == depID {
+			return true
+		}
+	}
+	return false
+}
+
+// appendMissingDependencies appends each id not already in deps.
+func appendMissingDependencies(deps []string, ids ...string) []string {
+	for _, id := range ids {
+		present := false
+		for _, dep := range deps {
+			if strings.TrimSpace(dep) == strings.TrimSpace(id) {
+				present = true
+				break
+			}
+		}
+		if !present {
+			deps = append(deps, id)
+		}
+	}
+	return deps
+}
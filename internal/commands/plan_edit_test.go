@@ -0,0 +1,173 @@
+package commands
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+const planEditTestTasks = `  - id: "task-1"
+    title: "Schema"
+  - id: "task-2"
+    title: "API"
+    dependencies: ["task-1"]
+  - id: "task-3"
+    title: "Client"
+    dependencies: ["task-2"]
+  - id: "task-4"
+    title: "Docs"
+    status: "completed"
+    dependencies: ["task-2"]
+`
+
+func setupPlanEditWorkspace(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	artifacts := defaultArtifacts()
+	artifacts["plan.small.yml"] = "small_version: \"1.0.0\"\nowner: \"agent\"\ntasks:\n" + planEditTestTasks
+	writeArtifacts(t, dir, artifacts)
+	mustSaveWorkspace(t, dir, workspace.KindRepoRoot)
+	return dir
+}
+
+func runPlanSubcommand(t *testing.T, dir string, args ...string) error {
+	t.Helper()
+	cmd := planCmd()
+	cmd.SetArgs(append(args, "--dir", dir))
+	return cmd.Execute()
+}
+
+func loadPlanForTest(t *testing.T, dir string) *PlanData {
+	t.Helper()
+	plan, err := loadPlan(filepath.Join(dir, small.SmallDir, "plan.small.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return plan
+}
+
+func planTaskIDOrder(plan *PlanData) []string {
+	return planTaskIDs(plan.Tasks)
+}
+
+func TestPlanEditSubcommand(t *testing.T) {
+	dir := setupPlanEditWorkspace(t)
+	if err := runPlanSubcommand(t, dir, "edit", "task-2", "--title", "Public API", "--step", "Design", "--acceptance", "Reviewed"); err != nil {
+		t.Fatalf("plan edit failed: %v", err)
+	}
+	task, _ := findTask(loadPlanForTest(t, dir), "task-2")
+	if task.Title != "Public API" || !reflect.DeepEqual(task.Steps, []string{"Design"}) || len(task.Acceptance) != 1 || task.Acceptance[0].Description != "Reviewed" {
+		t.Fatalf("unexpected task after edit: %+v", task)
+	}
+	progress, err := loadProgressData(filepath.Join(dir, small.SmallDir, "progress.small.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := progress.Entries[len(progress.Entries)-1]
+	if last["task_id"] != "task-2" || last["evidence"] != "Edited task task-2: title, steps, acceptance" {
+		t.Fatalf("unexpected audit entry: %#v", last)
+	}
+
+	if err := runPlanSubcommand(t, dir, "edit", "task-2"); err == nil {
+		t.Fatal("expected edit without changes to fail")
+	}
+}
+
+func TestPlanRmSubcommand(t *testing.T) {
+	dir := setupPlanEditWorkspace(t)
+	if err := appendPlanProgress(dir, "task-2", "in_progress", "Started API", ""); err != nil {
+		t.Fatal(err)
+	}
+	err := runPlanSubcommand(t, dir, "rm", "task-2")
+	if err == nil || !strings.Contains(err.Error(), "cancel it first") {
+		t.Fatalf("expected referenced task to be refused, got %v", err)
+	}
+
+	if err := appendPlanProgress(dir, "task-2", "cancelled", "Dropped API", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := runPlanSubcommand(t, dir, "rm", "task-2"); err != nil {
+		t.Fatalf("plan rm failed: %v", err)
+	}
+	plan := loadPlanForTest(t, dir)
+	if !reflect.DeepEqual(planTaskIDOrder(plan), []string{"task-1", "task-3", "task-4"}) {
+		t.Fatalf("unexpected tasks: %v", planTaskIDOrder(plan))
+	}
+	client, _ := findTask(plan, "task-3")
+	if !reflect.DeepEqual(client.Dependencies, []string{"task-1"}) {
+		t.Fatalf("expected dependents to inherit dependencies, got %v", client.Dependencies)
+	}
+	if err := ensureProgressEvidence(dir, "task-4"); err != nil {
+		t.Fatal(err)
+	}
+	if code := runVerify(dir, true, true, workspace.ScopeAny); code != ExitValid {
+		t.Fatalf("expected strict verify to pass after rm, got exit code %d", code)
+	}
+}
+
+func TestPlanMoveSubcommand(t *testing.T) {
+	dir := setupPlanEditWorkspace(t)
+	if err := runPlanSubcommand(t, dir, "move", "task-4", "--before", "task-2"); err != nil {
+		t.Fatalf("plan move failed: %v", err)
+	}
+	if got := planTaskIDOrder(loadPlanForTest(t, dir)); !reflect.DeepEqual(got, []string{"task-1", "task-4", "task-2", "task-3"}) {
+		t.Fatalf("order after --before = %v", got)
+	}
+	if err := runPlanSubcommand(t, dir, "move", "task-1", "--after", "task-3"); err != nil {
+		t.Fatalf("plan move failed: %v", err)
+	}
+	if got := planTaskIDOrder(loadPlanForTest(t, dir)); !reflect.DeepEqual(got, []string{"task-4", "task-2", "task-3", "task-1"}) {
+		t.Fatalf("order after --after = %v", got)
+	}
+	if err := runPlanSubcommand(t, dir, "move", "task-1", "--before", "task-2", "--after", "task-3"); err == nil {
+		t.Fatal("expected --before with --after to fail")
+	}
+}
+
+func TestPlanSplitSubcommand(t *testing.T) {
+	dir := setupPlanEditWorkspace(t)
+	if err := runPlanSubcommand(t, dir, "split", "task-2", "--into", "API auth", "--into", "API pagination"); err != nil {
+		t.Fatalf("plan split failed: %v", err)
+	}
+	plan := loadPlanForTest(t, dir)
+	if got := planTaskIDOrder(plan); !reflect.DeepEqual(got, []string{"task-1", "task-2", "task-5", "task-6", "task-3", "task-4"}) {
+		t.Fatalf("order after split = %v", got)
+	}
+	part, _ := findTask(plan, "task-6")
+	if part.Title != "API pagination" || !reflect.DeepEqual(part.Dependencies, []string{"task-1"}) {
+		t.Fatalf("unexpected split part: %+v", part)
+	}
+	client, _ := findTask(plan, "task-3")
+	if !reflect.DeepEqual(client.Dependencies, []string{"task-2", "task-5", "task-6"}) {
+		t.Fatalf("expected dependents to wait on every part, got %v", client.Dependencies)
+	}
+}
+
+func TestPlanMergeSubcommand(t *testing.T) {
+	dir := setupPlanEditWorkspace(t)
+	if err := runPlanSubcommand(t, dir, "merge", "task-4", "task-3", "--title", "Client and docs"); err != nil {
+		t.Fatalf("plan merge failed: %v", err)
+	}
+	plan := loadPlanForTest(t, dir)
+	if got := planTaskIDOrder(plan); !reflect.DeepEqual(got, []string{"task-1", "task-2", "task-4"}) {
+		t.Fatalf("order after merge = %v", got)
+	}
+	merged, _ := findTask(plan, "task-4")
+	if merged.Title != "Client and docs" || merged.Status != "" || !reflect.DeepEqual(merged.Dependencies, []string{"task-2"}) {
+		t.Fatalf("unexpected merged task: %+v", merged)
+	}
+
+	if err := runPlanSubcommand(t, dir, "merge", "task-1", "task-2"); err != nil {
+		t.Fatalf("merging a dependency into its dependent failed: %v", err)
+	}
+	plan = loadPlanForTest(t, dir)
+	first, _ := findTask(plan, "task-1")
+	docs, _ := findTask(plan, "task-4")
+	if len(first.Dependencies) != 0 || !reflect.DeepEqual(docs.Dependencies, []string{"task-1"}) {
+		t.Fatalf("unexpected dependencies after merge: task-1=%v task-4=%v", first.Dependencies, docs.Dependencies)
+	}
+}
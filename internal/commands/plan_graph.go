@@ -0,0 +1,330 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// planGraphColors fills graph nodes by task status.
+var planGraphColors = map[string]string{
+	"pending":     "#eeeeee",
+	"in_progress": "#bbdefb",
+	"completed":   "#c8e6c9",
+	"blocked":     "#ffcdd2",
+	"cancelled":   "#f5f5f5",
+}
+
+const planGraphCriticalColor = "#d32f2f"
+
+// planGraph is the task dependency DAG. Edges run from a dependency to the task that
+// depends on it, so arrows follow the order work can happen in.
+type planGraph struct {
+	Nodes        []planGraphNode `json:"nodes"`
+	Edges        []planGraphEdge `json:"edges"`
+	CriticalPath []string        `json:"critical_path"`
+}
+
+type planGraphNode struct {
+	ID           string   `json:"id"`
+	Title        string   `json:"title"`
+	Status       string   `json:"status"`
+	Dependencies []string `json:"dependencies"`
+	// BlockedBy lists blocked tasks this task transitively depends on.
+	BlockedBy []string `json:"blocked_by,omitempty"`
+	Critical  bool     `json:"critical"`
+}
+
+type planGraphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Critical bool   `json:"critical"`
+}
+
+func planGraphCmd() *cobra.Command {
+	var (
+		dir           string
+		workspaceFlag string
+		format        string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Render the task dependency graph",
+		Long: `Renders the plan's task dependency graph as Graphviz DOT, Mermaid, or JSON.
+
+Nodes are coloured by status. The critical path is the longest chain of
+unfinished tasks and is highlighted; JSON output also lists, per task, any
+blocked tasks it transitively depends on. Plans with dangling or cyclic
+dependencies are rejected (see small lint).`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dir == "" {
+				dir = baseDir
+			}
+			artifactsDir := resolveArtifactsDir(dir)
+
+			scope, err := workspace.ParseScope(workspaceFlag)
+			if err != nil {
+				return err
+			}
+			if scope != workspace.ScopeAny {
+				if err := enforceWorkspaceScope(artifactsDir, scope); err != nil {
+					return err
+				}
+			}
+
+			plan, err := loadPlan(filepath.Join(artifactsDir, small.SmallDir, "plan.small.yml"))
+			if err != nil {
+				return fmt.Errorf("failed to load plan.small.yml: %w", err)
+			}
+			graph, err := buildPlanGraph(plan.Tasks)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			switch format {
+			case "dot":
+				writePlanGraphDOT(out, graph)
+			case "mermaid":
+				writePlanGraphMermaid(out, graph)
+			case "json":
+				data, err := json.MarshalIndent(graph, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(out, string(data))
+			default:
+				return fmt.Errorf("invalid --format %q (expected dot, mermaid, or json)", format)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "dot", "Output format (dot, mermaid, or json)")
+	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
+	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root, examples, or any)")
+
+	return cmd
+}
+
+func buildPlanGraph(tasks []PlanTask) (*planGraph, error) {
+	if err := validatePlanDependencyGraph(tasks); err != nil {
+		return nil, err
+	}
+	ids := planTaskIDs(tasks)
+	deps := planDependencyMap(tasks)
+	status := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		status[strings.TrimSpace(task.ID)] = normalizePlanStatus(task.Status)
+	}
+
+	critical := planCriticalPath(ids, deps, status)
+	onPath := map[string]int{}
+	for i, id := range critical {
+		onPath[id] = i + 1
+	}
+
+	graph := &planGraph{Nodes: []planGraphNode{}, Edges: []planGraphEdge{}, CriticalPath: critical}
+	for i, task := range tasks {
+		id := ids[i]
+		graph.Nodes = append(graph.Nodes, planGraphNode{
+			ID:           id,
+			Title:        strings.TrimSpace(task.Title),
+			Status:       status[id],
+			Dependencies: append([]string{}, deps[id]...),
+			BlockedBy:    planBlockedAncestors(id, deps, status),
+			Critical:     onPath[id] > 0,
+		})
+		for _, dep := range deps[id] {
+			graph.Edges = append(graph.Edges, planGraphEdge{
+				From:     dep,
+				To:       id,
+				Critical: onPath[dep] > 0 && onPath[id] == onPath[dep]+1,
+			})
+		}
+	}
+	return graph, nil
+}
+
+// validatePlanDependencyGraph reports the first dangling dependency, dependency cycle,
+// or broken parent or superseded_by link.
+func validatePlanDependencyGraph(tasks []PlanTask) error {
+	ids := planTaskIDs(tasks)
+	deps := planDependencyMap(tasks)
+	known := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		known[id] = true
+	}
+	for _, id := range ids {
+		for _, dep := range deps[id] {
+			if !known[dep] {
+				return fmt.Errorf("task %s depends on unknown task %q", id, dep)
+			}
+		}
+	}
+	if cycles := small.DependencyCycles(ids, deps); len(cycles) > 0 {
+		return fmt.Errorf("dependency cycle: %s", strings.Join(cycles[0], " -> "))
+	}
+	if err := validatePlanParents(tasks); err != nil {
+		return err
+	}
+	return validatePlanSupersession(tasks)
+}
+
+// planCriticalPath returns the longest dependency chain of unfinished (neither completed
+// nor cancelled) tasks, earliest first. Ties go to the chain ending earliest in plan
+// order. The graph must be acyclic.
+func planCriticalPath(ids []string, deps map[string][]string, status map[string]string) []string {
+	length := map[string]int{}
+	prev := map[string]string{}
+	var chain func(id string) int
+	chain = func(id string) int {
+		if n, ok := length[id]; ok {
+			return n
+		}
+		best := 0
+		for _, dep := range deps[id] {
+			if planStatusDone(status[dep]) {
+				continue
+			}
+			if n := chain(dep); n > best {
+				best = n
+				prev[id] = dep
+			}
+		}
+		length[id] = best + 1
+		return best + 1
+	}
+
+	end, longest := "", 0
+	for _, id := range ids {
+		if planStatusDone(status[id]) {
+			continue
+		}
+		if n := chain(id); n > longest {
+			end, longest = id, n
+		}
+	}
+	path := make([]string, longest)
+	for i := longest - 1; i >= 0; i-- {
+		path[i] = end
+		end = prev[end]
+	}
+	return path
+}
+
+// planBlockedAncestors returns the blocked tasks id transitively depends on, in the order found.
+func planBlockedAncestors(id string, deps map[string][]string, status map[string]string) []string {
+	var blocked []string
+	seen := map[string]bool{}
+	var walk func(id string)
+	walk = func(id string) {
+		for _, dep := range deps[id] {
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			if status[dep] == "blocked" {
+				blocked = append(blocked, dep)
+			}
+			walk(dep)
+		}
+	}
+	walk(id)
+	return blocked
+}
+
+func planGraphColor(status string) string {
+	if color, ok := planGraphColors[status]; ok {
+		return color
+	}
+	return planGraphColors["pending"]
+}
+
+func writePlanGraphDOT(w io.Writer, graph *planGraph) {
+	fmt.Fprintln(w, "digraph plan {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, `  node [shape=box, style="rounded,filled", fontname="Helvetica"];`)
+	for _, node := range graph.Nodes {
+		attrs := fmt.Sprintf(`label=%s, fillcolor="%s"`, dotQuote(node.ID+"\n"+node.Title+"\n["+node.Status+"]"), planGraphColor(node.Status))
+		if node.Critical {
+			attrs += fmt.Sprintf(`, color="%s", penwidth=2.5`, planGraphCriticalColor)
+		}
+		fmt.Fprintf(w, "  %s [%s];\n", dotQuote(node.ID), attrs)
+	}
+	for _, edge := range graph.Edges {
+		attrs := ""
+		if edge.Critical {
+			attrs = fmt.Sprintf(` [color="%s", penwidth=2.5]`, planGraphCriticalColor)
+		}
+		fmt.Fprintf(w, "  %s -> %s%s;\n", dotQuote(edge.From), dotQuote(edge.To), attrs)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func dotQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + strings.ReplaceAll(value, "\n", `\n`) + `"`
+}
+
+// writePlanGraphMermaid writes a flowchart. Task ids are not valid Mermaid node ids in
+// general, so nodes are numbered by plan order and labelled with the task id.
+func writePlanGraphMermaid(w io.Writer, graph *planGraph) {
+	nodeIDs := make(map[string]string, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		nodeIDs[node.ID] = fmt.Sprintf("t%d", i+1)
+	}
+
+	fmt.Fprintln(w, "flowchart LR")
+	for _, node := range graph.Nodes {
+		label := mermaidEscape(node.ID + ": " + node.Title)
+		fmt.Fprintf(w, "  %s[\"%s<br/>[%s]\"]:::%s\n", nodeIDs[node.ID], label, node.Status, mermaidClass(node.Status))
+	}
+	var critical []int
+	for i, edge := range graph.Edges {
+		fmt.Fprintf(w, "  %s --> %s\n", nodeIDs[edge.From], nodeIDs[edge.To])
+		if edge.Critical {
+			critical = append(critical, i)
+		}
+	}
+	for _, status := range []string{"pending", "in_progress", "completed", "blocked", "cancelled"} {
+		fmt.Fprintf(w, "  classDef %s fill:%s\n", mermaidClass(status), planGraphColor(status))
+	}
+	var criticalNodes []string
+	for _, node := range graph.Nodes {
+		if node.Critical {
+			criticalNodes = append(criticalNodes, nodeIDs[node.ID])
+		}
+	}
+	if len(criticalNodes) > 0 {
+		fmt.Fprintf(w, "  classDef critical stroke:%s,stroke-width:3px\n", planGraphCriticalColor)
+		fmt.Fprintf(w, "  class %s critical\n", strings.Join(criticalNodes, ","))
+	}
+	if len(critical) > 0 {
+		indexes := make([]string, len(critical))
+		for i, index := range critical {
+			indexes[i] = fmt.Sprint(index)
+		}
+		fmt.Fprintf(w, "  linkStyle %s stroke:%s,stroke-width:3px\n", strings.Join(indexes, ","), planGraphCriticalColor)
+	}
+}
+
+func mermaidClass(status string) string {
+	if _, ok := planGraphColors[status]; !ok {
+		status = "pending"
+	}
+	return "status_" + status
+}
+
+func mermaidEscape(value string) string {
+	return strings.ReplaceAll(value, `"`, "#quot;")
+}
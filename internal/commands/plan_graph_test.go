@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func graphTestTasks() []PlanTask {
+	return []PlanTask{
+		{ID: "task-1", Title: "Schema", Status: "completed"},
+		{ID: "task-2", Title: "API", Status: "blocked", Dependencies: []string{"task-1"}},
+		{ID: "task-3", Title: "Docs", Status: "pending", Dependencies: []string{"task-1"}},
+		{ID: "task-4", Title: "Client \"v2\"", Status: "pending", Dependencies: []string{"task-2"}},
+		{ID: "task-5", Title: "Release", Status: "pending", Dependencies: []string{"task-3", "task-4"}},
+	}
+}
+
+func TestBuildPlanGraph(t *testing.T) {
+	graph, err := buildPlanGraph(graphTestTasks())
+	if err != nil {
+		t.Fatalf("buildPlanGraph failed: %v", err)
+	}
+	if want := []string{"task-2", "task-4", "task-5"}; !reflect.DeepEqual(graph.CriticalPath, want) {
+		t.Fatalf("critical path = %v, want %v", graph.CriticalPath, want)
+	}
+	if len(graph.Edges) != 5 {
+		t.Fatalf("expected 5 edges, got %d", len(graph.Edges))
+	}
+	for _, edge := range graph.Edges {
+		want := (edge.From == "task-2" && edge.To == "task-4") || (edge.From == "task-4" && edge.To == "task-5")
+		if edge.Critical != want {
+			t.Errorf("edge %s -> %s critical = %v, want %v", edge.From, edge.To, edge.Critical, want)
+		}
+	}
+	release := graph.Nodes[4]
+	if !reflect.DeepEqual(release.BlockedBy, []string{"task-2"}) || !release.Critical {
+		t.Fatalf("unexpected release node: %+v", release)
+	}
+	if graph.Nodes[2].BlockedBy != nil || graph.Nodes[0].Critical {
+		t.Fatalf("unexpected nodes: %+v", graph.Nodes)
+	}
+
+	cyclic := graphTestTasks()
+	cyclic[0].Dependencies = []string{"task-5"}
+	if _, err := buildPlanGraph(cyclic); err == nil || !strings.Contains(err.Error(), "dependency cycle") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+	dangling := graphTestTasks()
+	dangling[2].Dependencies = []string{"task-9"}
+	if _, err := buildPlanGraph(dangling); err == nil || !strings.Contains(err.Error(), "unknown task") {
+		t.Fatalf("expected dangling dependency error, got %v", err)
+	}
+}
+
+func TestPlanGraphFormats(t *testing.T) {
+	graph, err := buildPlanGraph(graphTestTasks())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dot bytes.Buffer
+	writePlanGraphDOT(&dot, graph)
+	for _, want := range []string{
+		`"task-1" [label="task-1\nSchema\n[completed]", fillcolor="#c8e6c9"];`,
+		`"task-4" [label="task-4\nClient \"v2\"\n[pending]", fillcolor="#eeeeee", color="#d32f2f", penwidth=2.5];`,
+		`"task-2" -> "task-4" [color="#d32f2f", penwidth=2.5];`,
+		`"task-1" -> "task-3";`,
+	} {
+		if !strings.Contains(dot.String(), want) {
+			t.Fatalf("DOT output missing %q:\n%s", want, dot.String())
+		}
+	}
+
+	var mermaid bytes.Buffer
+	writePlanGraphMermaid(&mermaid, graph)
+	for _, want := range []string{
+		"flowchart LR\n",
+		`t4["task-4: Client #quot;v2#quot;<br/>[pending]"]:::status_pending`,
+		"t2 --> t4\n",
+		"class t2,t4,t5 critical\n",
+		"linkStyle 2,4 stroke:#d32f2f,stroke-width:3px\n",
+	} {
+		if !strings.Contains(mermaid.String(), want) {
+			t.Fatalf("Mermaid output missing %q:\n%s", want, mermaid.String())
+		}
+	}
+}
+
+func TestPlanGraphCommandJSON(t *testing.T) {
+	tmpDir := t.TempDir()
+	artifacts := defaultArtifacts()
+	artifacts["plan.small.yml"] = `small_version: "1.0.0"
+owner: "agent"
+tasks:
+  - id: "task-1"
+    title: "First"
+    status: "completed"
+  - id: "task-2"
+    title: "Second"
+    dependencies: ["task-1"]
+`
+	writeArtifacts(t, tmpDir, artifacts)
+	mustSaveWorkspace(t, tmpDir, workspace.KindRepoRoot)
+
+	var out bytes.Buffer
+	cmd := planCmd()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"graph", "--dir", tmpDir, "--format", "json"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("plan graph failed: %v", err)
+	}
+	var graph planGraph
+	if err := json.Unmarshal(out.Bytes(), &graph); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(graph.Nodes) != 2 || graph.Nodes[1].Status != "pending" || !reflect.DeepEqual(graph.CriticalPath, []string{"task-2"}) {
+		t.Fatalf("unexpected graph: %+v", graph)
+	}
+}
@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+)
+
+// planChildren maps each parent task id to its subtasks in plan order.
+func planChildren(tasks []PlanTask) map[string][]string {
+	children := map[string][]string{}
+	for _, task := range tasks {
+		if parent := strings.TrimSpace(task.Parent); parent != "" {
+			children[parent] = append(children[parent], strings.TrimSpace(task.ID))
+		}
+	}
+	return children
+}
+
+// validatePlanParents reports the first parent link to an unknown task or parent cycle.
+func validatePlanParents(tasks []PlanTask) error {
+	ids := planTaskIDs(tasks)
+	known := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		known[id] = true
+	}
+	links := map[string][]string{}
+	for i, task := range tasks {
+		parent := strings.TrimSpace(task.Parent)
+		if parent == "" {
+			continue
+		}
+		if !known[parent] {
+			return fmt.Errorf("task %s has unknown parent %q", ids[i], parent)
+		}
+		links[ids[i]] = []string{parent}
+	}
+	if cycles := small.DependencyCycles(ids, links); len(cycles) > 0 {
+		return fmt.Errorf("parent cycle: %s", strings.Join(cycles[0], " -> "))
+	}
+	return nil
+}
+
+// rollUpPlanStatuses derives every parent's status from its subtasks: completed once all
+// are completed, blocked if any is blocked, in_progress once any has started, and pending
+// otherwise. Cancelled subtasks count as done, so a parent whose subtasks are all
+// cancelled is cancelled too. Parent cycles are left untouched.
+func rollUpPlanStatuses(tasks []PlanTask) {
+	children := planChildren(tasks)
+	if len(children) == 0 {
+		return
+	}
+	index := make(map[string]int, len(tasks))
+	for i := range tasks {
+		index[strings.TrimSpace(tasks[i].ID)] = i
+	}
+
+	visiting := map[string]bool{}
+	var status func(id string) string
+	status = func(id string) string {
+		i, ok := index[id]
+		if !ok {
+			return "pending"
+		}
+		kids := children[id]
+		if len(kids) == 0 || visiting[id] {
+			return normalizePlanStatus(tasks[i].Status)
+		}
+		visiting[id] = true
+		defer delete(visiting, id)
+
+		counts := map[string]int{}
+		for _, kid := range kids {
+			counts[status(kid)]++
+		}
+		derived := "pending"
+		switch {
+		case counts["cancelled"] == len(kids):
+			derived = "cancelled"
+		case counts["completed"]+counts["cancelled"] == len(kids):
+			derived = "completed"
+		case counts["blocked"] > 0:
+			derived = "blocked"
+		case counts["in_progress"] > 0 || counts["completed"] > 0:
+			derived = "in_progress"
+		}
+		tasks[i].Status = derived
+		return derived
+	}
+	for id := range children {
+		status(id)
+	}
+}
+
+// planEffectiveDependencies returns each task's dependencies together with those of its
+// ancestors, since a subtask cannot start before its parent could.
+func planEffectiveDependencies(tasks []PlanTask) map[string][]string {
+	deps := planDependencyMap(tasks)
+	parents := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		parents[strings.TrimSpace(task.ID)] = strings.TrimSpace(task.Parent)
+	}
+	effective := make(map[string][]string, len(tasks))
+	for _, id := range planTaskIDs(tasks) {
+		all := append([]string{}, deps[id]...)
+		seen := map[string]bool{id: true}
+		for parent := parents[id]; parent != "" && !seen[parent]; parent = parents[parent] {
+			seen[parent] = true
+			all = appendMissingDependencies(all, deps[parent]...)
+		}
+		effective[id] = all
+	}
+	return effective
+}
+
+// planTaskMilestones returns each task's milestone; subtasks without one inherit the
+// nearest ancestor's.
+func planTaskMilestones(tasks []PlanTask) map[string]string {
+	byID := make(map[string]PlanTask, len(tasks))
+	for _, task := range tasks {
+		byID[strings.TrimSpace(task.ID)] = task
+	}
+	milestones := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		id := strings.TrimSpace(task.ID)
+		seen := map[string]bool{}
+		for current, ok := task, true; ok && !seen[strings.TrimSpace(current.ID)]; current, ok = byID[strings.TrimSpace(current.Parent)] {
+			seen[strings.TrimSpace(current.ID)] = true
+			if milestone := strings.TrimSpace(current.Milestone); milestone != "" {
+				milestones[id] = milestone
+				break
+			}
+		}
+	}
+	return milestones
+}
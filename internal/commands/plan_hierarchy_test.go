@@ -0,0 +1,183 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func TestRollUpPlanStatuses(t *testing.T) {
+	tasks := []PlanTask{
+		{ID: "epic"},
+		{ID: "feature", Parent: "epic"},
+		{ID: "a", Parent: "feature", Status: "completed"},
+		{ID: "b", Parent: "feature", Status: "pending"},
+		{ID: "docs", Parent: "epic", Status: "completed"},
+		{ID: "done", Status: "pending"},
+		{ID: "c", Parent: "done", Status: "completed"},
+		{ID: "d", Parent: "done", Status: "cancelled"},
+		{ID: "stuck"},
+		{ID: "e", Parent: "stuck", Status: "blocked"},
+		{ID: "f", Parent: "stuck", Status: "in_progress"},
+	}
+	rollUpPlanStatuses(tasks)
+
+	got := map[string]string{}
+	for _, task := range tasks {
+		got[task.ID] = task.Status
+	}
+	want := map[string]string{"epic": "in_progress", "feature": "in_progress", "done": "completed", "stuck": "blocked"}
+	for id, status := range want {
+		if got[id] != status {
+			t.Errorf("%s status = %q, want %q", id, got[id], status)
+		}
+	}
+
+	tasks[3].Status = "completed"
+	rollUpPlanStatuses(tasks)
+	if tasks[0].Status != "completed" || tasks[1].Status != "completed" {
+		t.Fatalf("expected epic and feature completed, got %q and %q", tasks[0].Status, tasks[1].Status)
+	}
+}
+
+func TestNextActionableTaskIDsWithSubtasks(t *testing.T) {
+	tasks := []PlanTask{
+		{ID: "setup", Status: "pending"},
+		{ID: "epic", Dependencies: []string{"setup"}},
+		{ID: "a", Parent: "epic", Status: "pending"},
+		{ID: "b", Parent: "epic", Status: "pending", Dependencies: []string{"a"}},
+		{ID: "release", Status: "pending", Dependencies: []string{"epic"}},
+	}
+	if got := nextActionableTaskIDs(tasks, 0); !reflect.DeepEqual(got, []string{"setup"}) {
+		t.Fatalf("subtasks should wait on the parent's dependencies, got %v", got)
+	}
+
+	tasks[0].Status = "completed"
+	if got := nextActionableTaskIDs(tasks, 0); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("expected only leaf a to be actionable, got %v", got)
+	}
+
+	tasks[2].Status = "completed"
+	tasks[3].Status = "completed"
+	if got := nextActionableTaskIDs(tasks, 0); !reflect.DeepEqual(got, []string{"release"}) {
+		t.Fatalf("expected release once the epic rolls up to completed, got %v", got)
+	}
+}
+
+func TestMilestoneStatuses(t *testing.T) {
+	tasks := []PlanTask{
+		{ID: "epic", Milestone: "beta"},
+		{ID: "a", Parent: "epic", Status: "completed"},
+		{ID: "b", Parent: "epic", Status: "pending"},
+		{ID: "c", Parent: "epic", Status: "cancelled"},
+		{ID: "d", Milestone: "ga", Status: "pending"},
+		{ID: "e", Parent: "epic", Milestone: "ga", Status: "completed"},
+		{ID: "loose", Status: "completed"},
+	}
+	got := milestoneStatuses(tasks)
+	want := []MilestoneStatus{
+		{Name: "beta", Total: 2, Completed: 1},
+		{Name: "ga", Total: 2, Completed: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("milestoneStatuses() = %+v, want %+v", got, want)
+	}
+
+	if bar := milestoneProgressBar(MilestoneStatus{Name: "beta", Total: 5, Completed: 2}); bar != "[########------------] 2/5 (40%)" {
+		t.Fatalf("unexpected progress bar %q", bar)
+	}
+}
+
+func TestPlanAddSubtaskRollsUpParent(t *testing.T) {
+	dir := setupPlanEditWorkspace(t)
+	if err := runPlanSubcommand(t, dir, "--add", "API tests", "--parent", "task-2", "--milestone", "beta"); err != nil {
+		t.Fatalf("plan --add --parent failed: %v", err)
+	}
+	plan := loadPlanForTest(t, dir)
+	sub, _ := findTask(plan, "task-5")
+	if sub == nil || sub.Parent != "task-2" || sub.Milestone != "beta" {
+		t.Fatalf("unexpected subtask: %+v", sub)
+	}
+
+	if err := runPlanSubcommand(t, dir, "--done", "task-2"); err == nil || !strings.Contains(err.Error(), "derived") {
+		t.Fatalf("expected parent status to be derived, got %v", err)
+	}
+	if err := runPlanSubcommand(t, dir, "--done", "task-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runPlanSubcommand(t, dir, "--done", "task-5"); err != nil {
+		t.Fatal(err)
+	}
+	parent, _ := findTask(loadPlanForTest(t, dir), "task-2")
+	if parent.Status != "completed" {
+		t.Fatalf("parent status = %q, want completed", parent.Status)
+	}
+
+	if err := runPlanSubcommand(t, dir, "--add", "Orphan", "--parent", "task-9"); err == nil {
+		t.Fatal("expected unknown parent to be rejected")
+	}
+}
+
+func TestCheckpointCompletesSiblingOfCancelledSubtask(t *testing.T) {
+	dir := t.TempDir()
+	artifacts := defaultArtifacts()
+	artifacts["plan.small.yml"] = `small_version: "1.0.0"
+owner: "agent"
+tasks:
+  - id: "task-1"
+    title: "Schema"
+    status: "completed"
+  - id: "task-2"
+    title: "API"
+  - id: "task-3"
+    title: "Endpoints"
+    parent: "task-2"
+  - id: "task-4"
+    title: "Webhooks"
+    parent: "task-2"
+`
+	writeArtifacts(t, dir, artifacts)
+	mustSaveWorkspace(t, dir, workspace.KindRepoRoot)
+
+	if err := runPlanSubcommand(t, dir, "--cancel", "task-4", "--reason", "Out of scope"); err != nil {
+		t.Fatalf("plan --cancel failed: %v", err)
+	}
+	cmd := checkpointCmd()
+	cmd.SetArgs([]string{"--dir", dir, "--task", "task-3", "--status", "completed", "--evidence", "done"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("checkpoint of the remaining subtask failed: %v", err)
+	}
+	parent, _ := findTask(loadPlanForTest(t, dir), "task-2")
+	if parent.Status != "completed" {
+		t.Fatalf("parent status = %q, want completed", parent.Status)
+	}
+}
+
+func TestPlanRmReparentsSubtasks(t *testing.T) {
+	dir := t.TempDir()
+	artifacts := defaultArtifacts()
+	artifacts["plan.small.yml"] = `small_version: "1.0.0"
+owner: "agent"
+tasks:
+  - id: "task-1"
+    title: "Epic"
+  - id: "task-2"
+    title: "Group"
+    parent: "task-1"
+  - id: "task-3"
+    title: "Leaf"
+    parent: "task-2"
+`
+	writeArtifacts(t, dir, artifacts)
+	mustSaveWorkspace(t, dir, workspace.KindRepoRoot)
+
+	if err := runPlanSubcommand(t, dir, "rm", "task-2"); err != nil {
+		t.Fatalf("plan rm failed: %v", err)
+	}
+	leaf, _ := findTask(loadPlanForTest(t, dir), "task-3")
+	if leaf == nil || leaf.Parent != "task-1" {
+		t.Fatalf("expected task-3 to move under task-1, got %+v", leaf)
+	}
+}
@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/spf13/cobra"
+)
+
+const defaultPlanLeaseTTL = 30 * time.Minute
+
+// PlanTaskLease records which agent is working on a task and until when. An expired
+// lease no longer reserves the task; it stays in the plan until it is released or
+// another agent claims the task.
+type PlanTaskLease struct {
+	AgentID   string `yaml:"agent_id"`
+	ClaimedAt string `yaml:"claimed_at,omitempty"`
+	Expires   string `yaml:"expires"`
+}
+
+// expiresAt parses the expiry; a lease with an unreadable expiry counts as expired.
+func (l *PlanTaskLease) expiresAt() (time.Time, bool) {
+	if l == nil {
+		return time.Time{}, false
+	}
+	expires, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(l.Expires))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expires, true
+}
+
+// live reports whether the lease still reserves its task at now.
+func (l *PlanTaskLease) live(now time.Time) bool {
+	expires, ok := l.expiresAt()
+	return ok && now.Before(expires)
+}
+
+// heldByOther reports whether a live lease reserves the task for an agent other than
+// agent. An empty agent is never the holder.
+func (l *PlanTaskLease) heldByOther(agent string, now time.Time) bool {
+	return l.live(now) && strings.TrimSpace(l.AgentID) != strings.TrimSpace(agent)
+}
+
+// ensureTaskLeaseHolder refuses writes to a task while another agent's lease is live.
+func ensureTaskLeaseHolder(plan *PlanData, taskID, agent string, now time.Time) error {
+	task, _ := findTask(plan, taskID)
+	if task == nil || !task.Lease.heldByOther(agent, now) {
+		return nil
+	}
+	hint := "pass --agent " + task.Lease.AgentID + " if you hold it"
+	if strings.TrimSpace(agent) != "" {
+		hint = "wait for it to expire or ask the holder to release it"
+	}
+	return fmt.Errorf("task %s is leased to %s until %s; %s", taskID, task.Lease.AgentID, task.Lease.Expires, hint)
+}
+
+// ensureTaskLeaseAvailable checks the lease on taskID in the workspace plan, if any.
+func ensureTaskLeaseAvailable(baseDir, taskID, agent string) error {
+	if !small.ArtifactExists(baseDir, "plan.small.yml") {
+		return nil
+	}
+	plan, err := loadPlan(filepath.Join(baseDir, small.SmallDir, "plan.small.yml"))
+	if err != nil {
+		return fmt.Errorf("failed to load plan.small.yml: %w", err)
+	}
+	return ensureTaskLeaseHolder(plan, taskID, agent, time.Now())
+}
+
+// planLeaseFlags are the flags shared by claim, renew, and release.
+type planLeaseFlags struct {
+	planEditFlags
+}
+
+func (f *planLeaseFlags) register(cmd *cobra.Command) {
+	f.planEditFlags.register(cmd)
+}
+
+func (f *planLeaseFlags) agentID() (string, error) {
+	agent := currentAgentID()
+	if agent == "" {
+		return "", fmt.Errorf("--agent (or %s) is required", agentIDEnvVar)
+	}
+	return agent, nil
+}
+
+func newPlanTaskLease(agent string, now time.Time, ttl time.Duration) *PlanTaskLease {
+	return &PlanTaskLease{
+		AgentID:   agent,
+		ClaimedAt: now.UTC().Format(time.RFC3339),
+		Expires:   now.Add(ttl).UTC().Format(time.RFC3339),
+	}
+}
+
+func planClaimCmd() *cobra.Command {
+	var (
+		flags planLeaseFlags
+		ttl   time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "claim <task-id>",
+		Short: "Lease a task to an agent",
+		Long: `Leases a task to --agent for --ttl. While the lease is live, the task is left
+out of other agents' next actionable tasks, and small checkpoint and small apply
+--task refuse to record it for anyone else. Claiming a task whose lease has
+expired takes it over; claiming your own task again renews it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID := strings.TrimSpace(args[0])
+			agent, err := flags.agentID()
+			if err != nil {
+				return err
+			}
+			if ttl <= 0 {
+				return fmt.Errorf("--ttl must be positive")
+			}
+			return flags.mutatePlan(func(_ string, plan *PlanData) ([]planProgressRecord, error) {
+				task, _ := findTask(plan, taskID)
+				if task == nil {
+					return nil, fmt.Errorf("task %s not found", taskID)
+				}
+				if len(planChildren(plan.Tasks)[taskID]) > 0 {
+					return nil, fmt.Errorf("task %s has subtasks; claim a subtask instead", taskID)
+				}
+				if planStatusDone(task.Status) {
+					return nil, fmt.Errorf("task %s is %s", taskID, normalizePlanStatus(task.Status))
+				}
+				now := time.Now()
+				if err := ensureTaskLeaseHolder(plan, taskID, agent, now); err != nil {
+					return nil, err
+				}
+				evidence := fmt.Sprintf("Claimed by %s", agent)
+				if task.Lease != nil && task.Lease.AgentID != agent {
+					evidence = fmt.Sprintf("Claimed by %s (expired lease held by %s)", agent, task.Lease.AgentID)
+				}
+				task.Lease = newPlanTaskLease(agent, now, ttl)
+				fmt.Printf("Task %s leased to %s until %s\n", taskID, agent, task.Lease.Expires)
+				return []planProgressRecord{{
+					TaskID:   taskID,
+					Status:   task.Status,
+					Evidence: fmt.Sprintf("%s until %s", evidence, task.Lease.Expires),
+					Notes:    "small plan claim",
+				}}, nil
+			})
+		},
+	}
+
+	cmd.Flags().DurationVar(&ttl, "ttl", defaultPlanLeaseTTL, "How long the lease lasts")
+	flags.register(cmd)
+
+	return cmd
+}
+
+func planRenewCmd() *cobra.Command {
+	var (
+		flags planLeaseFlags
+		ttl   time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "renew <task-id>",
+		Short: "Extend an agent's task lease",
+		Long: `Extends the lease held by --agent to --ttl from now. A holder may renew an
+expired lease as long as no other agent has claimed the task since.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID := strings.TrimSpace(args[0])
+			agent, err := flags.agentID()
+			if err != nil {
+				return err
+			}
+			if ttl <= 0 {
+				return fmt.Errorf("--ttl must be positive")
+			}
+			return flags.mutatePlan(func(_ string, plan *PlanData) ([]planProgressRecord, error) {
+				task, _ := findTask(plan, taskID)
+				if task == nil {
+					return nil, fmt.Errorf("task %s not found", taskID)
+				}
+				if task.Lease == nil {
+					return nil, fmt.Errorf("task %s is not leased; use small plan claim", taskID)
+				}
+				if task.Lease.AgentID != agent {
+					return nil, fmt.Errorf("task %s is leased to %s, not %s", taskID, task.Lease.AgentID, agent)
+				}
+				task.Lease.Expires = time.Now().Add(ttl).UTC().Format(time.RFC3339)
+				fmt.Printf("Task %s lease renewed for %s until %s\n", taskID, agent, task.Lease.Expires)
+				return []planProgressRecord{{
+					TaskID:   taskID,
+					Status:   task.Status,
+					Evidence: fmt.Sprintf("Lease renewed by %s until %s", agent, task.Lease.Expires),
+					Notes:    "small plan renew",
+				}}, nil
+			})
+		},
+	}
+
+	cmd.Flags().DurationVar(&ttl, "ttl", defaultPlanLeaseTTL, "New lease duration, counted from now")
+	flags.register(cmd)
+
+	return cmd
+}
+
+func planReleaseCmd() *cobra.Command {
+	var (
+		flags planLeaseFlags
+		force bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "release <task-id>",
+		Short: "Release a task lease",
+		Long: `Removes the lease held by --agent. --force releases a lease held by another
+agent, for example one left behind by an agent that crashed.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			taskID := strings.TrimSpace(args[0])
+			agent, err := flags.agentID()
+			if err != nil {
+				return err
+			}
+			return flags.mutatePlan(func(_ string, plan *PlanData) ([]planProgressRecord, error) {
+				task, _ := findTask(plan, taskID)
+				if task == nil {
+					return nil, fmt.Errorf("task %s not found", taskID)
+				}
+				if task.Lease == nil {
+					return nil, fmt.Errorf("task %s is not leased", taskID)
+				}
+				holder := task.Lease.AgentID
+				if holder != agent && !force {
+					return nil, fmt.Errorf("task %s is leased to %s, not %s (use --force to release it anyway)", taskID, holder, agent)
+				}
+				task.Lease = nil
+				evidence := fmt.Sprintf("Lease released by %s", agent)
+				if holder != agent {
+					evidence = fmt.Sprintf("Lease held by %s released by %s (--force)", holder, agent)
+				}
+				fmt.Printf("Task %s lease released\n", taskID)
+				return []planProgressRecord{{
+					TaskID:   taskID,
+					Status:   task.Status,
+					Evidence: evidence,
+					Notes:    "small plan release",
+				}}, nil
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Release a lease held by another agent")
+	flags.register(cmd)
+
+	return cmd
+}
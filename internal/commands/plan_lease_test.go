@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNextActionableTaskIDsSkipsOtherAgentsLeases(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	tasks := []PlanTask{
+		{ID: "task-1", Lease: newPlanTaskLease("agent-a", now, time.Hour)},
+		{ID: "task-2", Lease: newPlanTaskLease("agent-b", now.Add(-2*time.Hour), time.Hour)},
+		{ID: "task-3"},
+	}
+	if got := nextActionableTaskIDsForAgent(tasks, 0, "agent-a", now); !reflect.DeepEqual(got, []string{"task-1", "task-2", "task-3"}) {
+		t.Fatalf("holder should see its own and expired leases, got %v", got)
+	}
+	if got := nextActionableTaskIDsForAgent(tasks, 0, "agent-b", now); !reflect.DeepEqual(got, []string{"task-2", "task-3"}) {
+		t.Fatalf("expected task-1 to be skipped for agent-b, got %v", got)
+	}
+}
+
+// runPlanSubcommandAs runs a plan subcommand with the global --agent set to agent.
+func runPlanSubcommandAs(t *testing.T, dir, agent string, args ...string) error {
+	t.Helper()
+	setAgentFlag(t, agent)
+	return runPlanSubcommand(t, dir, args...)
+}
+
+func TestPlanClaimRenewRelease(t *testing.T) {
+	setAgentFlag(t, "")
+	dir := setupPlanEditWorkspace(t)
+
+	if err := runPlanSubcommand(t, dir, "claim", "task-1"); err == nil || !strings.Contains(err.Error(), "--agent") {
+		t.Fatalf("expected claim without --agent to fail, got %v", err)
+	}
+	if err := runPlanSubcommandAs(t, dir, "agent-a", "claim", "task-1", "--ttl", "10m"); err != nil {
+		t.Fatalf("plan claim failed: %v", err)
+	}
+	task, _ := findTask(loadPlanForTest(t, dir), "task-1")
+	if task.Lease == nil || task.Lease.AgentID != "agent-a" || !task.Lease.live(time.Now()) {
+		t.Fatalf("expected a live lease for agent-a, got %+v", task.Lease)
+	}
+	first := task.Lease.Expires
+
+	if err := runPlanSubcommandAs(t, dir, "agent-b", "claim", "task-1"); err == nil || !strings.Contains(err.Error(), "leased to agent-a") {
+		t.Fatalf("expected claim by another agent to fail, got %v", err)
+	}
+	if err := runPlanSubcommandAs(t, dir, "agent-b", "renew", "task-1"); err == nil {
+		t.Fatal("expected renew by a non-holder to fail")
+	}
+	if err := runPlanSubcommandAs(t, dir, "agent-a", "renew", "task-1", "--ttl", "2h"); err != nil {
+		t.Fatalf("plan renew failed: %v", err)
+	}
+	task, _ = findTask(loadPlanForTest(t, dir), "task-1")
+	if task.Lease.Expires <= first {
+		t.Fatalf("expected renew to extend the lease past %s, got %s", first, task.Lease.Expires)
+	}
+
+	if err := runPlanSubcommandAs(t, dir, "agent-b", "release", "task-1"); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("expected release by a non-holder to fail, got %v", err)
+	}
+	if err := runPlanSubcommandAs(t, dir, "agent-b", "release", "task-1", "--force"); err != nil {
+		t.Fatalf("plan release --force failed: %v", err)
+	}
+	task, _ = findTask(loadPlanForTest(t, dir), "task-1")
+	if task.Lease != nil {
+		t.Fatalf("expected the lease to be removed, got %+v", task.Lease)
+	}
+
+	if err := runPlanSubcommandAs(t, dir, "agent-a", "claim", "task-4"); err == nil {
+		t.Fatal("expected claiming a completed task to fail")
+	}
+}
+
+func TestCheckpointRejectsNonHolder(t *testing.T) {
+	setAgentFlag(t, "")
+	dir := setupPlanEditWorkspace(t)
+	if err := runPlanSubcommandAs(t, dir, "agent-a", "claim", "task-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, agent := range []string{"", "agent-b"} {
+		setAgentFlag(t, agent)
+		cmd := checkpointCmd()
+		cmd.SetArgs([]string{"--dir", dir, "--task", "task-1", "--status", "completed", "--evidence", "done"})
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "leased to agent-a") {
+			t.Fatalf("expected checkpoint by %q to be refused, got %v", agent, err)
+		}
+	}
+
+	setAgentFlag(t, "agent-a")
+	cmd := checkpointCmd()
+	cmd.SetArgs([]string{"--dir", dir, "--task", "task-1", "--status", "completed", "--evidence", "done"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("expected holder checkpoint to succeed, got %v", err)
+	}
+	task, _ := findTask(loadPlanForTest(t, dir), "task-1")
+	if task.Status != "completed" || task.Lease != nil {
+		t.Fatalf("expected completed task with its lease cleared, got %+v", task)
+	}
+}
+
+func TestApplyTaskRejectsNonHolder(t *testing.T) {
+	setAgentFlag(t, "")
+	dir := setupPlanEditWorkspace(t)
+	if err := runPlanSubcommandAs(t, dir, "agent-a", "claim", "task-1"); err != nil {
+		t.Fatal(err)
+	}
+	setAgentFlag(t, "agent-b")
+	cmd := applyCmd()
+	cmd.SetArgs([]string{"--dir", dir, "--task", "task-1", "--cmd", "true"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "leased to agent-a") {
+		t.Fatalf("expected apply by a non-holder to be refused, got %v", err)
+	}
+}
+
+func TestDoctorReportsExpiredLeases(t *testing.T) {
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	tasks := []any{
+		map[string]any{"id": "task-1", "lease": map[string]any{"agent_id": "agent-a", "expires": "2026-01-02T09:00:00Z"}},
+		map[string]any{"id": "task-2", "lease": map[string]any{"agent_id": "agent-b", "expires": now.Add(time.Hour)}},
+		map[string]any{"id": "task-3"},
+	}
+	results := analyzePlanLeases(tasks, now)
+	if len(results) != 1 || results[0].Status != "warning" || !strings.Contains(results[0].Message, "task-1") {
+		t.Fatalf("expected one expired lease warning for task-1, got %+v", results)
+	}
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+)
+
+// planStatusDone reports whether a task no longer holds up its dependents. Cancelled
+// tasks are terminal like completed ones.
+func planStatusDone(status string) bool {
+	status = normalizePlanStatus(status)
+	return status == "completed" || status == "cancelled"
+}
+
+// planDependencyState answers whether dependencies are satisfied. A dependency on a
+// superseded task is satisfied by its replacement, following superseded_by links.
+type planDependencyState struct {
+	status       map[string]string
+	supersededBy map[string]string
+}
+
+func newPlanDependencyState(tasks []PlanTask) *planDependencyState {
+	state := &planDependencyState{
+		status:       make(map[string]string, len(tasks)),
+		supersededBy: map[string]string{},
+	}
+	for _, task := range tasks {
+		id := strings.TrimSpace(task.ID)
+		state.status[id] = normalizePlanStatus(task.Status)
+		if by := strings.TrimSpace(task.SupersededBy); by != "" {
+			state.supersededBy[id] = by
+		}
+	}
+	return state
+}
+
+// resolve returns the task that stands in for id: id itself, or the last task along
+// its superseded_by chain.
+func (s *planDependencyState) resolve(id string) string {
+	seen := map[string]bool{}
+	for !seen[id] {
+		seen[id] = true
+		next, ok := s.supersededBy[id]
+		if !ok {
+			break
+		}
+		id = next
+	}
+	return id
+}
+
+func (s *planDependencyState) satisfied(dep string) bool {
+	status, ok := s.status[s.resolve(strings.TrimSpace(dep))]
+	return ok && planStatusDone(status)
+}
+
+// unsatisfied returns the dependencies that still hold a task up.
+func (s *planDependencyState) unsatisfied(deps []string) []string {
+	var waiting []string
+	for _, dep := range deps {
+		if !s.satisfied(dep) {
+			waiting = append(waiting, strings.TrimSpace(dep))
+		}
+	}
+	return waiting
+}
+
+// validatePlanSupersession reports the first superseded_by link to an unknown task,
+// on a task that is not cancelled, or around a cycle.
+func validatePlanSupersession(tasks []PlanTask) error {
+	ids := planTaskIDs(tasks)
+	known := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		known[id] = true
+	}
+	links := map[string][]string{}
+	for i, task := range tasks {
+		by := strings.TrimSpace(task.SupersededBy)
+		if by == "" {
+			continue
+		}
+		if !known[by] {
+			return fmt.Errorf("task %s is superseded by unknown task %q", ids[i], by)
+		}
+		if normalizePlanStatus(task.Status) != "cancelled" {
+			return fmt.Errorf("task %s is superseded by %s but is not cancelled", ids[i], by)
+		}
+		links[ids[i]] = []string{by}
+	}
+	if cycles := small.DependencyCycles(ids, links); len(cycles) > 0 {
+		return fmt.Errorf("superseded_by cycle: %s", strings.Join(cycles[0], " -> "))
+	}
+	return nil
+}
+
+// cancelPlanTask marks a task cancelled, optionally superseded by another task.
+func cancelPlanTask(plan *PlanData, taskID, supersededBy string) error {
+	if supersededBy != "" {
+		if supersededBy == taskID {
+			return fmt.Errorf("task cannot supersede itself")
+		}
+		replacement, _ := findTask(plan, supersededBy)
+		if replacement == nil {
+			return fmt.Errorf("replacement task %s not found", supersededBy)
+		}
+	}
+	if err := setTaskStatus(plan, taskID, "cancelled"); err != nil {
+		return err
+	}
+	task, _ := findTask(plan, taskID)
+	task.SupersededBy = supersededBy
+	if err := validatePlanSupersession(plan.Tasks); err != nil {
+		task.SupersededBy = ""
+		return err
+	}
+	return nil
+}
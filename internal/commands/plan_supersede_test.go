@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func TestNextActionableTaskIDsFollowsSupersededTasks(t *testing.T) {
+	tasks := []PlanTask{
+		{ID: "task-1", Status: "cancelled"},
+		{ID: "task-2", Status: "cancelled", SupersededBy: "task-4"},
+		{ID: "task-3", Status: "pending", Dependencies: []string{"task-1", "task-2"}},
+		{ID: "task-4", Status: "cancelled", SupersededBy: "task-5"},
+		{ID: "task-5", Status: "pending"},
+	}
+	if got := nextActionableTaskIDs(tasks, 0); !reflect.DeepEqual(got, []string{"task-5"}) {
+		t.Fatalf("task-3 should wait on task-5 through the superseded chain, got %v", got)
+	}
+
+	tasks[4].Status = "completed"
+	if got := nextActionableTaskIDs(tasks, 0); !reflect.DeepEqual(got, []string{"task-3"}) {
+		t.Fatalf("expected task-3 once the replacement completed, got %v", got)
+	}
+}
+
+func TestValidatePlanSupersession(t *testing.T) {
+	cases := map[string][]PlanTask{
+		"unknown task": {{ID: "a", Status: "cancelled", SupersededBy: "z"}},
+		"not cancelled": {
+			{ID: "a", Status: "pending", SupersededBy: "b"},
+			{ID: "b"},
+		},
+		"cycle": {
+			{ID: "a", Status: "cancelled", SupersededBy: "b"},
+			{ID: "b", Status: "cancelled", SupersededBy: "a"},
+		},
+	}
+	for name, tasks := range cases {
+		if err := validatePlanSupersession(tasks); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestPlanCancelAndSupersede(t *testing.T) {
+	dir := setupPlanEditWorkspace(t)
+
+	if err := runPlanSubcommand(t, dir, "--cancel", "task-3"); err == nil || !strings.Contains(err.Error(), "--reason") {
+		t.Fatalf("expected --cancel without --reason to fail, got %v", err)
+	}
+	if err := runPlanSubcommand(t, dir, "--cancel", "task-3", "--reason", "Client dropped from scope"); err != nil {
+		t.Fatalf("plan --cancel failed: %v", err)
+	}
+	if err := runPlanSubcommand(t, dir, "--add", "API v2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runPlanSubcommand(t, dir, "--supersede", "task-2", "--by", "task-5", "--reason", "Redesigned"); err != nil {
+		t.Fatalf("plan --supersede failed: %v", err)
+	}
+	if err := runPlanSubcommand(t, dir, "--supersede", "task-5", "--by", "task-2"); err == nil {
+		t.Fatal("expected a superseded_by cycle to be rejected")
+	}
+
+	plan := loadPlanForTest(t, dir)
+	old, _ := findTask(plan, "task-2")
+	if old.Status != "cancelled" || old.SupersededBy != "task-5" {
+		t.Fatalf("unexpected superseded task: %+v", old)
+	}
+
+	progress, err := loadProgressData(filepath.Join(dir, small.SmallDir, "progress.small.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	evidence := map[string]string{}
+	for _, entry := range progress.Entries {
+		if stringVal(entry["status"]) == "cancelled" {
+			evidence[stringVal(entry["task_id"])] = stringVal(entry["evidence"])
+		}
+	}
+	want := map[string]string{"task-3": "Client dropped from scope", "task-2": "Superseded by task-5: Redesigned"}
+	if !reflect.DeepEqual(evidence, want) {
+		t.Fatalf("cancelled evidence = %v, want %v", evidence, want)
+	}
+
+	if err := runPlanSubcommand(t, dir, "--pending", "task-2"); err != nil {
+		t.Fatal(err)
+	}
+	reopened, _ := findTask(loadPlanForTest(t, dir), "task-2")
+	if reopened.SupersededBy != "" {
+		t.Fatalf("reopening a task should clear superseded_by, got %+v", reopened)
+	}
+
+	if err := ensureProgressEvidence(dir, "task-4"); err != nil {
+		t.Fatal(err)
+	}
+	if code := runVerify(dir, true, true, workspace.ScopeAny); code != ExitValid {
+		t.Fatalf("strict verify after cancellations exited %d", code)
+	}
+}
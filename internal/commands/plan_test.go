@@ -191,6 +191,19 @@ func TestAddDependency(t *testing.T) {
 			depID:   "task-1",
 			wantErr: true,
 		},
+		{
+			name: "cyclic dependency",
+			plan: &PlanData{
+				Tasks: []PlanTask{
+					{ID: "task-1", Title: "Test 1", Status: "pending", Dependencies: []string{"task-2"}},
+					{ID: "task-2", Title: "Test 2", Status: "pending", Dependencies: []string{"task-3"}},
+					{ID: "task-3", Title: "Test 3", Status: "pending"},
+				},
+			},
+			taskID:  "task-3",
+			depID:   "task-1",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/justyn-clark/small-protocol/internal/small"
@@ -24,6 +25,10 @@ type ProgressData struct {
 
 var progressTimestampNow = time.Now
 
+// artifactWriteMu serialises read-modify-write updates of progress and plan within this
+// process, so concurrent run-plan workers append entries one at a time.
+var artifactWriteMu sync.Mutex
+
 type progressAddResult struct {
 	Entry            map[string]any
 	WorkspaceDir     string
@@ -40,11 +45,14 @@ func progressCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "progress",
 		Short: "Manage progress.small.yml",
-		Long:  "Utilities for maintaining progress.small.yml, including timestamp migration.",
+		Long:  "Utilities for maintaining progress.small.yml, including timestamp migration, the JSON Lines ledger, and the hash chain.",
 	}
 
 	cmd.AddCommand(progressAddCmd())
 	cmd.AddCommand(progressMigrateCmd())
+	cmd.AddCommand(progressCompactCmd())
+	cmd.AddCommand(progressStorageCmd())
+	cmd.AddCommand(progressSealCmd())
 	return cmd
 }
 
@@ -59,6 +67,7 @@ func progressAddCmd() *cobra.Command {
 		dir            string
 		workspaceFlag  string
 		jsonOutput     bool
+		ifMatch        string
 	)
 
 	cmd := &cobra.Command{
@@ -90,10 +99,17 @@ func progressAddCmd() *cobra.Command {
 				return fmt.Errorf("invalid status %q (must be pending, in_progress, completed, blocked, or cancelled)", status)
 			}
 
+			unlock, err := lockArtifactRevision(artifactsDir, "progress.small.yml", ifMatch)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
 			progressPath := filepath.Join(artifactsDir, small.SmallDir, "progress.small.yml")
 			progress, err := loadProgressData(progressPath)
+			progressMissing := os.IsNotExist(err)
 			if err != nil {
-				if os.IsNotExist(err) {
+				if progressMissing {
 					progress = ProgressData{
 						SmallVersion: small.ProtocolVersion,
 						Owner:        "agent",
@@ -136,16 +152,19 @@ func progressAddCmd() *cobra.Command {
 				return err
 			}
 
-			originalContent, err := os.ReadFile(progressPath)
+			originalProgress, err := snapshotProgress(artifactsDir)
 			if err != nil {
-				if os.IsNotExist(err) {
-					originalContent = nil
-				} else {
-					return fmt.Errorf("failed to read progress.small.yml: %w", err)
-				}
+				return err
 			}
 
-			if err := appendProgressEntryWithData(artifactsDir, entry, progress); err != nil {
+			// A missing progress file starts from the empty log read above; otherwise
+			// progress is re-read so the entry follows everything already written.
+			if progressMissing {
+				err = writeProgressEntry(artifactsDir, entry, progress)
+			} else {
+				err = appendProgressEntryLocked(artifactsDir, entry)
+			}
+			if err != nil {
 				return fmt.Errorf("failed to append progress entry: %w", err)
 			}
 
@@ -158,11 +177,7 @@ func progressAddCmd() *cobra.Command {
 			}
 
 			if err := validateProgressArtifact(artifactsDir); err != nil {
-				if originalContent == nil {
-					_ = os.Remove(progressPath)
-				} else {
-					_ = os.WriteFile(progressPath, originalContent, 0o644)
-				}
+				originalProgress.restore()
 				return err
 			}
 
@@ -199,6 +214,7 @@ func progressAddCmd() *cobra.Command {
 	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
 	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root, examples, or any)")
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	registerIfMatchFlag(cmd, &ifMatch, "progress.small.yml")
 
 	_ = cmd.MarkFlagRequired("task")
 	_ = cmd.MarkFlagRequired("status")
@@ -239,6 +255,12 @@ monotonic contract.`,
 				return fmt.Errorf("progress.small.yml not found. Run 'small init' first")
 			}
 
+			unlock, err := small.LockWorkspace(artifactsDir)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
 			changed, err := migrateProgressFile(progressPath)
 			if err != nil {
 				return err
@@ -265,6 +287,16 @@ func migrateProgressFile(progressPath string) (int, error) {
 		return 0, fmt.Errorf("failed to read progress file: %w", err)
 	}
 
+	// Timestamps are covered by the hash chain, so an intact chain is re-sealed after
+	// they change. A broken one is left for 'small progress seal --force'.
+	baseDir := filepath.Dir(filepath.Dir(progressPath))
+	chained := small.ProgressChainEnforced(baseDir, progressChainEntries(progress.Entries))
+	if chained {
+		if err := checkProgressChain(baseDir, progress.Entries); err != nil {
+			return 0, fmt.Errorf("%w; run 'small progress seal --force' before migrating", err)
+		}
+	}
+
 	changed, err := normalizeProgressEntries(progress.Entries)
 	if err != nil {
 		return 0, err
@@ -272,6 +304,11 @@ func migrateProgressFile(progressPath string) (int, error) {
 	if changed == 0 {
 		return 0, nil
 	}
+	if chained {
+		if err := small.SealProgressEntries(progress.Entries); err != nil {
+			return 0, err
+		}
+	}
 
 	progress.SmallVersion = small.ProtocolVersion
 	progress.Owner = "agent"
@@ -283,6 +320,10 @@ func migrateProgressFile(progressPath string) (int, error) {
 	if err := os.WriteFile(progressPath, updated, 0o644); err != nil {
 		return 0, fmt.Errorf("failed to write progress file: %w", err)
 	}
+	// The rewritten file holds the ledger's entries too.
+	if err := small.ResetProgressLedger(filepath.Join(filepath.Dir(progressPath), small.ProgressLedgerFileName)); err != nil {
+		return 0, err
+	}
 
 	return changed, nil
 }
@@ -337,6 +378,33 @@ func attachProgressReplayID(baseDir string, entry map[string]any) {
 }
 
 func appendProgressEntry(baseDir string, entry map[string]any) error {
+	artifactWriteMu.Lock()
+	defer artifactWriteMu.Unlock()
+	return appendProgressEntryLocked(baseDir, entry)
+}
+
+// appendProgressEntryLocked appends entry under the workspace lock, re-reading progress
+// there so that entries written since the caller last read it are kept. Callers that
+// read progress first hold the workspace lock from before that read, and in-process
+// callers hold artifactWriteMu.
+func appendProgressEntryLocked(baseDir string, entry map[string]any) error {
+	unlock, err := small.LockWorkspace(baseDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if small.ProgressLedgerEnabled(baseDir) {
+		lastEntry, err := lastProgressLedgerEntry(baseDir)
+		if err != nil {
+			return fmt.Errorf("failed to read progress file: %w", err)
+		}
+		// An empty ledger has just been compacted; the last entry is in the YAML view.
+		if lastEntry != nil {
+			return appendProgressLedgerEntry(baseDir, entry, []map[string]any{lastEntry})
+		}
+	}
+
 	progressPath := filepath.Join(baseDir, small.SmallDir, "progress.small.yml")
 
 	progress, err := loadProgressData(progressPath)
@@ -344,16 +412,21 @@ func appendProgressEntry(baseDir string, entry map[string]any) error {
 		return fmt.Errorf("failed to read progress file: %w", err)
 	}
 
-	lastTimestamp, err := lastProgressTimestamp(progress.Entries)
-	if err != nil {
-		return fmt.Errorf("existing progress timestamps invalid: %w (run 'small progress migrate' to repair)", err)
+	return writeProgressEntry(baseDir, entry, progress)
+}
+
+// writeProgressEntry appends entry after the entries in progress: as one ledger line when
+// the workspace uses the JSON Lines ledger, otherwise by rewriting progress.small.yml.
+// Callers hold the workspace lock.
+func writeProgressEntry(baseDir string, entry map[string]any, progress ProgressData) error {
+	if small.ProgressLedgerEnabled(baseDir) {
+		return appendProgressLedgerEntry(baseDir, entry, progress.Entries)
 	}
+	progressPath := filepath.Join(baseDir, small.SmallDir, "progress.small.yml")
 
-	if _, err := normalizeEntryTimestamp(entry, lastTimestamp); err != nil {
+	if err := prepareProgressEntry(baseDir, entry, progress.Entries); err != nil {
 		return err
 	}
-
-	attachProgressReplayID(baseDir, entry)
 	progress.Entries = append(progress.Entries, entry)
 	progress.SmallVersion = small.ProtocolVersion
 	progress.Owner = "agent"
@@ -363,7 +436,7 @@ func appendProgressEntry(baseDir string, entry map[string]any) error {
 		return fmt.Errorf("failed to marshal progress: %w", err)
 	}
 
-	if err := os.WriteFile(progressPath, yamlData, 0o644); err != nil {
+	if err := small.WriteFileAtomic(progressPath, yamlData, 0o644); err != nil {
 		return fmt.Errorf("failed to write progress file: %w", err)
 	}
 	if err := touchWorkspaceUpdatedAt(baseDir); err != nil {
@@ -373,10 +446,10 @@ func appendProgressEntry(baseDir string, entry map[string]any) error {
 	return nil
 }
 
-func appendProgressEntryWithData(baseDir string, entry map[string]any, progress ProgressData) error {
-	progressPath := filepath.Join(baseDir, small.SmallDir, "progress.small.yml")
-
-	lastTimestamp, err := lastProgressTimestamp(progress.Entries)
+// prepareProgressEntry orders entry after entries, stamps the run and agent on it, and
+// links it into the hash chain.
+func prepareProgressEntry(baseDir string, entry map[string]any, entries []map[string]any) error {
+	lastTimestamp, err := lastProgressTimestamp(entries)
 	if err != nil {
 		return fmt.Errorf("existing progress timestamps invalid: %w (run 'small progress migrate' to repair)", err)
 	}
@@ -386,23 +459,23 @@ func appendProgressEntryWithData(baseDir string, entry map[string]any, progress
 	}
 
 	attachProgressReplayID(baseDir, entry)
-	progress.Entries = append(progress.Entries, entry)
-	progress.SmallVersion = small.ProtocolVersion
-	progress.Owner = "agent"
-
-	yamlData, err := small.MarshalYAMLWithQuotedVersion(&progress)
-	if err != nil {
-		return fmt.Errorf("failed to marshal progress: %w", err)
-	}
+	attachProgressAgent(entry)
+	return chainProgressEntry(entry, entries)
+}
 
-	if err := os.WriteFile(progressPath, yamlData, 0o644); err != nil {
-		return fmt.Errorf("failed to write progress file: %w", err)
+// chainProgressEntry links entry to the last of entries. A log written before the hash
+// chain existed stays unchained until 'small progress seal' backfills it.
+func chainProgressEntry(entry map[string]any, entries []map[string]any) error {
+	if len(entries) == 0 {
+		return small.ChainProgressEntry(entry, "")
 	}
-	if err := touchWorkspaceUpdatedAt(baseDir); err != nil {
-		return err
+	last := entries[len(entries)-1]
+	if !small.ProgressEntryChained(last) {
+		delete(entry, "prev_hash")
+		delete(entry, "entry_hash")
+		return nil
 	}
-
-	return nil
+	return small.ChainProgressEntry(entry, stringVal(last["entry_hash"]))
 }
 
 func lastProgressTimestamp(entries []map[string]any) (time.Time, error) {
@@ -540,6 +613,17 @@ func loadProgressData(progressPath string) (ProgressData, error) {
 	if progress.Entries == nil {
 		progress.Entries = []map[string]any{}
 	}
+
+	// Entries appended to a JSON Lines ledger beside the file follow the YAML ones.
+	var last time.Time
+	if len(progress.Entries) > 0 {
+		last, _ = lastProgressTimestamp(progress.Entries)
+	}
+	ledger, err := small.ReadProgressLedger(filepath.Join(filepath.Dir(progressPath), small.ProgressLedgerFileName), last)
+	if err != nil {
+		return ProgressData{}, err
+	}
+	progress.Entries = append(progress.Entries, ledger...)
 	return progress, nil
 }
 
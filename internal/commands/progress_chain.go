@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// progressChainEntries converts loaded progress entries for the small chain helpers.
+func progressChainEntries(entries []map[string]any) []any {
+	out := make([]any, len(entries))
+	for i, entry := range entries {
+		out[i] = entry
+	}
+	return out
+}
+
+// checkProgressChain returns an error describing the first break in a hash chain that
+// the workspace requires or the entries have adopted. Otherwise unchained entries pass.
+func checkProgressChain(baseDir string, entries []map[string]any) error {
+	chain := progressChainEntries(entries)
+	if !small.ProgressChainEnforced(baseDir, chain) {
+		return nil
+	}
+	if index, reason := small.VerifyProgressChain(chain); index >= 0 {
+		return fmt.Errorf("progress hash chain broken at entries[%d] (task %s): %s", index, stringVal(entries[index]["task_id"]), reason)
+	}
+	return nil
+}
+
+// sealProgress backfills the hash chain over every progress entry and writes them to
+// progress.small.yml, folding in the ledger if there is one. It returns the number of
+// entries sealed. Callers hold the workspace lock.
+func sealProgress(baseDir string, force bool) (int, error) {
+	progressPath := filepath.Join(baseDir, small.SmallDir, "progress.small.yml")
+	progress, err := loadProgressData(progressPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read progress file: %w", err)
+	}
+	if err := checkProgressChain(baseDir, progress.Entries); err != nil && !force {
+		return 0, fmt.Errorf("%w; sealing would hide the change, rerun with --force to re-seal anyway", err)
+	}
+	if err := small.SealProgressEntries(progress.Entries); err != nil {
+		return 0, err
+	}
+
+	progress.SmallVersion = small.ProtocolVersion
+	progress.Owner = "agent"
+	data, err := small.MarshalYAMLWithQuotedVersion(&progress)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal progress: %w", err)
+	}
+	if err := small.WriteFileAtomic(progressPath, data, 0o644); err != nil {
+		return 0, fmt.Errorf("failed to write progress.small.yml: %w", err)
+	}
+	// The rewritten file holds the ledger's entries too.
+	if err := small.ResetProgressLedger(small.ProgressLedgerPath(baseDir)); err != nil {
+		return 0, err
+	}
+	if err := workspace.SetProgressChain(baseDir); err != nil {
+		return 0, err
+	}
+	return len(progress.Entries), nil
+}
+
+func progressSealCmd() *cobra.Command {
+	var dir string
+	var workspaceFlag string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "seal",
+		Short: "Backfill the tamper-evident hash chain over progress entries",
+		Long: `Stamps prev_hash and entry_hash on every progress entry, in order, so that
+strict invariant S6 can detect later edits, reorders, and deletions. New entries
+are chained automatically once the log is sealed; workspaces created by
+'small init' are chained from their first entry.
+
+An entry's entry_hash is the sha256 of its canonical JSON (keys sorted, no
+whitespace) without entry_hash; prev_hash is the previous entry's entry_hash.
+
+Sealing a log whose chain is already broken would hide the change, so seal
+refuses unless --force is set. A ledger, if any, is folded into
+progress.small.yml first.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			artifactsDir, err := resolveProgressStorageDir(dir, workspaceFlag)
+			if err != nil {
+				return err
+			}
+			if !small.ArtifactExists(artifactsDir, "progress.small.yml") {
+				return fmt.Errorf("progress.small.yml not found. Run 'small init' first")
+			}
+
+			artifactWriteMu.Lock()
+			defer artifactWriteMu.Unlock()
+			unlock, err := small.LockWorkspace(artifactsDir)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			sealed, err := sealProgress(artifactsDir, force)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Sealed %d progress entries\n", sealed)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
+	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root, examples, or any)")
+	cmd.Flags().BoolVar(&force, "force", false, "Re-seal even if the existing chain is broken")
+
+	return cmd
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func runProgressSubcommand(t *testing.T, dir string, args ...string) error {
+	t.Helper()
+	cmd := progressCmd()
+	cmd.SetArgs(append(args, "--dir", dir))
+	return cmd.Execute()
+}
+
+func TestProgressSealAndChainedAppends(t *testing.T) {
+	setAgentFlag(t, "")
+	dir := setupPlanEditWorkspace(t)
+	progressPath := filepath.Join(dir, small.SmallDir, "progress.small.yml")
+	legacy := `small_version: "1.0.0"
+owner: "agent"
+entries:
+  - task_id: "task-4"
+    status: "completed"
+    timestamp: "2026-01-01T00:00:00.000000000Z"
+    evidence: "written before the hash chain"
+    agent:
+      id: "human:ops"
+`
+	if err := os.WriteFile(progressPath, []byte(legacy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := appendProgressEntry(dir, map[string]any{"task_id": "task-1", "status": "in_progress", "evidence": "before seal"}); err != nil {
+		t.Fatal(err)
+	}
+	progress, err := loadProgressData(progressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := progress.Entries[len(progress.Entries)-1]["entry_hash"]; ok {
+		t.Fatal("appends to an unsealed log should stay unchained")
+	}
+
+	if err := runProgressSubcommand(t, dir, "seal"); err != nil {
+		t.Fatalf("progress seal failed: %v", err)
+	}
+	if err := runProgressSubcommand(t, dir, "storage", "jsonl"); err != nil {
+		t.Fatal(err)
+	}
+	if err := appendProgressEntry(dir, map[string]any{"task_id": "task-1", "status": "in_progress", "evidence": "after seal"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runProgressSubcommand(t, dir, "compact"); err != nil {
+		t.Fatal(err)
+	}
+	progress, err = loadProgressData(progressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkProgressChain(dir, progress.Entries); err != nil || !small.ProgressEntryChained(progress.Entries[len(progress.Entries)-1]) {
+		t.Fatalf("expected new entries to extend the sealed chain, got %v", err)
+	}
+	if code := runVerify(dir, true, false, workspace.ScopeAny); code != ExitValid {
+		t.Fatalf("expected the sealed log to verify, got exit %d", code)
+	}
+
+	data, err := os.ReadFile(progressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(progressPath, []byte(strings.Replace(string(data), "before seal", "rewritten", 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code := runVerify(dir, true, false, workspace.ScopeAny); code != ExitInvalid {
+		t.Fatalf("expected an edited entry to fail S6, got exit %d", code)
+	}
+	if err := runProgressSubcommand(t, dir, "seal"); err == nil || !strings.Contains(err.Error(), "hash chain broken") {
+		t.Fatalf("expected seal to refuse a broken chain, got %v", err)
+	}
+	if err := runProgressSubcommand(t, dir, "seal", "--force"); err != nil {
+		t.Fatal(err)
+	}
+	if code := runVerify(dir, true, false, workspace.ScopeAny); code != ExitValid {
+		t.Fatalf("expected seal --force to repair the chain, got exit %d", code)
+	}
+}
+
+func TestStrippedProgressChainFailsStrictVerify(t *testing.T) {
+	setAgentFlag(t, "")
+	dir := setupPlanEditWorkspace(t)
+	if err := ensureProgressEvidence(dir, "task-4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := runProgressSubcommand(t, dir, "seal"); err != nil {
+		t.Fatalf("progress seal failed: %v", err)
+	}
+	if !small.ProgressChainRequired(dir) {
+		t.Fatal("expected seal to record the chain in workspace metadata")
+	}
+
+	progressPath := filepath.Join(dir, small.SmallDir, "progress.small.yml")
+	progress, err := loadProgressData(progressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range progress.Entries {
+		delete(entry, "entry_hash")
+		delete(entry, "prev_hash")
+	}
+	progress.Entries[0]["evidence"] = "FORGED"
+	data, err := small.MarshalYAMLWithQuotedVersion(&progress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(progressPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if code := runVerify(dir, true, false, workspace.ScopeAny); code != ExitInvalid {
+		t.Fatalf("expected a stripped chain to fail S6, got exit %d", code)
+	}
+	if err := runProgressSubcommand(t, dir, "seal"); err == nil || !strings.Contains(err.Error(), "entry_hash is missing") {
+		t.Fatalf("expected seal to refuse a stripped chain, got %v", err)
+	}
+}
@@ -0,0 +1,268 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+const (
+	progressStorageYAML  = "yaml"
+	progressStorageJSONL = "jsonl"
+)
+
+func appendProgressLedgerEntry(baseDir string, entry map[string]any, entries []map[string]any) error {
+	if err := prepareProgressEntry(baseDir, entry, entries); err != nil {
+		return err
+	}
+	if err := small.AppendProgressLedgerEntry(small.ProgressLedgerPath(baseDir), entry); err != nil {
+		return err
+	}
+	return touchWorkspaceUpdatedAt(baseDir)
+}
+
+// lastProgressLedgerEntry returns the last ledger entry without reading progress.small.yml,
+// or nil when the ledger is empty.
+func lastProgressLedgerEntry(baseDir string) (map[string]any, error) {
+	return small.LastProgressLedgerEntry(small.ProgressLedgerPath(baseDir))
+}
+
+// compactProgress folds the ledger into progress.small.yml and empties the ledger. It
+// returns the number of entries moved. Callers hold the workspace lock.
+func compactProgress(baseDir string) (int, error) {
+	progressPath := filepath.Join(baseDir, small.SmallDir, "progress.small.yml")
+	artifact, err := small.LoadArtifact(baseDir, "progress.small.yml")
+	if err != nil {
+		return 0, err
+	}
+	entries, _ := artifact.Data["entries"].([]any)
+	moved := len(entries) - artifact.LedgerStart
+	if artifact.LedgerPath == "" || moved == 0 {
+		return 0, small.ResetProgressLedger(small.ProgressLedgerPath(baseDir))
+	}
+
+	progress := ProgressData{SmallVersion: small.ProtocolVersion, Owner: "agent", Entries: []map[string]any{}}
+	for _, entry := range entries {
+		if entryMap, ok := entry.(map[string]any); ok {
+			progress.Entries = append(progress.Entries, entryMap)
+		}
+	}
+	data, err := small.MarshalYAMLWithQuotedVersion(&progress)
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal progress: %w", err)
+	}
+	// If the process dies between these two writes, loaders skip the ledger entries
+	// that are no newer than the last one in progress.small.yml, so none is read twice.
+	if err := small.WriteFileAtomic(progressPath, data, 0o644); err != nil {
+		return 0, fmt.Errorf("failed to write progress.small.yml: %w", err)
+	}
+	if err := small.ResetProgressLedger(small.ProgressLedgerPath(baseDir)); err != nil {
+		return 0, err
+	}
+	return moved, nil
+}
+
+// progressSnapshot holds the bytes of progress.small.yml and the ledger so a failed
+// multi-artifact update can put both back.
+type progressSnapshot struct {
+	baseDir string
+	yaml    []byte
+	ledger  []byte
+	hasYAML bool
+	hasLog  bool
+}
+
+func snapshotProgress(baseDir string) (progressSnapshot, error) {
+	snapshot := progressSnapshot{baseDir: baseDir}
+	var err error
+	snapshot.yaml, snapshot.hasYAML, err = readOptionalFile(filepath.Join(baseDir, small.SmallDir, "progress.small.yml"))
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to snapshot progress.small.yml: %w", err)
+	}
+	snapshot.ledger, snapshot.hasLog, err = readOptionalFile(small.ProgressLedgerPath(baseDir))
+	if err != nil {
+		return snapshot, fmt.Errorf("failed to snapshot %s: %w", small.ProgressLedgerFileName, err)
+	}
+	return snapshot, nil
+}
+
+// restore writes the snapshot back, removing files that did not exist when it was taken.
+func (s progressSnapshot) restore() {
+	restoreOptionalFile(filepath.Join(s.baseDir, small.SmallDir, "progress.small.yml"), s.yaml, s.hasYAML)
+	restoreOptionalFile(small.ProgressLedgerPath(s.baseDir), s.ledger, s.hasLog)
+}
+
+func readOptionalFile(path string) ([]byte, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func restoreOptionalFile(path string, data []byte, exists bool) {
+	if !exists {
+		_ = os.Remove(path)
+		return
+	}
+	_ = os.WriteFile(path, data, 0o644)
+}
+
+// initProgressStorage sets up storage for a freshly written progress.small.yml, dropping
+// any ledger left by an earlier workspace.
+func initProgressStorage(baseDir, storage string) error {
+	ledgerPath := small.ProgressLedgerPath(baseDir)
+	if storage == progressStorageJSONL {
+		if err := os.WriteFile(ledgerPath, nil, 0o644); err != nil {
+			return fmt.Errorf("failed to create %s: %w", small.ProgressLedgerFileName, err)
+		}
+		return nil
+	}
+	if err := os.Remove(ledgerPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", small.ProgressLedgerFileName, err)
+	}
+	return nil
+}
+
+// progressStorage reports how the workspace stores new progress entries.
+func progressStorage(baseDir string) string {
+	if small.ProgressLedgerEnabled(baseDir) {
+		return progressStorageJSONL
+	}
+	return progressStorageYAML
+}
+
+func progressCompactCmd() *cobra.Command {
+	var dir string
+	var workspaceFlag string
+
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Fold the progress ledger into progress.small.yml",
+		Long: `Rewrites progress.small.yml with every entry appended to progress.small.jsonl
+and empties the ledger. The workspace keeps using the ledger for new entries.
+Entry order and content are unchanged.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			artifactsDir, err := resolveProgressStorageDir(dir, workspaceFlag)
+			if err != nil {
+				return err
+			}
+			if !small.ProgressLedgerEnabled(artifactsDir) {
+				fmt.Println("progress.small.yml does not use a ledger; nothing to compact")
+				return nil
+			}
+			unlock, err := small.LockWorkspace(artifactsDir)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			moved, err := compactProgress(artifactsDir)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Compacted %d ledger entries into progress.small.yml\n", moved)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
+	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root, examples, or any)")
+
+	return cmd
+}
+
+func progressStorageCmd() *cobra.Command {
+	var dir string
+	var workspaceFlag string
+
+	cmd := &cobra.Command{
+		Use:   "storage [yaml|jsonl]",
+		Short: "Show or change how new progress entries are stored",
+		Long: `Without an argument, prints the current progress storage.
+
+jsonl appends each new entry to .small/progress.small.jsonl as a single fsync'd
+line instead of rewriting progress.small.yml. Loaders read both files as one
+progress log; run 'small progress compact' to fold the ledger into the YAML view.
+
+yaml compacts the ledger and removes it, so entries are written to
+progress.small.yml again.`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: []string{progressStorageYAML, progressStorageJSONL},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			artifactsDir, err := resolveProgressStorageDir(dir, workspaceFlag)
+			if err != nil {
+				return err
+			}
+			current := progressStorage(artifactsDir)
+			if len(args) == 0 {
+				fmt.Println(current)
+				return nil
+			}
+			target := strings.ToLower(strings.TrimSpace(args[0]))
+			if target != progressStorageYAML && target != progressStorageJSONL {
+				return fmt.Errorf("invalid storage %q (must be yaml or jsonl)", args[0])
+			}
+			if target == current {
+				fmt.Printf("Progress storage is already %s\n", current)
+				return nil
+			}
+			if !small.ArtifactExists(artifactsDir, "progress.small.yml") {
+				return fmt.Errorf("progress.small.yml not found. Run 'small init' first")
+			}
+
+			unlock, err := small.LockWorkspace(artifactsDir)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			ledgerPath := small.ProgressLedgerPath(artifactsDir)
+			if target == progressStorageJSONL {
+				if err := os.WriteFile(ledgerPath, nil, 0o644); err != nil {
+					return fmt.Errorf("failed to create %s: %w", small.ProgressLedgerFileName, err)
+				}
+			} else {
+				if _, err := compactProgress(artifactsDir); err != nil {
+					return err
+				}
+				if err := os.Remove(ledgerPath); err != nil && !os.IsNotExist(err) {
+					return fmt.Errorf("failed to remove %s: %w", small.ProgressLedgerFileName, err)
+				}
+			}
+			fmt.Printf("Progress storage set to %s\n", target)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
+	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root, examples, or any)")
+
+	return cmd
+}
+
+func resolveProgressStorageDir(dir, workspaceFlag string) (string, error) {
+	if dir == "" {
+		dir = baseDir
+	}
+	artifactsDir := resolveArtifactsDir(dir)
+	scope, err := workspace.ParseScope(workspaceFlag)
+	if err != nil {
+		return "", err
+	}
+	if scope != workspace.ScopeAny {
+		if err := enforceWorkspaceScope(artifactsDir, scope); err != nil {
+			return "", err
+		}
+	}
+	return artifactsDir, nil
+}
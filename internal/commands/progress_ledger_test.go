@@ -0,0 +1,81 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+)
+
+func TestProgressLedgerStorageAndCompact(t *testing.T) {
+	setAgentFlag(t, "")
+	dir := setupPlanEditWorkspace(t)
+	progressPath := filepath.Join(dir, small.SmallDir, "progress.small.yml")
+	before, err := loadProgressData(progressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storage := progressCmd()
+	storage.SetArgs([]string{"storage", "jsonl", "--dir", dir})
+	if err := storage.Execute(); err != nil {
+		t.Fatalf("progress storage jsonl failed: %v", err)
+	}
+	yamlBefore, err := os.ReadFile(progressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runPlanSubcommand(t, dir, "--pending", "task-2"); err != nil {
+		t.Fatal(err)
+	}
+	add := progressCmd()
+	add.SetArgs([]string{"add", "--dir", dir, "--task", "task-2", "--status", "in_progress", "--evidence", "Started"})
+	if err := add.Execute(); err != nil {
+		t.Fatalf("progress add failed: %v", err)
+	}
+
+	yamlAfter, err := os.ReadFile(progressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(yamlAfter) != string(yamlBefore) {
+		t.Fatal("expected appends to leave progress.small.yml untouched")
+	}
+	merged, err := loadProgressData(progressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged.Entries) != len(before.Entries)+2 || merged.Entries[len(merged.Entries)-1]["evidence"] != "Started" {
+		t.Fatalf("expected 2 ledger entries after the YAML ones, got %d entries", len(merged.Entries))
+	}
+	if err := validateProgressArtifact(dir); err != nil {
+		t.Fatalf("expected the merged progress to validate: %v", err)
+	}
+
+	compact := progressCmd()
+	compact.SetArgs([]string{"compact", "--dir", dir})
+	if err := compact.Execute(); err != nil {
+		t.Fatalf("progress compact failed: %v", err)
+	}
+	if info, err := os.Stat(small.ProgressLedgerPath(dir)); err != nil || info.Size() != 0 {
+		t.Fatalf("expected an empty ledger after compaction, got %v", err)
+	}
+	compacted, err := loadProgressData(progressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compacted.Entries) != len(merged.Entries) {
+		t.Fatalf("expected compaction to keep %d entries, got %d", len(merged.Entries), len(compacted.Entries))
+	}
+
+	storage = progressCmd()
+	storage.SetArgs([]string{"storage", "yaml", "--dir", dir})
+	if err := storage.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if small.ProgressLedgerEnabled(dir) {
+		t.Fatal("expected progress storage yaml to remove the ledger")
+	}
+}
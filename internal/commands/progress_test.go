@@ -1,7 +1,9 @@
 package commands
 
 import (
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 	"time"
@@ -169,20 +171,16 @@ func TestProgressAddMonotonicTimestamp(t *testing.T) {
 		"status":    "in_progress",
 		"timestamp": formatProgressTimestamp(fixed),
 	}
-	if err := appendProgressEntryWithData(tmpDir, entry, progress); err != nil {
-		t.Fatalf("appendProgressEntryWithData error: %v", err)
-	}
-	updatedProgress, err := loadProgressData(progressPath)
-	if err != nil {
-		t.Fatalf("loadProgressData error: %v", err)
+	if err := appendProgressEntryLocked(tmpDir, entry); err != nil {
+		t.Fatalf("appendProgressEntryLocked error: %v", err)
 	}
 	second := map[string]any{
 		"task_id":   "task-2",
 		"status":    "in_progress",
 		"timestamp": formatProgressTimestamp(fixed),
 	}
-	if err := appendProgressEntryWithData(tmpDir, second, updatedProgress); err != nil {
-		t.Fatalf("appendProgressEntryWithData second error: %v", err)
+	if err := appendProgressEntryLocked(tmpDir, second); err != nil {
+		t.Fatalf("appendProgressEntryLocked second error: %v", err)
 	}
 
 	updated, err := loadProgressData(progressPath)
@@ -222,14 +220,68 @@ func TestProgressAddCreatesFileIfMissing(t *testing.T) {
 		"notes":     "created via test",
 		"timestamp": formatProgressTimestamp(fixed),
 	}
-	if err := appendProgressEntryWithData(tmpDir, entry, progress); err != nil {
-		t.Fatalf("appendProgressEntryWithData error: %v", err)
+	if err := writeProgressEntry(tmpDir, entry, progress); err != nil {
+		t.Fatalf("writeProgressEntry error: %v", err)
 	}
 	if _, err := os.Stat(progressPath); err != nil {
 		t.Fatalf("expected progress.small.yml to exist: %v", err)
 	}
 }
 
+// progressWriterDirEnv makes TestProgressAddWriterProcess append one entry to the
+// workspace it names, so the concurrency test can run writers as separate processes.
+const progressWriterDirEnv = "SMALL_TEST_PROGRESS_WRITER_DIR"
+
+func TestProgressAddWriterProcess(t *testing.T) {
+	dir := os.Getenv(progressWriterDirEnv)
+	if dir == "" {
+		t.Skip("only runs as a writer for TestProgressAddConcurrentWriters")
+	}
+	setAgentFlag(t, "")
+	if err := runProgressSubcommand(t, dir, "add", "--task", "task-1", "--status", "in_progress", "--evidence", "concurrent writer"); err != nil {
+		t.Fatalf("progress add failed: %v", err)
+	}
+}
+
+func TestProgressAddConcurrentWriters(t *testing.T) {
+	dir := setupPlanEditWorkspace(t)
+	progressPath := filepath.Join(dir, small.SmallDir, "progress.small.yml")
+	before, err := loadProgressData(progressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const writers = 12
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		go func() {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestProgressAddWriterProcess$")
+			cmd.Env = append(os.Environ(), progressWriterDirEnv+"="+dir, "SMALL_LOCK_TIMEOUT=60s")
+			if out, err := cmd.CombinedOutput(); err != nil {
+				errs <- fmt.Errorf("writer failed: %v\n%s", err, out)
+				return
+			}
+			errs <- nil
+		}()
+	}
+	for i := 0; i < writers; i++ {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+
+	after, err := loadProgressData(progressPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(after.Entries) - len(before.Entries); got != writers {
+		t.Fatalf("expected %d new entries from concurrent writers, got %d", writers, got)
+	}
+	if _, err := lastProgressTimestamp(after.Entries); err != nil {
+		t.Fatalf("concurrent writers left invalid timestamps: %v", err)
+	}
+}
+
 func TestMigrateProgressFileRejectsUnparseable(t *testing.T) {
 	oldNow := progressTimestampNow
 	defer func() { progressTimestampNow = oldNow }()
@@ -0,0 +1,461 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// velocityReport compares task estimates with the time tasks actually took, derived
+// from progress timestamps.
+type velocityReport struct {
+	Tasks    []velocityTask  `json:"tasks"`
+	Runs     []velocityRun   `json:"runs"`
+	Burndown []burndownPoint `json:"burndown"`
+	Totals   velocityTotals  `json:"totals"`
+}
+
+type velocityTask struct {
+	TaskID          string   `json:"task_id"`
+	Title           string   `json:"title"`
+	Status          string   `json:"status"`
+	Estimate        string   `json:"estimate,omitempty"`
+	EstimateSeconds *float64 `json:"estimate_seconds,omitempty"`
+	StartedAt       string   `json:"started_at,omitempty"`
+	FinishedAt      string   `json:"finished_at,omitempty"`
+	ActualSeconds   *float64 `json:"actual_seconds,omitempty"`
+	// ActualToEstimate is actual time over estimate; above 1 means the task overran.
+	ActualToEstimate *float64 `json:"actual_to_estimate,omitempty"`
+}
+
+// velocityRun summarises the progress recorded under one replayId.
+type velocityRun struct {
+	ReplayID       string   `json:"replayId"`
+	FirstEntry     string   `json:"first_entry"`
+	LastEntry      string   `json:"last_entry"`
+	Entries        int      `json:"entries"`
+	TasksCompleted int      `json:"tasks_completed"`
+	SpanSeconds    float64  `json:"span_seconds"`
+	TasksPerHour   *float64 `json:"tasks_per_hour,omitempty"`
+}
+
+// burndownPoint is the work left after a task finished; the first point is the work
+// left when progress starts.
+type burndownPoint struct {
+	Timestamp                string  `json:"timestamp"`
+	TaskID                   string  `json:"task_id,omitempty"`
+	RemainingTasks           int     `json:"remaining_tasks"`
+	RemainingEstimateSeconds float64 `json:"remaining_estimate_seconds"`
+}
+
+type velocityTotals struct {
+	Tasks           int     `json:"tasks"`
+	Finished        int     `json:"finished"`
+	EstimateSeconds float64 `json:"estimate_seconds"`
+	// ActualSeconds and EstimatedActualSeconds cover finished tasks with both an
+	// estimate and a measured duration, so their ratio is like for like.
+	ActualSeconds          float64 `json:"actual_seconds"`
+	EstimatedActualSeconds float64 `json:"estimated_actual_seconds"`
+}
+
+type taskTimes struct {
+	started  time.Time
+	finished time.Time
+}
+
+var velocitySections = []string{"tasks", "runs", "burndown"}
+
+func reportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Report on plan and progress history",
+	}
+	cmd.AddCommand(reportVelocityCmd())
+	return cmd
+}
+
+func reportVelocityCmd() *cobra.Command {
+	var (
+		dir           string
+		workspaceFlag string
+		format        string
+		section       string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "velocity",
+		Short: "Compare task estimates with actual durations",
+		Long: `Reports how long tasks took against their estimate, throughput per run, and
+a burn-down series of the work left.
+
+A task's actual duration runs from its first in_progress progress entry to the
+first completed or cancelled entry after it. Audit records of plan edits, which
+restate a task's status, are ignored. Tasks that never recorded in_progress have
+no actual duration; in the default signal progress mode small apply does not
+record in_progress, so set SMALL_PROGRESS_MODE=audit to time applied tasks. Runs group progress entries by replayId. The burn-down starts at
+the first progress entry and drops as each completed task finishes; cancelled
+tasks are left out. Only leaf tasks are reported, since parent tasks derive their
+status from their subtasks.
+
+--format csv writes one section, chosen with --section.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			artifactsDir, _, err := resolveRunContext(dir, "", workspaceFlag)
+			if err != nil {
+				return err
+			}
+			if section != "all" && !containsString(velocitySections, section) {
+				return fmt.Errorf("invalid --section %q (expected all, tasks, runs, or burndown)", section)
+			}
+
+			plan, err := loadPlan(filepath.Join(artifactsDir, small.SmallDir, "plan.small.yml"))
+			if err != nil {
+				return fmt.Errorf("failed to load plan.small.yml: %w", err)
+			}
+			progress := ProgressData{Entries: []map[string]any{}}
+			if small.ArtifactExists(artifactsDir, "progress.small.yml") {
+				progress, err = loadProgressData(filepath.Join(artifactsDir, small.SmallDir, "progress.small.yml"))
+				if err != nil {
+					return fmt.Errorf("failed to load progress.small.yml: %w", err)
+				}
+			}
+			report := buildVelocityReport(plan.Tasks, progress.Entries)
+
+			out := cmd.OutOrStdout()
+			switch format {
+			case "text":
+				fmt.Fprint(out, formatVelocityText(report, section))
+			case "json":
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(out, string(data))
+			case "csv":
+				if section == "all" {
+					return fmt.Errorf("--format csv needs --section tasks, runs, or burndown")
+				}
+				return writeVelocityCSV(out, report, section)
+			default:
+				return fmt.Errorf("invalid --format %q (expected text, json, or csv)", format)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text, json, or csv)")
+	cmd.Flags().StringVar(&section, "section", "all", "Section to print (all, tasks, runs, or burndown)")
+	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
+	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root, examples, or any)")
+
+	return cmd
+}
+
+// planAuditEntry reports whether entry is the audit record of a plan edit, which
+// restates the task's status at the time of the edit rather than changing it.
+// Cancellations and other status flags of small plan are real transitions.
+func planAuditEntry(entry map[string]any) bool {
+	notes := strings.TrimSpace(stringVal(entry["notes"]))
+	switch notes {
+	case "small plan --cancel", "small plan --supersede", "small plan --pending", "small plan --blocked":
+		return false
+	}
+	return strings.HasPrefix(notes, "small plan")
+}
+
+func buildVelocityReport(tasks []PlanTask, entries []map[string]any) *velocityReport {
+	rolled := append([]PlanTask{}, tasks...)
+	rollUpPlanStatuses(rolled)
+	children := planChildren(rolled)
+
+	times := map[string]*taskTimes{}
+	var first time.Time
+	runs := map[string]*velocityRun{}
+	var runOrder []string
+	runCompleted := map[string]map[string]bool{}
+	for _, entry := range entries {
+		ts, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(stringVal(entry["timestamp"])))
+		if err != nil {
+			continue
+		}
+		ts = ts.UTC()
+		if first.IsZero() || ts.Before(first) {
+			first = ts
+		}
+		taskID := strings.TrimSpace(stringVal(entry["task_id"]))
+		status := strings.TrimSpace(stringVal(entry["status"]))
+
+		if replayID := strings.TrimSpace(stringVal(entry["replayId"])); replayID != "" {
+			run, ok := runs[replayID]
+			if !ok {
+				run = &velocityRun{ReplayID: replayID, FirstEntry: formatProgressTimestamp(ts)}
+				runs[replayID] = run
+				runOrder = append(runOrder, replayID)
+				runCompleted[replayID] = map[string]bool{}
+			}
+			run.Entries++
+			run.LastEntry = formatProgressTimestamp(ts)
+			if status == "completed" && taskID != "" && !strings.HasPrefix(taskID, "meta/") && !planAuditEntry(entry) {
+				runCompleted[replayID][taskID] = true
+			}
+		}
+
+		if taskID == "" || planAuditEntry(entry) {
+			continue
+		}
+		t := times[taskID]
+		if t == nil {
+			t = &taskTimes{}
+			times[taskID] = t
+		}
+		switch status {
+		case "in_progress":
+			if t.started.IsZero() {
+				t.started = ts
+			}
+		case "completed", "cancelled":
+			if t.finished.IsZero() && (t.started.IsZero() || !ts.Before(t.started)) {
+				t.finished = ts
+			}
+		}
+	}
+
+	report := &velocityReport{Tasks: []velocityTask{}, Runs: []velocityRun{}, Burndown: []burndownPoint{}}
+	type finish struct {
+		id       string
+		at       time.Time
+		estimate float64
+	}
+	var finishes []finish
+	remaining, remainingEstimate := 0, 0.0
+	for _, task := range rolled {
+		id := strings.TrimSpace(task.ID)
+		if len(children[id]) > 0 {
+			continue
+		}
+		status := normalizePlanStatus(task.Status)
+		row := velocityTask{TaskID: id, Title: strings.TrimSpace(task.Title), Status: status, Estimate: strings.TrimSpace(task.Estimate)}
+		estimate := 0.0
+		if d, err := small.ParseTaskEstimate(task.Estimate); err == nil {
+			estimate = d.Seconds()
+			row.EstimateSeconds = &estimate
+		}
+
+		terminal := status == "completed" || status == "cancelled"
+		if t := times[id]; t != nil {
+			if !t.started.IsZero() {
+				row.StartedAt = formatProgressTimestamp(t.started)
+			}
+			if terminal && !t.finished.IsZero() {
+				row.FinishedAt = formatProgressTimestamp(t.finished)
+				if !t.started.IsZero() && !t.finished.Before(t.started) {
+					actual := t.finished.Sub(t.started).Seconds()
+					row.ActualSeconds = &actual
+					if estimate > 0 {
+						ratio := actual / estimate
+						row.ActualToEstimate = &ratio
+						report.Totals.ActualSeconds += actual
+						report.Totals.EstimatedActualSeconds += estimate
+					}
+				}
+			}
+		}
+		report.Tasks = append(report.Tasks, row)
+
+		if status == "cancelled" {
+			continue
+		}
+		report.Totals.Tasks++
+		report.Totals.EstimateSeconds += estimate
+		remaining++
+		remainingEstimate += estimate
+		if status == "completed" && row.FinishedAt != "" {
+			report.Totals.Finished++
+			finishes = append(finishes, finish{id: id, at: times[id].finished, estimate: estimate})
+		}
+	}
+
+	for _, replayID := range runOrder {
+		run := runs[replayID]
+		run.TasksCompleted = len(runCompleted[replayID])
+		firstTS, _ := time.Parse(time.RFC3339Nano, run.FirstEntry)
+		lastTS, _ := time.Parse(time.RFC3339Nano, run.LastEntry)
+		run.SpanSeconds = lastTS.Sub(firstTS).Seconds()
+		if run.SpanSeconds > 0 {
+			perHour := float64(run.TasksCompleted) / (run.SpanSeconds / 3600)
+			run.TasksPerHour = &perHour
+		}
+		report.Runs = append(report.Runs, *run)
+	}
+
+	if !first.IsZero() {
+		sort.SliceStable(finishes, func(i, j int) bool { return finishes[i].at.Before(finishes[j].at) })
+		report.Burndown = append(report.Burndown, burndownPoint{
+			Timestamp:                formatProgressTimestamp(first),
+			RemainingTasks:           remaining,
+			RemainingEstimateSeconds: remainingEstimate,
+		})
+		for _, f := range finishes {
+			remaining--
+			remainingEstimate -= f.estimate
+			report.Burndown = append(report.Burndown, burndownPoint{
+				Timestamp:                formatProgressTimestamp(f.at),
+				TaskID:                   f.id,
+				RemainingTasks:           remaining,
+				RemainingEstimateSeconds: remainingEstimate,
+			})
+		}
+	}
+	return report
+}
+
+func formatVelocityText(report *velocityReport, section string) string {
+	var buffer bytes.Buffer
+	writer := tabwriter.NewWriter(&buffer, 0, 4, 2, ' ', 0)
+	show := func(name string) bool { return section == "all" || section == name }
+
+	if show("tasks") {
+		_, _ = fmt.Fprintln(writer, "task\tstatus\testimate\tactual\tactual/estimate\ttitle")
+		for _, task := range report.Tasks {
+			_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				task.TaskID,
+				task.Status,
+				dashIfEmpty(task.Estimate),
+				formatVelocitySeconds(task.ActualSeconds),
+				formatVelocityRatio(task.ActualToEstimate),
+				task.Title,
+			)
+		}
+		_ = writer.Flush()
+		totals := report.Totals
+		fmt.Fprintf(&buffer, "\n%d of %d tasks finished; estimated %s in total", totals.Finished, totals.Tasks, formatVelocityDuration(totals.EstimateSeconds))
+		if totals.EstimatedActualSeconds > 0 {
+			fmt.Fprintf(&buffer, "; estimated tasks took %s against %s (%.2fx)",
+				formatVelocityDuration(totals.ActualSeconds),
+				formatVelocityDuration(totals.EstimatedActualSeconds),
+				totals.ActualSeconds/totals.EstimatedActualSeconds)
+		}
+		fmt.Fprintln(&buffer)
+	}
+
+	if show("runs") {
+		if section == "all" {
+			fmt.Fprintln(&buffer)
+		}
+		if len(report.Runs) == 0 {
+			fmt.Fprintln(&buffer, "no runs recorded")
+		} else {
+			_, _ = fmt.Fprintln(writer, "replayId\tfirst entry\tentries\tcompleted\tspan\ttasks/hour")
+			for _, run := range report.Runs {
+				perHour := "-"
+				if run.TasksPerHour != nil {
+					perHour = fmt.Sprintf("%.2f", *run.TasksPerHour)
+				}
+				_, _ = fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%s\t%s\n",
+					shortID(run.ReplayID, 8),
+					formatTimestamp(run.FirstEntry),
+					run.Entries,
+					run.TasksCompleted,
+					formatVelocityDuration(run.SpanSeconds),
+					perHour,
+				)
+			}
+			_ = writer.Flush()
+		}
+	}
+
+	if show("burndown") {
+		if section == "all" {
+			fmt.Fprintln(&buffer)
+		}
+		if len(report.Burndown) == 0 {
+			fmt.Fprintln(&buffer, "no progress recorded")
+		} else {
+			_, _ = fmt.Fprintln(writer, "timestamp\ttask\tremaining tasks\tremaining estimate")
+			for _, point := range report.Burndown {
+				_, _ = fmt.Fprintf(writer, "%s\t%s\t%d\t%s\n",
+					formatTimestamp(point.Timestamp),
+					dashIfEmpty(point.TaskID),
+					point.RemainingTasks,
+					formatVelocityDuration(point.RemainingEstimateSeconds),
+				)
+			}
+			_ = writer.Flush()
+		}
+	}
+	return buffer.String()
+}
+
+// writeVelocityCSV writes one section with raw seconds so spreadsheets can chart it.
+func writeVelocityCSV(w io.Writer, report *velocityReport, section string) error {
+	writer := csv.NewWriter(w)
+	var rows [][]string
+	switch section {
+	case "tasks":
+		rows = append(rows, []string{"task_id", "title", "status", "estimate_seconds", "started_at", "finished_at", "actual_seconds", "actual_to_estimate"})
+		for _, task := range report.Tasks {
+			rows = append(rows, []string{
+				task.TaskID, task.Title, task.Status,
+				csvFloat(task.EstimateSeconds), task.StartedAt, task.FinishedAt,
+				csvFloat(task.ActualSeconds), csvFloat(task.ActualToEstimate),
+			})
+		}
+	case "runs":
+		rows = append(rows, []string{"replayId", "first_entry", "last_entry", "entries", "tasks_completed", "span_seconds", "tasks_per_hour"})
+		for _, run := range report.Runs {
+			span := run.SpanSeconds
+			rows = append(rows, []string{
+				run.ReplayID, run.FirstEntry, run.LastEntry,
+				strconv.Itoa(run.Entries), strconv.Itoa(run.TasksCompleted),
+				csvFloat(&span), csvFloat(run.TasksPerHour),
+			})
+		}
+	case "burndown":
+		rows = append(rows, []string{"timestamp", "task_id", "remaining_tasks", "remaining_estimate_seconds"})
+		for _, point := range report.Burndown {
+			remaining := point.RemainingEstimateSeconds
+			rows = append(rows, []string{point.Timestamp, point.TaskID, strconv.Itoa(point.RemainingTasks), csvFloat(&remaining)})
+		}
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+func csvFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+func formatVelocitySeconds(seconds *float64) string {
+	if seconds == nil {
+		return "-"
+	}
+	return formatVelocityDuration(*seconds)
+}
+
+func formatVelocityDuration(seconds float64) string {
+	return (time.Duration(seconds * float64(time.Second))).Round(time.Second).String()
+}
+
+func formatVelocityRatio(ratio *float64) string {
+	if ratio == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2fx", *ratio)
+}
@@ -0,0 +1,179 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func velocityTestEntries() []map[string]any {
+	return []map[string]any{
+		{"task_id": "task-1", "status": "in_progress", "timestamp": "2026-01-01T10:00:00.000000000Z", "replayId": "run-a"},
+		{"task_id": "task-1", "status": "completed", "timestamp": "2026-01-01T11:30:00.000000000Z", "replayId": "run-a"},
+		{"task_id": "task-2", "status": "in_progress", "timestamp": "2026-01-01T11:45:00.000000000Z", "replayId": "run-a"},
+		{"task_id": "task-2", "status": "blocked", "timestamp": "2026-01-01T12:00:00.000000000Z", "replayId": "run-a"},
+		{"task_id": "task-2", "status": "completed", "timestamp": "2026-01-02T09:00:00.000000000Z", "replayId": "run-b"},
+		{"task_id": "task-3", "status": "completed", "timestamp": "2026-01-02T10:00:00.000000000Z", "replayId": "run-b"},
+		{"task_id": "meta/plan", "status": "completed", "timestamp": "2026-01-02T10:00:00.000000001Z", "replayId": "run-b"},
+	}
+}
+
+func velocityTestTasks() []PlanTask {
+	return []PlanTask{
+		{ID: "task-1", Title: "Schema", Estimate: "1h", Status: "completed"},
+		{ID: "task-2", Title: "API", Estimate: "2h", Status: "completed"},
+		{ID: "task-3", Title: "Docs", Status: "completed"},
+		{ID: "task-4", Title: "Client", Estimate: "30m", Status: "pending"},
+		{ID: "task-5", Title: "Dropped", Estimate: "4h", Status: "cancelled"},
+	}
+}
+
+func TestBuildVelocityReport(t *testing.T) {
+	report := buildVelocityReport(velocityTestTasks(), velocityTestEntries())
+
+	if len(report.Tasks) != 5 {
+		t.Fatalf("expected 5 task rows, got %d", len(report.Tasks))
+	}
+	schema := report.Tasks[0]
+	if schema.ActualSeconds == nil || *schema.ActualSeconds != 5400 || schema.ActualToEstimate == nil || *schema.ActualToEstimate != 1.5 {
+		t.Fatalf("unexpected task-1 row: %+v", schema)
+	}
+	api := report.Tasks[1]
+	if api.StartedAt != "2026-01-01T11:45:00.000000000Z" || api.FinishedAt != "2026-01-02T09:00:00.000000000Z" || *api.ActualSeconds != 76500 {
+		t.Fatalf("task-2 should run from first in_progress to completion: %+v", api)
+	}
+	if docs := report.Tasks[2]; docs.ActualSeconds != nil || docs.FinishedAt == "" {
+		t.Fatalf("task-3 never started, so it has no actual duration: %+v", docs)
+	}
+	if client := report.Tasks[3]; client.FinishedAt != "" || client.ActualSeconds != nil {
+		t.Fatalf("pending task-4 should have no finish: %+v", client)
+	}
+
+	if report.Totals.Tasks != 4 || report.Totals.Finished != 3 || report.Totals.EstimateSeconds != 12600 {
+		t.Fatalf("unexpected totals: %+v", report.Totals)
+	}
+
+	if len(report.Runs) != 2 {
+		t.Fatalf("expected 2 runs, got %+v", report.Runs)
+	}
+	runA := report.Runs[0]
+	if runA.ReplayID != "run-a" || runA.Entries != 4 || runA.TasksCompleted != 1 || runA.SpanSeconds != 7200 || runA.TasksPerHour == nil || *runA.TasksPerHour != 0.5 {
+		t.Fatalf("unexpected run-a: %+v", runA)
+	}
+	if runB := report.Runs[1]; runB.TasksCompleted != 2 {
+		t.Fatalf("meta tasks should not count toward throughput: %+v", runB)
+	}
+
+	var remaining []int
+	var remainingEstimate []float64
+	for _, point := range report.Burndown {
+		remaining = append(remaining, point.RemainingTasks)
+		remainingEstimate = append(remainingEstimate, point.RemainingEstimateSeconds)
+	}
+	if !reflect.DeepEqual(remaining, []int{4, 3, 2, 1}) {
+		t.Fatalf("unexpected burn-down tasks: %v", remaining)
+	}
+	if !reflect.DeepEqual(remainingEstimate, []float64{12600, 9000, 1800, 1800}) {
+		t.Fatalf("unexpected burn-down estimates: %v", remainingEstimate)
+	}
+}
+
+func TestBuildVelocityReportIgnoresPlanEditAudits(t *testing.T) {
+	tasks := []PlanTask{{ID: "task-1", Title: "Schema", Estimate: "1h", Status: "completed"}}
+	entries := []map[string]any{
+		{"task_id": "task-1", "status": "in_progress", "timestamp": "2026-01-01T10:00:00.000000000Z", "replayId": "run-a"},
+		{"task_id": "task-1", "status": "completed", "timestamp": "2026-01-01T11:00:00.000000000Z", "replayId": "run-a"},
+		{"task_id": "task-1", "status": "completed", "timestamp": "2026-01-01T12:00:00.000000000Z", "replayId": "run-a", "notes": "apply recipe: step 2/2 (exit code 0)"},
+		{"task_id": "task-1", "status": "completed", "timestamp": "2026-01-03T09:00:00.000000000Z", "replayId": "run-b", "notes": "small plan edit"},
+	}
+	report := buildVelocityReport(tasks, entries)
+
+	row := report.Tasks[0]
+	if row.FinishedAt != "2026-01-01T11:00:00.000000000Z" || row.ActualSeconds == nil || *row.ActualSeconds != 3600 {
+		t.Fatalf("task-1 should finish at its first completion: %+v", row)
+	}
+	if len(report.Runs) != 2 || report.Runs[1].TasksCompleted != 0 {
+		t.Fatalf("a plan edit audit should not count as a completion: %+v", report.Runs)
+	}
+	last := report.Burndown[len(report.Burndown)-1]
+	if last.Timestamp != "2026-01-01T11:00:00.000000000Z" || last.RemainingTasks != 0 {
+		t.Fatalf("unexpected final burn-down point: %+v", last)
+	}
+}
+
+func TestReportVelocityCommandFormats(t *testing.T) {
+	dir := t.TempDir()
+	artifacts := defaultArtifacts()
+	artifacts["plan.small.yml"] = `small_version: "1.0.0"
+owner: "agent"
+tasks:
+  - id: "task-1"
+    title: "Schema"
+    estimate: "1h"
+    status: "completed"
+`
+	artifacts["progress.small.yml"] = `small_version: "1.0.0"
+owner: "agent"
+entries:
+  - task_id: "task-1"
+    status: "in_progress"
+    timestamp: "2026-01-01T10:00:00.000000000Z"
+    evidence: "Started"
+    replayId: "run-a"
+  - task_id: "task-1"
+    status: "completed"
+    timestamp: "2026-01-01T10:45:00.000000000Z"
+    evidence: "Done"
+    replayId: "run-a"
+`
+	writeArtifacts(t, dir, artifacts)
+	mustSaveWorkspace(t, dir, workspace.KindRepoRoot)
+
+	run := func(args ...string) (string, error) {
+		cmd := reportCmd()
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetArgs(append([]string{"velocity", "--dir", dir}, args...))
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	text, err := run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "45m0s") || !strings.Contains(text, "0.75x") || !strings.Contains(text, "1 of 1 tasks finished") {
+		t.Fatalf("unexpected text output:\n%s", text)
+	}
+
+	data, err := run("--format", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report velocityReport
+	if err := json.Unmarshal([]byte(data), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+	if len(report.Burndown) != 2 || report.Burndown[1].RemainingTasks != 0 {
+		t.Fatalf("unexpected burn-down: %+v", report.Burndown)
+	}
+
+	csvOut, err := run("--format", "csv", "--section", "burndown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "timestamp,task_id,remaining_tasks,remaining_estimate_seconds\n" +
+		"2026-01-01T10:00:00.000000000Z,,1,3600\n" +
+		"2026-01-01T10:45:00.000000000Z,task-1,0,0\n"
+	if csvOut != want {
+		t.Fatalf("unexpected CSV:\n%s", csvOut)
+	}
+
+	if _, err := run("--format", "csv"); err == nil {
+		t.Fatal("expected --format csv without --section to fail")
+	}
+}
@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/spf13/cobra"
+)
+
+// RevisionConflictError is returned when --if-match names a revision the artifact no
+// longer has: another writer changed it after the caller read it.
+type RevisionConflictError struct {
+	Artifact string
+	Expected string
+	Actual   string
+}
+
+func (e *RevisionConflictError) Error() string {
+	actual := e.Actual
+	if actual == "" {
+		actual = "none (file missing)"
+	}
+	return fmt.Sprintf("%s changed since it was read: --if-match %s, current revision %s; re-read it (small emit --include revisions) and retry",
+		e.Artifact, e.Expected, actual)
+}
+
+// ExitCode reports the process exit code for a revision conflict.
+func (e *RevisionConflictError) ExitCode() int {
+	return ExitConflict
+}
+
+// ExitCodeForError maps an error returned by Execute to a process exit code. Revision
+// conflicts exit with ExitConflict; everything else, including a wrapped failure of a
+// child process, exits 1.
+func ExitCodeForError(err error) int {
+	var conflict *RevisionConflictError
+	if errors.As(err, &conflict) {
+		return conflict.ExitCode()
+	}
+	return ExitInvalid
+}
+
+// artifactRevision returns the sha256 of a canonical artifact's bytes, or "" when the
+// artifact does not exist. The progress revision also covers the JSON Lines ledger, so
+// it changes with every appended entry.
+func artifactRevision(baseDir, filename string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(baseDir, small.SmallDir, filename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	hash := sha256.New()
+	hash.Write(data)
+	if filename == "progress.small.yml" && small.ProgressLedgerEnabled(baseDir) {
+		ledger, err := os.ReadFile(small.ProgressLedgerPath(baseDir))
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", small.ProgressLedgerFileName, err)
+		}
+		hash.Write(ledger)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// normalizeRevision accepts a bare or "sha256:"-prefixed hex digest in either case.
+func normalizeRevision(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	return strings.TrimPrefix(value, "sha256:")
+}
+
+// lockArtifactRevision takes the workspace lock and, when ifMatch is set, checks that
+// filename still has the revision it names. Callers take it before reading the artifacts
+// they update and hold it until their write is done, so no other writer can change them
+// in between.
+func lockArtifactRevision(baseDir, filename, ifMatch string) (func(), error) {
+	unlock, err := small.LockWorkspace(baseDir)
+	if err != nil {
+		return nil, err
+	}
+	expected := normalizeRevision(ifMatch)
+	if expected == "" {
+		return unlock, nil
+	}
+	actual, err := artifactRevision(baseDir, filename)
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+	if actual != expected {
+		unlock()
+		return nil, &RevisionConflictError{Artifact: filename, Expected: expected, Actual: actual}
+	}
+	return unlock, nil
+}
+
+func registerIfMatchFlag(cmd *cobra.Command, target *string, filename string) {
+	cmd.Flags().StringVar(target, "if-match", "", fmt.Sprintf("Only write if %s still has this sha256 revision (exit %d otherwise)", filename, ExitConflict))
+}
@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func TestPlanEditIfMatch(t *testing.T) {
+	dir := setupPlanEditWorkspace(t)
+	output, _, err := buildEmitOutput(dir, dir, emitInclude{"revisions": true}, workspace.ScopeRoot, 1, 3, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revision := output.Revisions.Plan
+	if len(revision) != 64 || output.Revisions.Progress == "" {
+		t.Fatalf("expected emit to report artifact revisions, got %+v", output.Revisions)
+	}
+
+	if err := runPlanSubcommand(t, dir, "edit", "task-2", "--title", "First", "--if-match", "sha256:"+strings.ToUpper(revision)); err != nil {
+		t.Fatalf("plan edit with the current revision failed: %v", err)
+	}
+	before, err := os.ReadFile(filepath.Join(dir, small.SmallDir, "plan.small.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = runPlanSubcommand(t, dir, "edit", "task-2", "--title", "Second", "--if-match", revision)
+	var conflict *RevisionConflictError
+	if !errors.As(err, &conflict) || conflict.Artifact != "plan.small.yml" {
+		t.Fatalf("expected a revision conflict on plan.small.yml, got %v", err)
+	}
+	if code := ExitCodeForError(err); code != ExitConflict {
+		t.Fatalf("expected exit code %d, got %d", ExitConflict, code)
+	}
+	after, err := os.ReadFile(filepath.Join(dir, small.SmallDir, "plan.small.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Fatal("expected a conflicting write to leave plan.small.yml untouched")
+	}
+}
+
+func TestCheckpointIfMatchRejectsStaleRevision(t *testing.T) {
+	setAgentFlag(t, "")
+	dir := setupPlanEditWorkspace(t)
+	progressBefore, err := artifactRevision(dir, "progress.small.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := checkpointCmd()
+	cmd.SetArgs([]string{"--dir", dir, "--task", "task-1", "--status", "completed", "--evidence", "done", "--if-match", strings.Repeat("0", 64)})
+	if err := cmd.Execute(); ExitCodeForError(err) != ExitConflict {
+		t.Fatalf("expected a revision conflict, got %v", err)
+	}
+	if progressAfter, _ := artifactRevision(dir, "progress.small.yml"); progressAfter != progressBefore {
+		t.Fatal("expected a conflicting checkpoint to leave progress.small.yml untouched")
+	}
+	if code := ExitCodeForError(errors.New("other failure")); code != ExitInvalid {
+		t.Fatalf("expected ordinary errors to exit %d, got %d", ExitInvalid, code)
+	}
+	childErr := exec.Command("git", "--no-such-option").Run()
+	var exitErr *exec.ExitError
+	if !errors.As(childErr, &exitErr) {
+		t.Fatalf("expected an *exec.ExitError from git, got %v", childErr)
+	}
+	if code := ExitCodeForError(fmt.Errorf("git failed: %w", childErr)); code != ExitInvalid {
+		t.Fatalf("expected a failed child process to exit %d, got %d", ExitInvalid, code)
+	}
+}
@@ -49,6 +49,8 @@ func newRootCmd() *cobra.Command {
 
 	rootCmd.PersistentFlags().BoolVar(&outputNoColor, "no-color", false, "Disable ANSI color output")
 	rootCmd.PersistentFlags().BoolVar(&outputQuiet, "quiet", false, "Suppress non-error output")
+	rootCmd.PersistentFlags().StringVar(&agentFlag, "agent", "", "Agent id recorded on progress entries and handoffs and checked against task leases (default $SMALL_AGENT_ID)")
+	rootCmd.PersistentFlags().DurationVar(&small.LockWaitTimeout, "lock-timeout", 0, "How long writers wait for .small/.lock (default $SMALL_LOCK_TIMEOUT or 10s)")
 
 	rootCmd.AddCommand(versionCmd())
 	rootCmd.AddCommand(initCmd())
@@ -72,6 +74,10 @@ func newRootCmd() *cobra.Command {
 	rootCmd.AddCommand(selftestCmd())
 	rootCmd.AddCommand(archiveCmd())
 	rootCmd.AddCommand(runCmd())
+	rootCmd.AddCommand(runPlanCmd())
+	rootCmd.AddCommand(reportCmd())
+	rootCmd.AddCommand(logsCmd())
+	rootCmd.AddCommand(lockCmd())
 	rootCmd.AddCommand(agentsCmd())
 
 	return rootCmd
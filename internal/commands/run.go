@@ -62,28 +62,48 @@ func runCmd() *cobra.Command {
 
 func runSnapshotCmd(dir, storeFlag, workspaceFlag *string) *cobra.Command {
 	var force bool
+	var sign bool
+	var signingKey string
 
 	cmd := &cobra.Command{
 		Use:   "snapshot",
 		Short: "Snapshot current workspace into the run store",
+		Long: `Copies the canonical artifacts into <store>/<replayId>/ with a meta.json.
+
+--sign also writes manifest.sha256 (sha256sum format) listing every snapshot
+file, and manifest.sha256.sig, a detached ed25519 signature of the manifest in
+the OpenSSH SSHSIG format (namespace "small").`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			artifactsDir, storeDir, err := resolveRunContext(*dir, *storeFlag, *workspaceFlag)
 			if err != nil {
 				return err
 			}
+			signer, err := resolveSigningKey(sign, signingKey)
+			if err != nil {
+				return err
+			}
 
 			snapshot, err := runstore.WriteSnapshot(artifactsDir, storeDir, force)
 			if err != nil {
 				return err
 			}
+			if signer != nil {
+				if err := runstore.SignSnapshot(snapshot.Dir, signer); err != nil {
+					return err
+				}
+			}
 
 			fmt.Printf("Snapshot saved: %s\n", snapshot.Dir)
 			fmt.Printf("replayId: %s\n", snapshot.ReplayID)
+			if signer != nil {
+				fmt.Printf("Signed with %s\n", signingKeyFingerprint(signer))
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVar(&force, "force", false, "Overwrite existing snapshot directory")
+	registerSigningFlags(cmd, &sign, &signingKey, "the snapshot manifest")
 	return cmd
 }
 
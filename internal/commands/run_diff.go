@@ -12,7 +12,6 @@ import (
 
 	"github.com/justyn-clark/small-protocol/internal/runstore"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 type runFileDiff struct {
@@ -219,20 +218,13 @@ func diffProgress(fromDir, toDir string, full bool) (runProgressDiff, error) {
 }
 
 func loadProgressEntries(path string) ([]map[string]any, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return []map[string]any{}, nil
-		}
-		return nil, fmt.Errorf("failed to read progress.small.yml: %w", err)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return []map[string]any{}, nil
 	}
 
-	var progress ProgressData
-	if err := yaml.Unmarshal(data, &progress); err != nil {
-		return nil, fmt.Errorf("failed to parse progress.small.yml: %w", err)
-	}
-	if progress.Entries == nil {
-		return []map[string]any{}, nil
+	progress, err := loadProgressData(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read progress.small.yml: %w", err)
 	}
 	return progress.Entries, nil
 }
@@ -0,0 +1,340 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+	"github.com/spf13/cobra"
+)
+
+// runPlanOptions configures a run-plan execution.
+type runPlanOptions struct {
+	Parallel  int
+	KeepGoing bool
+	// Recipe holds the apply options shared by every task; Stdout and Stderr are
+	// replaced by per-task prefixed writers.
+	Recipe applyRecipeOptions
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// runPlanReport summarises which tasks ran and why the rest did not.
+type runPlanReport struct {
+	Completed []string
+	Failed    []string
+	// NotRun maps pending tasks that never started to the reason.
+	NotRun      map[string]string
+	NotRunOrder []string
+	Interrupted bool
+}
+
+type runPlanResult struct {
+	TaskID  string
+	Outcome applyRecipeOutcome
+	Err     error
+}
+
+func runPlanCmd() *cobra.Command {
+	var (
+		parallel      int
+		keepGoing     bool
+		dryRun        bool
+		timeout       time.Duration
+		killGrace     time.Duration
+		skipScope     bool
+		dir           string
+		workspaceFlag string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "run-plan",
+		Short: "Run plan task recipes in dependency order",
+		Long: `Runs the run recipe of every pending task whose dependencies are completed,
+following the plan's dependency graph until no runnable task is left.
+
+Up to --parallel tasks run at once. Progress entries from concurrent tasks are
+appended one at a time, and task output is prefixed with the task id. By default
+the first failing task stops new tasks from starting (tasks already running
+finish); --keep-going keeps running every task that does not depend on a failure.
+
+Tasks without a run recipe are never started, so their dependents wait too.
+Touched paths are hashed per step across the whole workspace, so with
+--parallel above 1 a step may also record files written by a concurrent task.
+For the same reason a step cannot be blamed for a scope violation, and
+--parallel above 1 requires --skip-scope-check when the intent declares a scope.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if dir == "" {
+				dir = baseDir
+			}
+			artifactsDir := resolveArtifactsDir(dir)
+
+			scope, err := workspace.ParseScope(workspaceFlag)
+			if err != nil {
+				return err
+			}
+			if scope == workspace.ScopeExamples {
+				return fmt.Errorf("--workspace examples is not supported for run-plan (use --workspace any to bypass)")
+			}
+			if scope != workspace.ScopeAny {
+				if err := enforceWorkspaceScope(artifactsDir, workspace.ScopeRoot); err != nil {
+					return err
+				}
+			}
+			if parallel < 1 {
+				return fmt.Errorf("--parallel must be at least 1")
+			}
+			if timeout < 0 || killGrace < 0 {
+				return fmt.Errorf("--timeout and --kill-grace must be non-negative")
+			}
+			if parallel > 1 && !skipScope {
+				_, hasScope, err := small.LoadIntentScope(artifactsDir)
+				if err != nil {
+					return fmt.Errorf("failed to read intent scope: %w", err)
+				}
+				if hasScope {
+					return fmt.Errorf("--parallel above 1 cannot attribute scope violations to a task, since concurrent steps share the workspace; pass --skip-scope-check or use --parallel 1")
+				}
+			}
+
+			plan, err := loadPlan(filepath.Join(artifactsDir, small.SmallDir, "plan.small.yml"))
+			if err != nil {
+				return fmt.Errorf("failed to load plan.small.yml: %w", err)
+			}
+			if err := validatePlanDependencyGraph(plan.Tasks); err != nil {
+				return err
+			}
+
+			if dryRun {
+				printRunPlanSchedule(os.Stdout, plan.Tasks)
+				return nil
+			}
+
+			if _, err := ensureWorkspaceRunReplayID(artifactsDir); err != nil {
+				return err
+			}
+			report := runPlanTasks(artifactsDir, plan.Tasks, runPlanOptions{
+				Parallel:  parallel,
+				KeepGoing: keepGoing,
+				Recipe: applyRecipeOptions{
+					Timeout:   timeout,
+					KillGrace: killGrace,
+					SkipScope: skipScope,
+				},
+				Stdout: os.Stdout,
+				Stderr: os.Stderr,
+			})
+			printRunPlanReport(os.Stdout, report)
+			if len(report.Failed) > 0 {
+				return fmt.Errorf("run-plan: %d task(s) failed: %s", len(report.Failed), strings.Join(report.Failed, ", "))
+			}
+			if report.Interrupted {
+				return fmt.Errorf("run-plan interrupted")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&parallel, "parallel", 1, "Number of tasks to run at once")
+	cmd.Flags().BoolVar(&keepGoing, "keep-going", false, "Keep starting independent tasks after a task fails")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the order tasks would run in, assuming every task succeeds")
+	cmd.Flags().DurationVar(&timeout, "timeout", 0, "Default per-step timeout for recipes that declare none (0 disables)")
+	cmd.Flags().DurationVar(&killGrace, "kill-grace", defaultApplyKillGrace, "Grace period between SIGTERM and SIGKILL when stopping a step")
+	cmd.Flags().BoolVar(&skipScope, "skip-scope-check", false, "Record touched paths without enforcing intent scope")
+	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
+	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root or any)")
+
+	return cmd
+}
+
+// runPlanTasks schedules task recipes over the dependency graph. Statuses are tracked in
+// memory from recipe outcomes, so the plan file is only written by the recipes' checkpoints.
+func runPlanTasks(baseDir string, tasks []PlanTask, opts runPlanOptions) runPlanReport {
+	current := append([]PlanTask{}, tasks...)
+	index := make(map[string]int, len(current))
+	for i := range current {
+		current[i].ID = strings.TrimSpace(current[i].ID)
+		index[current[i].ID] = i
+	}
+
+	report := runPlanReport{NotRun: map[string]string{}}
+	started := map[string]bool{}
+	results := make(chan runPlanResult)
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	defer signal.Stop(interrupts)
+
+	var outputMu sync.Mutex
+	printf := func(w io.Writer, format string, args ...any) {
+		outputMu.Lock()
+		defer outputMu.Unlock()
+		fmt.Fprintf(w, format, args...)
+	}
+	running := 0
+	stopped := false
+	for {
+		if !stopped {
+			for _, id := range nextActionableTaskIDs(current, 0) {
+				if running >= opts.Parallel {
+					break
+				}
+				task := current[index[id]]
+				if started[id] || task.Run == nil || len(task.Run.Commands) == 0 {
+					continue
+				}
+				started[id] = true
+				running++
+				current[index[id]].Status = "in_progress"
+				rollUpPlanStatuses(current)
+				printf(opts.Stdout, "Starting %s: %s\n", id, task.Title)
+				go runPlanTask(baseDir, id, task.Run, opts, &outputMu, results)
+			}
+		}
+		if running == 0 {
+			break
+		}
+
+		select {
+		case result := <-results:
+			running--
+			if result.Err == nil && result.Outcome.completed() {
+				current[index[result.TaskID]].Status = "completed"
+				rollUpPlanStatuses(current)
+				report.Completed = append(report.Completed, result.TaskID)
+				printf(opts.Stdout, "Finished %s: %s\n", result.TaskID, result.Outcome.Summary)
+				continue
+			}
+			current[index[result.TaskID]].Status = "blocked"
+			rollUpPlanStatuses(current)
+			report.Failed = append(report.Failed, result.TaskID)
+			if result.Err != nil {
+				printf(opts.Stderr, "Failed %s: %v\n", result.TaskID, result.Err)
+			} else {
+				printf(opts.Stderr, "Failed %s: %s\n", result.TaskID, result.Outcome.Summary)
+			}
+			if !opts.KeepGoing {
+				stopped = true
+			}
+		case <-interrupts:
+			stopped = true
+			report.Interrupted = true
+		}
+	}
+
+	children := planChildren(current)
+	deps := planEffectiveDependencies(current)
+	state := newPlanDependencyState(current)
+	for _, task := range current {
+		if normalizePlanStatus(task.Status) != "pending" || len(children[task.ID]) > 0 {
+			continue
+		}
+		report.NotRunOrder = append(report.NotRunOrder, task.ID)
+		report.NotRun[task.ID] = runPlanNotRunReason(task, state.unsatisfied(deps[task.ID]), stopped)
+	}
+	return report
+}
+
+func runPlanTask(baseDir, taskID string, run *PlanTaskRun, opts runPlanOptions, outputMu *sync.Mutex, results chan<- runPlanResult) {
+	stdout := &prefixedLineWriter{mu: outputMu, w: opts.Stdout, prefix: "[" + taskID + "] "}
+	stderr := &prefixedLineWriter{mu: outputMu, w: opts.Stderr, prefix: "[" + taskID + "] "}
+	recipeOpts := opts.Recipe
+	recipeOpts.Stdout = stdout
+	recipeOpts.Stderr = stderr
+	outcome, err := runApplyRecipe(baseDir, taskID, run, recipeOpts)
+	stdout.flush()
+	stderr.flush()
+	results <- runPlanResult{TaskID: taskID, Outcome: outcome, Err: err}
+}
+
+func runPlanNotRunReason(task PlanTask, waiting []string, stopped bool) string {
+	switch {
+	case len(waiting) > 0:
+		return "waiting on " + strings.Join(waiting, ", ")
+	case task.Lease.live(time.Now()):
+		return fmt.Sprintf("leased to %s until %s", task.Lease.AgentID, task.Lease.Expires)
+	case task.Run == nil || len(task.Run.Commands) == 0:
+		return "no run recipe"
+	case stopped:
+		return "not started after a failure or interrupt"
+	default:
+		return "not runnable"
+	}
+}
+
+func printRunPlanReport(w io.Writer, report runPlanReport) {
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Completed: %d, failed: %d, not run: %d\n", len(report.Completed), len(report.Failed), len(report.NotRunOrder))
+	for _, id := range report.NotRunOrder {
+		fmt.Fprintf(w, "  %s: %s\n", id, report.NotRun[id])
+	}
+}
+
+// printRunPlanSchedule prints the waves tasks would start in if every recipe succeeded.
+func printRunPlanSchedule(w io.Writer, tasks []PlanTask) {
+	current := append([]PlanTask{}, tasks...)
+	wave := 0
+	for {
+		var runnable []string
+		for _, id := range nextActionableTaskIDs(current, 0) {
+			for i := range current {
+				if strings.TrimSpace(current[i].ID) == id && current[i].Run != nil && len(current[i].Run.Commands) > 0 {
+					runnable = append(runnable, id)
+					current[i].Status = "completed"
+				}
+			}
+		}
+		rollUpPlanStatuses(current)
+		if len(runnable) == 0 {
+			break
+		}
+		wave++
+		fmt.Fprintf(w, "Wave %d: %s\n", wave, strings.Join(runnable, ", "))
+	}
+	if wave == 0 {
+		fmt.Fprintln(w, "No runnable tasks (pending tasks need a run recipe and completed dependencies)")
+	}
+}
+
+// prefixedLineWriter writes whole lines to w with a prefix. Lines from concurrent tasks
+// share mu so they never interleave mid-line.
+type prefixedLineWriter struct {
+	mu      *sync.Mutex
+	w       io.Writer
+	prefix  string
+	pending []byte
+}
+
+func (p *prefixedLineWriter) Write(data []byte) (int, error) {
+	p.pending = append(p.pending, data...)
+	for {
+		i := bytes.IndexByte(p.pending, '\n')
+		if i < 0 {
+			return len(data), nil
+		}
+		p.emit(p.pending[:i+1])
+		p.pending = p.pending[i+1:]
+	}
+}
+
+func (p *prefixedLineWriter) flush() {
+	if len(p.pending) > 0 {
+		p.emit(append(p.pending, '\n'))
+		p.pending = nil
+	}
+}
+
+func (p *prefixedLineWriter) emit(line []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, _ = io.WriteString(p.w, p.prefix)
+	_, _ = p.w.Write(line)
+}
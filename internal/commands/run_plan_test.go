@@ -0,0 +1,190 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func writeRunPlanArtifacts(t *testing.T, dir, tasks string) *PlanData {
+	t.Helper()
+	artifacts := defaultArtifacts()
+	artifacts["plan.small.yml"] = "small_version: \"1.0.0\"\nowner: \"agent\"\ntasks:\n" + tasks
+	writeArtifacts(t, dir, artifacts)
+	mustSaveWorkspace(t, dir, workspace.KindRepoRoot)
+	if _, err := ensureWorkspaceRunReplayID(dir); err != nil {
+		t.Fatal(err)
+	}
+	plan, err := loadPlan(filepath.Join(dir, small.SmallDir, "plan.small.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return plan
+}
+
+func TestRunPlanTasksRunsIndependentTasksInParallel(t *testing.T) {
+	tmpDir := t.TempDir()
+	// task-2 and task-3 each wait for the other to start, so they only pass when run together.
+	plan := writeRunPlanArtifacts(t, tmpDir, `  - id: "task-1"
+    title: "Setup"
+    run:
+      commands: ["echo setup"]
+  - id: "task-2"
+    title: "Left"
+    dependencies: ["task-1"]
+    run:
+      commands: ["touch left.start; for i in $(seq 50); do [ -f right.start ] && exit 0; sleep 0.1; done; exit 1"]
+  - id: "task-3"
+    title: "Right"
+    dependencies: ["task-1"]
+    run:
+      commands: ["touch right.start; for i in $(seq 50); do [ -f left.start ] && exit 0; sleep 0.1; done; exit 1"]
+  - id: "task-4"
+    title: "Join"
+    dependencies: ["task-2", "task-3"]
+    run:
+      commands: ["echo join"]
+  - id: "task-5"
+    title: "Manual"
+`)
+
+	var stdout, stderr bytes.Buffer
+	report := runPlanTasks(tmpDir, plan.Tasks, runPlanOptions{Parallel: 2, Stdout: &stdout, Stderr: &stderr})
+	if len(report.Failed) != 0 || len(report.Completed) != 4 {
+		t.Fatalf("unexpected report: %+v\nstderr:\n%s", report, stderr.String())
+	}
+	if report.Completed[0] != "task-1" || report.Completed[3] != "task-4" {
+		t.Fatalf("expected dependency order, got %v", report.Completed)
+	}
+	if !reflect.DeepEqual(report.NotRunOrder, []string{"task-5"}) || report.NotRun["task-5"] != "no run recipe" {
+		t.Fatalf("unexpected not-run tasks: %+v", report.NotRun)
+	}
+	if !strings.Contains(stdout.String(), "[task-4] join\n") {
+		t.Fatalf("expected prefixed task output, got:\n%s", stdout.String())
+	}
+
+	updated, err := loadPlan(filepath.Join(tmpDir, small.SmallDir, "plan.small.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, task := range updated.Tasks[:4] {
+		if task.Status != "completed" {
+			t.Fatalf("task %s status = %q, want completed", task.ID, task.Status)
+		}
+	}
+	if code := runVerify(tmpDir, false, true, workspace.ScopeAny); code != ExitValid {
+		t.Fatalf("expected artifacts to verify after parallel run, got exit code %d", code)
+	}
+}
+
+func TestRunPlanTasksFailurePolicies(t *testing.T) {
+	tasks := `  - id: "task-1"
+    title: "Broken"
+    run:
+      commands: ["exit 1"]
+  - id: "task-2"
+    title: "Independent"
+    run:
+      commands: ["true"]
+  - id: "task-3"
+    title: "Dependent"
+    dependencies: ["task-1"]
+    run:
+      commands: ["true"]
+`
+	var out bytes.Buffer
+
+	dir := t.TempDir()
+	plan := writeRunPlanArtifacts(t, dir, tasks)
+	report := runPlanTasks(dir, plan.Tasks, runPlanOptions{Parallel: 1, Stdout: &out, Stderr: &out})
+	if !reflect.DeepEqual(report.Failed, []string{"task-1"}) || len(report.Completed) != 0 {
+		t.Fatalf("fail-fast report = %+v", report)
+	}
+	if report.NotRun["task-2"] != "not started after a failure or interrupt" || report.NotRun["task-3"] != "waiting on task-1" {
+		t.Fatalf("fail-fast not-run reasons = %+v", report.NotRun)
+	}
+
+	dir = t.TempDir()
+	plan = writeRunPlanArtifacts(t, dir, tasks)
+	report = runPlanTasks(dir, plan.Tasks, runPlanOptions{Parallel: 1, KeepGoing: true, Stdout: &out, Stderr: &out})
+	if !reflect.DeepEqual(report.Failed, []string{"task-1"}) || !reflect.DeepEqual(report.Completed, []string{"task-2"}) {
+		t.Fatalf("keep-going report = %+v", report)
+	}
+	if !reflect.DeepEqual(report.NotRunOrder, []string{"task-3"}) {
+		t.Fatalf("keep-going not run = %v", report.NotRunOrder)
+	}
+}
+
+func TestRunPlanParallelRequiresSkipScopeCheckWithScope(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeRunPlanArtifacts(t, tmpDir, `  - id: "task-1"
+    title: "Left"
+    run:
+      commands: ["true"]
+  - id: "task-2"
+    title: "Right"
+    run:
+      commands: ["true"]
+`)
+	intent := `small_version: "1.0.0"
+owner: "human"
+intent: "Test intent"
+scope:
+  include:
+    - "src/"
+  exclude: []
+success_criteria: []
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, small.SmallDir, "intent.small.yml"), []byte(intent), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) error {
+		cmd := runPlanCmd()
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		cmd.SetArgs(append([]string{"--dir", tmpDir, "--workspace", "any"}, args...))
+		return cmd.Execute()
+	}
+	if err := run("--parallel", "2"); err == nil || !strings.Contains(err.Error(), "--skip-scope-check") {
+		t.Fatalf("expected --parallel 2 to require --skip-scope-check, got %v", err)
+	}
+	if err := run("--parallel", "2", "--skip-scope-check"); err != nil {
+		t.Fatalf("run-plan --parallel 2 --skip-scope-check failed: %v", err)
+	}
+}
+
+func TestPrintRunPlanSchedule(t *testing.T) {
+	run := &PlanTaskRun{Commands: []PlanRunCommand{{Cmd: "true"}}}
+	tasks := []PlanTask{
+		{ID: "task-1", Title: "A", Run: run},
+		{ID: "task-2", Title: "B", Run: run, Dependencies: []string{"task-1"}},
+		{ID: "task-3", Title: "C", Run: run, Dependencies: []string{"task-1"}},
+		{ID: "task-4", Title: "D", Dependencies: []string{"task-2"}},
+		{ID: "task-5", Title: "E", Status: "completed", Run: run},
+	}
+	var out bytes.Buffer
+	printRunPlanSchedule(&out, tasks)
+	if want := "Wave 1: task-1\nWave 2: task-2, task-3\n"; out.String() != want {
+		t.Fatalf("schedule = %q, want %q", out.String(), want)
+	}
+}
+
+func TestPrefixedLineWriter(t *testing.T) {
+	var mu sync.Mutex
+	var out bytes.Buffer
+	w := &prefixedLineWriter{mu: &mu, w: &out, prefix: "[t] "}
+	_, _ = w.Write([]byte("one\ntw"))
+	_, _ = w.Write([]byte("o\nthree"))
+	w.flush()
+	if want := "[t] one\n[t] two\n[t] three\n"; out.String() != want {
+		t.Fatalf("output = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+)
+
+// scopeDriftReport lists paths changed since a git base ref and the subset that
+// falls outside intent scope.
+type scopeDriftReport struct {
+	Base         string                 `json:"base"`
+	ChangedPaths []string               `json:"changed_paths"`
+	Violations   []small.ScopeViolation `json:"violations,omitempty"`
+}
+
+func evaluateScopeDrift(artifactsDir string, scope small.IntentScope, base string) (scopeDriftReport, error) {
+	changed, err := small.ChangedPathsSince(artifactsDir, base)
+	if err != nil {
+		return scopeDriftReport{}, err
+	}
+	if changed == nil {
+		changed = []string{}
+	}
+	return scopeDriftReport{
+		Base:         base,
+		ChangedPaths: changed,
+		Violations:   scope.Violations(changed),
+	}, nil
+}
+
+func scopeDriftVerifyErrors(report scopeDriftReport) []verifyError {
+	errors := make([]verifyError, 0, len(report.Violations))
+	for _, violation := range report.Violations {
+		errors = append(errors, verifyError{
+			message: fmt.Sprintf("Scope drift since %s: %s", report.Base, describeScopeViolation(violation)),
+			fix:     "Revert the change, or have a human update scope in intent.small.yml",
+		})
+	}
+	return errors
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+const scopedIntent = `small_version: "1.0.0"
+owner: "human"
+intent: "Test intent"
+scope:
+  include:
+    - "src/"
+  exclude:
+    - "src/generated/"
+success_criteria: []
+`
+
+func mustGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	full := append([]string{"-c", "user.email=test@example.com", "-c", "user.name=test", "-c", "commit.gpgsign=false"}, args...)
+	cmd := exec.Command("git", full...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, output)
+	}
+}
+
+func setupScopeDriftRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	tmpDir := t.TempDir()
+	artifacts := cloneArtifacts(defaultArtifacts())
+	artifacts["intent.small.yml"] = scopedIntent
+	writeArtifacts(t, tmpDir, artifacts)
+	mustSaveWorkspace(t, tmpDir, workspace.KindRepoRoot)
+	if err := os.MkdirAll(filepath.Join(tmpDir, "src"), 0o755); err != nil {
+		t.Fatalf("failed to create src: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "src", "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	mustGit(t, tmpDir, "init", "-q")
+	mustGit(t, tmpDir, "add", "-A")
+	mustGit(t, tmpDir, "commit", "-q", "-m", "base")
+	return tmpDir
+}
+
+func TestVerifyScopeBase(t *testing.T) {
+	t.Run("in-scope changes pass", func(t *testing.T) {
+		tmpDir := setupScopeDriftRepo(t)
+		if err := os.WriteFile(filepath.Join(tmpDir, "src", "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+			t.Fatalf("failed to modify file: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, ".small", "progress.small.yml"), []byte(defaultArtifacts()["progress.small.yml"]), 0o644); err != nil {
+			t.Fatalf("failed to touch progress: %v", err)
+		}
+
+		code := runVerifyWithOptions(tmpDir, verifyOptions{ci: true, scope: workspace.ScopeRoot, scopeBase: "HEAD"})
+		if code != ExitValid {
+			t.Fatalf("expected ExitValid, got %d", code)
+		}
+	})
+
+	t.Run("out-of-scope and excluded changes fail", func(t *testing.T) {
+		tmpDir := setupScopeDriftRepo(t)
+		if err := os.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("drift\n"), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Join(tmpDir, "src", "generated"), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, "src", "generated", "api.go"), []byte("package generated\n"), 0o644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+
+		code := runVerifyWithOptions(tmpDir, verifyOptions{ci: true, scope: workspace.ScopeRoot, scopeBase: "HEAD"})
+		if code != ExitInvalid {
+			t.Fatalf("expected ExitInvalid, got %d", code)
+		}
+
+		code, output, err := runCheckWithOptions(tmpDir, verifyOptions{ci: true, scope: workspace.ScopeRoot, scopeBase: "HEAD"}, true, false)
+		if err != nil {
+			t.Fatalf("runCheckWithOptions error: %v", err)
+		}
+		if code != ExitInvalid {
+			t.Fatalf("expected check ExitInvalid, got %d", code)
+		}
+		if output.Scope == nil || output.Scope.Status != "failed" {
+			t.Fatalf("expected failed scope stage, got %#v", output.Scope)
+		}
+		if len(output.Scope.Violations) != 2 {
+			t.Fatalf("expected 2 violations, got %#v", output.Scope.Violations)
+		}
+		if output.Scope.Violations[0].Path != "README.md" || output.Scope.Violations[0].Reason != small.ScopeReasonOutsideInclude {
+			t.Fatalf("unexpected first violation: %#v", output.Scope.Violations[0])
+		}
+		if output.Scope.Violations[1].Reason != small.ScopeReasonExcluded {
+			t.Fatalf("unexpected second violation: %#v", output.Scope.Violations[1])
+		}
+	})
+
+	t.Run("unknown ref is a system error", func(t *testing.T) {
+		tmpDir := setupScopeDriftRepo(t)
+		code := runVerifyWithOptions(tmpDir, verifyOptions{ci: true, scope: workspace.ScopeRoot, scopeBase: "does-not-exist"})
+		if code != ExitSystemError {
+			t.Fatalf("expected ExitSystemError, got %d", code)
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/justyn-clark/small-protocol/internal/small"
 	"github.com/justyn-clark/small-protocol/internal/workspace"
 	"github.com/spf13/cobra"
 )
@@ -239,6 +240,12 @@ entries: []
 func runSelftestPlanAdd(dir string) error {
 	planPath := filepath.Join(dir, ".small", "plan.small.yml")
 
+	unlock, err := small.LockWorkspace(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// Load existing plan
 	plan, err := loadPlan(planPath)
 	if err != nil {
@@ -271,6 +278,12 @@ func runSelftestPlanAdd(dir string) error {
 func runSelftestPlanDone(dir string) error {
 	planPath := filepath.Join(dir, ".small", "plan.small.yml")
 
+	unlock, err := small.LockWorkspace(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	// Load plan
 	plan, err := loadPlan(planPath)
 	if err != nil {
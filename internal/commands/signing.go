@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/justyn-clark/small-protocol/internal/runstore"
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/spf13/cobra"
+)
+
+const signingKeyEnvVar = "SMALL_SIGNING_KEY"
+
+func registerSigningFlags(cmd *cobra.Command, sign *bool, keyPath *string, what string) {
+	cmd.Flags().BoolVar(sign, "sign", false, fmt.Sprintf("Write a detached ed25519 signature of %s", what))
+	cmd.Flags().StringVar(keyPath, "signing-key", "", fmt.Sprintf("ed25519 private key (OpenSSH or PKCS#8) used by --sign (default $%s)", signingKeyEnvVar))
+}
+
+// resolveSigningKey loads the key --sign uses, from --signing-key or SMALL_SIGNING_KEY.
+// It returns nil when signing was not requested.
+func resolveSigningKey(sign bool, keyPath string) (ed25519.PrivateKey, error) {
+	if !sign {
+		return nil, nil
+	}
+	if strings.TrimSpace(keyPath) == "" {
+		keyPath = os.Getenv(signingKeyEnvVar)
+	}
+	if strings.TrimSpace(keyPath) == "" {
+		return nil, fmt.Errorf("--sign requires --signing-key or %s", signingKeyEnvVar)
+	}
+	return small.LoadSigningKey(keyPath)
+}
+
+// signHandoff writes the detached signature of handoff.small.yml. Callers hold the
+// workspace lock from before the handoff is written, so the signature covers those bytes.
+func signHandoff(artifactsDir string, key ed25519.PrivateKey) error {
+	return small.SignFile(filepath.Join(artifactsDir, small.SmallDir, "handoff.small.yml"), key)
+}
+
+func signingKeyFingerprint(key ed25519.PrivateKey) string {
+	return small.PublicKeyFingerprint(key.Public().(ed25519.PublicKey))
+}
+
+// verifySignatures checks that handoff.small.yml is signed by a trusted key, and so is
+// the run snapshot of its replayId when one exists in the default run store.
+func verifySignatures(artifactsDir string, handoff *small.Artifact, trustedKeysPath string) ([]verifyError, error) {
+	trusted, err := small.LoadTrustedKeys(trustedKeysPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []verifyError
+	handoffPath := filepath.Join(artifactsDir, small.SmallDir, "handoff.small.yml")
+	if _, err := small.VerifyFileSignature(handoffPath, trusted); err != nil {
+		errs = append(errs, verifyError{
+			message: fmt.Sprintf("Signature [%s]: %v", small.HandoffSignatureFileName, err),
+			fix:     fmt.Sprintf("small handoff --sign --signing-key <key> --dir %q", artifactsDir),
+		})
+	}
+
+	replayID := ""
+	if handoff != nil && handoff.Data != nil {
+		if metadata, ok := handoff.Data["replayId"].(map[string]any); ok {
+			replayID = strings.TrimSpace(stringVal(metadata["value"]))
+		}
+	}
+	if replayID == "" {
+		return errs, nil
+	}
+	snapshotDir := filepath.Join(small.RunStoreDir(artifactsDir), replayID)
+	if _, err := os.Stat(snapshotDir); err != nil {
+		return errs, nil
+	}
+	if _, err := runstore.VerifySnapshotSignature(snapshotDir, trusted); err != nil {
+		errs = append(errs, verifyError{
+			message: fmt.Sprintf("Signature [run snapshot %s]: %v", shortID(replayID, 16), err),
+			fix:     fmt.Sprintf("small run snapshot --force --sign --signing-key <key> --dir %q", artifactsDir),
+		})
+	}
+	return errs, nil
+}
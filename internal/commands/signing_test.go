@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+	"github.com/justyn-clark/small-protocol/internal/workspace"
+)
+
+func TestHandoffSignAndRequireSignatures(t *testing.T) {
+	setAgentFlag(t, "")
+	dir := t.TempDir()
+	writeArtifacts(t, dir, defaultArtifacts())
+	mustSaveWorkspace(t, dir, workspace.KindRepoRoot)
+
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "signing.pem")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	trustedPath := filepath.Join(t.TempDir(), "trusted_keys")
+	if err := os.WriteFile(trustedPath, []byte(small.AuthorizedKey(pub)+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts := verifyOptions{strict: true, ci: true, scope: workspace.ScopeAny, requireSignatures: true, trustedKeys: trustedPath}
+
+	if code := runVerifyWithOptions(dir, opts); code != ExitInvalid {
+		t.Fatalf("expected an unsigned handoff to fail, got exit %d", code)
+	}
+
+	t.Setenv(signingKeyEnvVar, keyPath)
+	cmd := handoffCmd()
+	cmd.SetArgs([]string{"--dir", dir, "--sign"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("handoff --sign failed: %v", err)
+	}
+	if code := runVerifyWithOptions(dir, opts); code != ExitValid {
+		t.Fatalf("expected the signed handoff to verify, got exit %d", code)
+	}
+
+	snapshot := runCmd()
+	snapshot.SetArgs([]string{"snapshot", "--dir", dir, "--sign"})
+	if err := snapshot.Execute(); err != nil {
+		t.Fatalf("run snapshot --sign failed: %v", err)
+	}
+	if code := runVerifyWithOptions(dir, opts); code != ExitValid {
+		t.Fatalf("expected the signed snapshot to verify, got exit %d", code)
+	}
+
+	handoffPath := filepath.Join(dir, small.SmallDir, "handoff.small.yml")
+	data, err := os.ReadFile(handoffPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(handoffPath, append(data, []byte("# edited\n")...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if code := runVerifyWithOptions(dir, opts); code != ExitInvalid {
+		t.Fatalf("expected a hand-edited handoff to fail, got exit %d", code)
+	}
+
+	opts.trustedKeys = ""
+	if code := runVerifyWithOptions(dir, opts); code != ExitSystemError {
+		t.Fatalf("expected --require-signatures without --trusted-keys to be a system error, got exit %d", code)
+	}
+}
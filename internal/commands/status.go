@@ -16,19 +16,20 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-var knownPlanStatuses = []string{"pending", "in_progress", "blocked", "completed"}
+var knownPlanStatuses = []string{"pending", "in_progress", "blocked", "completed", "cancelled"}
 
 // StatusOutput represents the structured status output
 type StatusOutput struct {
-	Version        string           `json:"version"`
-	ProgressMode   string           `json:"progress_mode"`
-	SmallDirExists bool             `json:"small_dir_exists"`
-	Artifacts      ArtifactPresence `json:"artifacts"`
-	Plan           *PlanStatus      `json:"plan,omitempty"`
-	NextTask       string           `json:"next_task,omitempty"`
-	ReplayID       string           `json:"replay_id,omitempty"`
-	RecentProgress []ProgressEntry  `json:"recent_progress,omitempty"`
-	LastHandoff    string           `json:"last_handoff,omitempty"`
+	Version        string             `json:"version"`
+	ProgressMode   string             `json:"progress_mode"`
+	SmallDirExists bool               `json:"small_dir_exists"`
+	Artifacts      ArtifactPresence   `json:"artifacts"`
+	Plan           *PlanStatus        `json:"plan,omitempty"`
+	NextTask       string             `json:"next_task,omitempty"`
+	ReplayID       string             `json:"replay_id,omitempty"`
+	RecentProgress []ProgressEntry    `json:"recent_progress,omitempty"`
+	LastHandoff    string             `json:"last_handoff,omitempty"`
+	HandoffContext *handoffContextOut `json:"handoff_context,omitempty"`
 }
 
 // ArtifactPresence shows which artifacts exist
@@ -46,8 +47,19 @@ type PlanStatus struct {
 	TasksByStatus   map[string]int `json:"tasks_by_status"`
 	NextActionable  []string       `json:"next_actionable"`
 	FirstIncomplete string         `json:"first_incomplete,omitempty"`
+	// Milestones is set when any task has a milestone, in order of first appearance.
+	Milestones []MilestoneStatus `json:"milestones,omitempty"`
 }
 
+// MilestoneStatus counts the leaf tasks in a milestone. Cancelled tasks are left out.
+type MilestoneStatus struct {
+	Name      string `json:"name"`
+	Total     int    `json:"total"`
+	Completed int    `json:"completed"`
+}
+
+const milestoneBarWidth = 20
+
 // ProgressEntry represents a progress entry for status output
 type ProgressEntry struct {
 	Timestamp      string `json:"timestamp"`
@@ -58,11 +70,13 @@ type ProgressEntry struct {
 	CommandSummary string `json:"command_summary,omitempty"`
 	CommandRef     string `json:"command_ref,omitempty"`
 	CommandSha256  string `json:"command_sha256,omitempty"`
+	Agent          string `json:"agent,omitempty"`
 }
 
 type handoffStatusSnapshot struct {
 	Timestamp     string
 	CurrentTaskID string
+	Context       *handoffContextOut
 }
 
 func statusCmd() *cobra.Command {
@@ -70,6 +84,7 @@ func statusCmd() *cobra.Command {
 		jsonOutput bool
 		recent     int
 		tasks      int
+		byAgent    string
 		dir        string
 	)
 
@@ -128,7 +143,7 @@ func statusCmd() *cobra.Command {
 
 			// Load recent signal progress entries
 			if status.Artifacts.Progress {
-				entries, err := getRecentProgress(artifactsDir, recent, true)
+				entries, err := getRecentProgressByAgent(artifactsDir, recent, true, byAgent)
 				if err == nil {
 					status.RecentProgress = entries
 				}
@@ -138,6 +153,7 @@ func statusCmd() *cobra.Command {
 				handoff, err := getHandoffStatusSnapshot(artifactsDir)
 				if err == nil {
 					status.LastHandoff = handoff.Timestamp
+					status.HandoffContext = handoff.Context
 					status.NextTask = resolveNextTask(status.Plan, handoff.CurrentTaskID)
 				}
 			}
@@ -156,6 +172,8 @@ func statusCmd() *cobra.Command {
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
 	cmd.Flags().IntVar(&recent, "recent", 5, "Number of recent progress entries to show")
 	cmd.Flags().IntVar(&tasks, "tasks", 3, "Number of next actionable tasks to show")
+	// Shadows the global --agent: status writes nothing, so the flag selects whose entries to show.
+	cmd.Flags().StringVar(&byAgent, "agent", "", "Only show progress entries written by this agent id")
 	cmd.Flags().StringVar(&dir, "dir", ".", "Directory containing .small/ artifacts")
 
 	return cmd
@@ -186,10 +204,12 @@ func analyzePlan(baseDir string, maxActionable int) (*PlanStatus, error) {
 		status.TasksByStatus[name] = 0
 	}
 
+	rollUpPlanStatuses(plan.Tasks)
 	for _, task := range plan.Tasks {
 		normalizedStatus := normalizePlanStatus(task.Status)
 		status.TasksByStatus[normalizedStatus]++
 	}
+	status.Milestones = milestoneStatuses(plan.Tasks)
 	status.FirstIncomplete = preferredPlanTaskID(&plan)
 
 	status.NextActionable = nextActionableTaskIDs(plan.Tasks, maxActionable)
@@ -197,7 +217,56 @@ func analyzePlan(baseDir string, maxActionable int) (*PlanStatus, error) {
 	return status, nil
 }
 
+// milestoneStatuses counts leaf task completion per milestone; parents are skipped since
+// their status is derived from the subtasks already counted.
+func milestoneStatuses(tasks []PlanTask) []MilestoneStatus {
+	children := planChildren(tasks)
+	milestones := planTaskMilestones(tasks)
+	var out []MilestoneStatus
+	index := map[string]int{}
+	for _, task := range tasks {
+		id := strings.TrimSpace(task.ID)
+		name := milestones[id]
+		if name == "" || len(children[id]) > 0 {
+			continue
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(out)
+			index[name] = i
+			out = append(out, MilestoneStatus{Name: name})
+		}
+		switch normalizePlanStatus(task.Status) {
+		case "cancelled":
+		case "completed":
+			out[i].Completed++
+			out[i].Total++
+		default:
+			out[i].Total++
+		}
+	}
+	return out
+}
+
+// milestoneProgressBar renders completion as e.g. "[########------------] 2/5 (40%)".
+func milestoneProgressBar(m MilestoneStatus) string {
+	percent, filled := 100, milestoneBarWidth
+	if m.Total > 0 {
+		percent = m.Completed * 100 / m.Total
+		filled = m.Completed * milestoneBarWidth / m.Total
+	}
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", milestoneBarWidth-filled)
+	return fmt.Sprintf("[%s] %d/%d (%d%%)", bar, m.Completed, m.Total, percent)
+}
+
 func getRecentProgress(baseDir string, n int, signalOnly bool) ([]ProgressEntry, error) {
+	return getRecentProgressByAgent(baseDir, n, signalOnly, "")
+}
+
+// getRecentProgressByAgent is getRecentProgress limited to entries written by agent,
+// or all entries when agent is empty.
+func getRecentProgressByAgent(baseDir string, n int, signalOnly bool, agent string) ([]ProgressEntry, error) {
+	agent = strings.TrimSpace(agent)
 	artifact, err := small.LoadArtifact(baseDir, "progress.small.yml")
 	if err != nil {
 		return nil, err
@@ -219,10 +288,12 @@ func getRecentProgress(baseDir string, n int, signalOnly bool) ([]ProgressEntry,
 			Timestamp: stringVal(m["timestamp"]),
 			TaskID:    stringVal(m["task_id"]),
 			Status:    stringVal(m["status"]),
+			Agent:     progressEntryAgentID(m),
 		}
-		if evidence, ok := m["evidence"].(string); ok {
-			entry.Evidence = evidence
+		if agent != "" && entry.Agent != agent {
+			continue
 		}
+		entry.Evidence = progressEvidenceSummary(m["evidence"])
 		if notes, ok := m["notes"].(string); ok {
 			entry.Notes = notes
 		}
@@ -269,6 +340,7 @@ func getHandoffStatusSnapshot(baseDir string) (handoffStatusSnapshot, error) {
 	if resume, ok := artifact.Data["resume"].(map[string]any); ok {
 		snapshot.CurrentTaskID = strings.TrimSpace(stringVal(resume["current_task_id"]))
 	}
+	snapshot.Context = parseHandoffContext(artifact.Data["context"])
 
 	return snapshot, nil
 }
@@ -286,7 +358,7 @@ func resolveNextTask(plan *PlanStatus, handoffCurrentTaskID string) string {
 	if current := strings.TrimSpace(handoffCurrentTaskID); current != "" {
 		return current
 	}
-	if plan.TotalTasks > 0 && plan.TasksByStatus["completed"] == plan.TotalTasks {
+	if plan.TotalTasks > 0 && plan.TasksByStatus["completed"]+plan.TasksByStatus["cancelled"] == plan.TotalTasks {
 		return "No active task (run complete)"
 	}
 	return ""
@@ -331,6 +403,16 @@ func outputText(status StatusOutput) error {
 		for _, statusName := range knownPlanStatuses {
 			p.PrintInfo(fmt.Sprintf("  %s: %d", statusName, status.Plan.TasksByStatus[statusName]))
 		}
+		if len(status.Plan.Milestones) > 0 {
+			p.PrintInfo("Milestones:")
+			width := 0
+			for _, m := range status.Plan.Milestones {
+				width = max(width, len(m.Name))
+			}
+			for _, m := range status.Plan.Milestones {
+				p.PrintInfo(fmt.Sprintf("  %-*s %s", width, m.Name, milestoneProgressBar(m)))
+			}
+		}
 		if len(status.Plan.NextActionable) > 0 {
 			p.PrintInfo(fmt.Sprintf("Next actionable: %v", status.Plan.NextActionable))
 		} else {
@@ -348,11 +430,15 @@ func outputText(status StatusOutput) error {
 		for _, entry := range status.RecentProgress {
 			ts := formatTimestamp(entry.Timestamp)
 			evidence := summarizeStatusEvidence(entry)
+			state := entry.Status
+			if entry.Agent != "" {
+				state += " by " + entry.Agent
+			}
 			if evidence != "" {
-				p.PrintInfo(fmt.Sprintf("  [%s] %s: %s - %s", ts, entry.TaskID, entry.Status, evidence))
+				p.PrintInfo(fmt.Sprintf("  [%s] %s: %s - %s", ts, entry.TaskID, state, evidence))
 				continue
 			}
-			p.PrintInfo(fmt.Sprintf("  [%s] %s: %s", ts, entry.TaskID, entry.Status))
+			p.PrintInfo(fmt.Sprintf("  [%s] %s: %s", ts, entry.TaskID, state))
 		}
 		p.PrintInfo("")
 	}
@@ -362,10 +448,65 @@ func outputText(status StatusOutput) error {
 		ts := formatTimestamp(status.LastHandoff)
 		p.PrintInfo(fmt.Sprintf("Last handoff: %s", ts))
 	}
+	printHandoffContext(p, status.HandoffContext)
 
 	return nil
 }
 
+func printHandoffContext(p *Printer, ctx *handoffContextOut) {
+	if ctx.isEmpty() {
+		return
+	}
+	p.PrintInfo("Handoff context:")
+	if len(ctx.Decisions) > 0 {
+		p.PrintInfo("  Decisions:")
+		for _, decision := range ctx.Decisions {
+			line := "    - " + decision.Decision
+			if decision.Rationale != "" {
+				line += " (why: " + decision.Rationale + ")"
+			}
+			if len(decision.ProgressRefs) > 0 {
+				line += " [" + strings.Join(decision.ProgressRefs, ", ") + "]"
+			}
+			p.PrintInfo(line)
+		}
+	}
+	for _, section := range []struct {
+		label string
+		items []string
+	}{
+		{"Open questions", ctx.OpenQuestions},
+		{"Risks", ctx.Risks},
+		{"Failing checks", ctx.FailingChecks},
+		{"Touched files", ctx.TouchedFiles},
+	} {
+		if len(section.items) == 0 {
+			continue
+		}
+		p.PrintInfo(fmt.Sprintf("  %s:", section.label))
+		for i, item := range section.items {
+			if i >= defaultListCap {
+				p.PrintInfo(fmt.Sprintf("    and %d more", len(section.items)-defaultListCap))
+				break
+			}
+			p.PrintInfo("    - " + item)
+		}
+	}
+}
+
+// progressEvidenceSummary returns string evidence as-is and the summary field of
+// structured evidence objects.
+func progressEvidenceSummary(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]any:
+		return stringVal(v["summary"])
+	default:
+		return ""
+	}
+}
+
 func summarizeStatusEvidence(entry ProgressEntry) string {
 	candidate := strings.TrimSpace(entry.Evidence)
 	if candidate == "" {
@@ -1,32 +1,41 @@
 package commands
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
+// nextActionableTaskIDs returns pending leaf tasks whose dependencies, and those of their
+// ancestors, are completed or cancelled. Dependencies on superseded tasks wait for the
+// replacement instead. Parent statuses are rolled up from their subtasks first. Tasks
+// under a live lease held by anyone but the current agent are left out.
 func nextActionableTaskIDs(tasks []PlanTask, limit int) []string {
-	taskStatuses := make(map[string]string, len(tasks))
-	for _, task := range tasks {
-		taskStatuses[strings.TrimSpace(task.ID)] = normalizePlanStatus(task.Status)
-	}
+	return nextActionableTaskIDsForAgent(tasks, limit, currentAgentID(), time.Now())
+}
+
+// nextActionableTaskIDsForAgent is nextActionableTaskIDs for agent, which still sees the
+// tasks it holds live leases on.
+func nextActionableTaskIDsForAgent(tasks []PlanTask, limit int, agent string, now time.Time) []string {
+	tasks = append([]PlanTask{}, tasks...)
+	rollUpPlanStatuses(tasks)
+	children := planChildren(tasks)
+	deps := planEffectiveDependencies(tasks)
+	state := newPlanDependencyState(tasks)
 
 	actionable := []string{}
 	for _, task := range tasks {
-		if normalizePlanStatus(task.Status) != "pending" {
+		id := strings.TrimSpace(task.ID)
+		if normalizePlanStatus(task.Status) != "pending" || len(children[id]) > 0 {
 			continue
 		}
-
-		depsSatisfied := true
-		for _, depID := range task.Dependencies {
-			depStatus, exists := taskStatuses[depID]
-			if !exists || depStatus != "completed" {
-				depsSatisfied = false
-				break
-			}
+		if task.Lease.heldByOther(agent, now) {
+			continue
 		}
-		if !depsSatisfied {
+		if len(state.unsatisfied(deps[id])) > 0 {
 			continue
 		}
 
-		actionable = append(actionable, strings.TrimSpace(task.ID))
+		actionable = append(actionable, id)
 		if limit > 0 && len(actionable) >= limit {
 			break
 		}
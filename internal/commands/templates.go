@@ -72,10 +72,12 @@ Files in ` + "`.small/`" + ` have explicit ownership:
 ## Strict Mode Rules
 
 ` + "`small check --strict`" + ` enforces additional invariants:
-- S1: Completed/blocked tasks must have progress entries with evidence
+- S1: Completed/blocked/cancelled tasks must have progress entries with evidence
 - S2: All progress task_ids must reference valid plan tasks (or use meta/ prefix)
 - S3: Handoff current_task_id must exist in plan
 - S4: No unknown files or subdirectories under .small/
+- S5: Every progress entry and the handoff must record an agent id
+- S6: Progress entries must keep an intact prev_hash/entry_hash chain once sealed
 
 ## Localhost HTTP Allowlist (Strict Mode)
 
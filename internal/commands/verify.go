@@ -17,6 +17,8 @@ const (
 	ExitValid       = 0
 	ExitInvalid     = 1
 	ExitSystemError = 2
+	// ExitConflict is returned by writers whose --if-match revision is stale.
+	ExitConflict = 3
 )
 
 func verifyCmd() *cobra.Command {
@@ -24,6 +26,12 @@ func verifyCmd() *cobra.Command {
 	var ci bool
 	var dir string
 	var workspaceFlag string
+	var scopeBase string
+	var requireSignatures bool
+	var trustedKeys string
+	var ownershipAudit bool
+	var ownershipBase string
+	var humanIdentities string
 
 	cmd := &cobra.Command{
 		Use:   "verify",
@@ -34,6 +42,9 @@ Performs:
   - Schema validation of all artifacts
   - Invariant enforcement (required files, ownership, format)
   - ReplayId validation (required in handoff.small.yml)
+  - Scope drift against a git ref (with --scope-base)
+  - Signatures of the handoff and its run snapshot (with --require-signatures)
+  - Who edited human-owned artifacts in git history (with --ownership-audit)
 
 Exit codes:
   0 - All artifacts valid
@@ -42,7 +53,15 @@ Exit codes:
 
 Flags:
   --strict   Enable strict mode (strict invariants, secrets, insecure links)
-  --ci       CI mode (minimal output, just errors)`,
+  --ci       CI mode (minimal output, just errors)
+  --scope-base <ref>
+             Fail when paths changed since <ref> fall outside intent scope
+  --require-signatures --trusted-keys <file>
+             Fail unless handoff.small.yml (and the run snapshot of its
+             replayId, if any) is signed by a key listed in <file>
+  --ownership-audit
+             Fail when a commit attributed to an agent changed intent.small.yml
+             or constraints.small.yml`,
 		Run: func(cmd *cobra.Command, args []string) {
 			p := currentPrinter()
 			scope, err := workspace.ParseScope(workspaceFlag)
@@ -55,7 +74,17 @@ Flags:
 				dir = baseDir
 			}
 
-			exitCode := runVerify(dir, strict, ci, scope)
+			exitCode := runVerifyWithOptions(dir, verifyOptions{
+				strict:            strict,
+				ci:                ci,
+				scope:             scope,
+				scopeBase:         scopeBase,
+				requireSignatures: requireSignatures,
+				trustedKeys:       trustedKeys,
+				ownershipAudit:    ownershipAudit,
+				ownershipBase:     ownershipBase,
+				humanIdentities:   humanIdentities,
+			})
 			os.Exit(exitCode)
 		},
 	}
@@ -64,11 +93,41 @@ Flags:
 	cmd.Flags().BoolVar(&ci, "ci", false, "CI mode (minimal output)")
 	cmd.Flags().StringVar(&dir, "dir", "", "Directory containing .small/ artifacts")
 	cmd.Flags().StringVar(&workspaceFlag, "workspace", string(workspace.ScopeRoot), "Workspace scope (root, examples, or any)")
+	cmd.Flags().StringVar(&scopeBase, "scope-base", "", "Git ref to diff against for intent scope drift")
+	cmd.Flags().BoolVar(&requireSignatures, "require-signatures", false, "Require a trusted signature on the handoff and its run snapshot")
+	cmd.Flags().StringVar(&trustedKeys, "trusted-keys", "", "File of trusted ed25519 public keys (OpenSSH or PEM) for --require-signatures")
+	cmd.Flags().BoolVar(&ownershipAudit, "ownership-audit", false, "Fail when git history shows an agent edited intent or constraints")
+	cmd.Flags().StringVar(&ownershipBase, "ownership-base", "", "Only audit commits after this git ref (with --ownership-audit)")
+	cmd.Flags().StringVar(&humanIdentities, "human-identities", "", "File of human names or emails allowed to edit intent and constraints (with --ownership-audit)")
 
 	return cmd
 }
 
+// verifyOptions configures runVerifyWithOptions.
+type verifyOptions struct {
+	strict bool
+	ci     bool
+	scope  workspace.Scope
+	// scopeBase is a git ref; when set, paths changed since it must fall within intent scope.
+	scopeBase string
+	// requireSignatures fails verification unless the handoff (and its run snapshot, if
+	// any) carries a valid signature by one of the keys in trustedKeys.
+	requireSignatures bool
+	trustedKeys       string
+	// ownershipAudit fails verification when a commit attributed to an agent changed a
+	// human-owned artifact. ownershipBase limits it to commits after a ref, and
+	// humanIdentities names a file listing the humans allowed to make such commits.
+	ownershipAudit  bool
+	ownershipBase   string
+	humanIdentities string
+}
+
 func runVerify(dir string, strict, ci bool, scope workspace.Scope) int {
+	return runVerifyWithOptions(dir, verifyOptions{strict: strict, ci: ci, scope: scope})
+}
+
+func runVerifyWithOptions(dir string, opts verifyOptions) int {
+	strict, ci, scope := opts.strict, opts.ci, opts.scope
 	p := currentPrinter()
 	smallDir := filepath.Join(dir, ".small")
 
@@ -171,6 +230,47 @@ func runVerify(dir string, strict, ci bool, scope workspace.Scope) int {
 		}
 	}
 
+	// Scope drift against a git base ref
+	if opts.scopeBase != "" {
+		if intent, ok := artifacts["intent"]; ok {
+			intentScope, err := small.IntentScopeFromArtifact(intent)
+			if err != nil {
+				allErrors = append(allErrors, verifyError{message: fmt.Sprintf("Scope: %v", err)})
+			} else {
+				report, err := evaluateScopeDrift(artifactsDir, intentScope, opts.scopeBase)
+				if err != nil {
+					p.PrintError(fmt.Sprintf("Error checking scope drift: %v", err))
+					return ExitSystemError
+				}
+				allErrors = append(allErrors, scopeDriftVerifyErrors(report)...)
+			}
+		}
+	}
+
+	// Detached signatures
+	if opts.requireSignatures {
+		if strings.TrimSpace(opts.trustedKeys) == "" {
+			p.PrintError("Error: --require-signatures needs --trusted-keys <file>")
+			return ExitSystemError
+		}
+		signatureErrors, err := verifySignatures(artifactsDir, artifacts["handoff"], opts.trustedKeys)
+		if err != nil {
+			p.PrintError(fmt.Sprintf("Error loading trusted keys: %v", err))
+			return ExitSystemError
+		}
+		allErrors = append(allErrors, signatureErrors...)
+	}
+
+	// Ownership of human-owned artifacts in git history
+	if opts.ownershipAudit {
+		ownershipErrors, err := auditOwnership(artifactsDir, artifacts, opts)
+		if err != nil {
+			p.PrintError(fmt.Sprintf("Error auditing artifact ownership: %v", err))
+			return ExitSystemError
+		}
+		allErrors = append(allErrors, ownershipErrors...)
+	}
+
 	// Report results
 	if len(allErrors) > 0 {
 		if strict && !ci {
@@ -465,6 +465,8 @@ links: []
 replayId:
   value: "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2"
   source: "auto"
+agent:
+  id: "agent-test"
 `,
 	}
 }
@@ -660,6 +662,8 @@ links: []
 replayId:
   value: "a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2c3d4e5f6a1b2"
   source: "auto"
+agent:
+  id: "agent-test"
 `
 	writeArtifacts(t, tmpDir, artifacts)
 	mustSaveWorkspace(t, tmpDir, workspace.KindRepoRoot)
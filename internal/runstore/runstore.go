@@ -32,6 +32,7 @@ var (
 	}
 	OptionalArtifacts = []string{
 		"constraints.small.yml",
+		small.ProgressLedgerFileName,
 	}
 )
 
@@ -275,7 +276,7 @@ func LoadSnapshot(storeDir, replayID string) (*Snapshot, error) {
 	}
 
 	artifacts := []string{filepath.Join(snapshotDir, MetaFileName)}
-	for _, filename := range append(RequiredArtifacts, OptionalArtifacts...) {
+	for _, filename := range append(append(RequiredArtifacts, OptionalArtifacts...), ManifestFileName, ManifestFileName+small.SignatureSuffix) {
 		path := filepath.Join(snapshotDir, filename)
 		if _, err := os.Stat(path); err == nil {
 			artifacts = append(artifacts, path)
@@ -330,6 +331,14 @@ func CheckoutSnapshot(baseDir, storeDir, replayID string, force bool) error {
 		}
 	}
 
+	// A snapshot without a ledger holds every progress entry in progress.small.yml, so a
+	// ledger left in the workspace would add entries the snapshot never had.
+	if _, err := os.Stat(filepath.Join(snapshot.Dir, small.ProgressLedgerFileName)); os.IsNotExist(err) {
+		if err := os.Remove(small.ProgressLedgerPath(baseDir)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", small.ProgressLedgerFileName, err)
+		}
+	}
+
 	return nil
 }
 
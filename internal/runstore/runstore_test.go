@@ -1,6 +1,7 @@
 package runstore
 
 import (
+	"crypto/ed25519"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -282,3 +283,35 @@ func writeTestArtifacts(t *testing.T, dir string) {
 		}
 	}
 }
+
+func TestSignedSnapshotDetectsModification(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeTestWorkspace(t, tmpDir, "abc123", "Signed snapshot", true)
+
+	snapshot, err := WriteSnapshot(tmpDir, filepath.Join(tmpDir, DefaultStoreDirName), false)
+	if err != nil {
+		t.Fatalf("WriteSnapshot failed: %v", err)
+	}
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trusted := []ed25519.PublicKey{pub}
+	if _, err := VerifySnapshotSignature(snapshot.Dir, trusted); err == nil {
+		t.Fatal("expected an unsigned snapshot to fail verification")
+	}
+	if err := SignSnapshot(snapshot.Dir, key); err != nil {
+		t.Fatalf("SignSnapshot failed: %v", err)
+	}
+	if _, err := VerifySnapshotSignature(snapshot.Dir, trusted); err != nil {
+		t.Fatalf("expected the signed snapshot to verify: %v", err)
+	}
+
+	planPath := filepath.Join(snapshot.Dir, "plan.small.yml")
+	if err := os.WriteFile(planPath, []byte("tampered\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifySnapshotSignature(snapshot.Dir, trusted); err == nil || !strings.Contains(err.Error(), "modified after signing") {
+		t.Fatalf("expected a modified snapshot to fail verification, got %v", err)
+	}
+}
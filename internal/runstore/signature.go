@@ -0,0 +1,74 @@
+package runstore
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/justyn-clark/small-protocol/internal/small"
+)
+
+// ManifestFileName lists the sha256 of every file in a signed snapshot, in the format
+// sha256sum writes. Its detached signature is ManifestFileName + small.SignatureSuffix.
+const ManifestFileName = "manifest.sha256"
+
+// BuildManifest returns the manifest for the snapshot in snapshotDir: one
+// "<sha256>  <file>" line for meta.json and each artifact, sorted by file name.
+func BuildManifest(snapshotDir string) ([]byte, error) {
+	files := append([]string{MetaFileName}, snapshotFiles(snapshotDir)...)
+	sort.Strings(files)
+	var buf bytes.Buffer
+	for _, filename := range files {
+		data, err := os.ReadFile(filepath.Join(snapshotDir, filename))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+		sum := sha256.Sum256(data)
+		fmt.Fprintf(&buf, "%s  %s\n", hex.EncodeToString(sum[:]), filename)
+	}
+	return buf.Bytes(), nil
+}
+
+// SignSnapshot writes the snapshot's manifest and a detached signature over it.
+func SignSnapshot(snapshotDir string, key ed25519.PrivateKey) error {
+	manifest, err := BuildManifest(snapshotDir)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(snapshotDir, ManifestFileName)
+	if err := small.WriteFileAtomic(path, manifest, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ManifestFileName, err)
+	}
+	return small.SignFile(path, key)
+}
+
+// VerifySnapshotSignature checks that the snapshot's manifest was signed by a trusted key
+// and still matches the files in the snapshot. It returns the signing key.
+func VerifySnapshotSignature(snapshotDir string, trusted []ed25519.PublicKey) (ed25519.PublicKey, error) {
+	path := filepath.Join(snapshotDir, ManifestFileName)
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, errors.New("not signed")
+	}
+	key, err := small.VerifyFileSignature(path, trusted)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	current, err := BuildManifest(snapshotDir)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(signed, current) {
+		return nil, errors.New("snapshot files no longer match the signed manifest (modified after signing)")
+	}
+	return key, nil
+}
@@ -0,0 +1,43 @@
+package small
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validateStrictProgressAgents enforces S5 on progress: every entry must record the
+// agent, tool, or human that wrote it.
+func validateStrictProgressAgents(progressArtifact *Artifact) []InvariantViolation {
+	entries, _ := progressArtifact.Data["entries"].([]any)
+	var missing []string
+	for i, raw := range entries {
+		entry, _ := raw.(map[string]any)
+		if agentID(entry) == "" {
+			missing = append(missing, fmt.Sprintf("entries[%d] (task %s)", i, stringVal(entry["task_id"])))
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return []InvariantViolation{{
+		File:    progressArtifact.Path,
+		Message: fmt.Sprintf("strict invariant S5 failed: progress entries must include agent.id (set --agent or SMALL_AGENT_ID): %s", strings.Join(missing, "; ")),
+	}}
+}
+
+// validateStrictHandoffAgent enforces S5 on the handoff, which must record who wrote it.
+func validateStrictHandoffAgent(handoffArtifact *Artifact) []InvariantViolation {
+	if agentID(handoffArtifact.Data) != "" {
+		return nil
+	}
+	return []InvariantViolation{{
+		File:    handoffArtifact.Path,
+		Message: "strict invariant S5 failed: handoff must include agent.id (set --agent or SMALL_AGENT_ID)",
+	}}
+}
+
+func agentID(data map[string]any) string {
+	agent, _ := data["agent"].(map[string]any)
+	id, _ := agent["id"].(string)
+	return strings.TrimSpace(id)
+}
@@ -0,0 +1,35 @@
+package small
+
+import "testing"
+
+func TestStrictProgressAgents(t *testing.T) {
+	artifacts := func(handoffAgent map[string]any, entries ...any) map[string]*Artifact {
+		handoff := map[string]any{"small_version": ProtocolVersion, "owner": "agent"}
+		if handoffAgent != nil {
+			handoff["agent"] = handoffAgent
+		}
+		return map[string]*Artifact{
+			"progress": {
+				Path: "test/progress.small.yml",
+				Type: "progress",
+				Data: map[string]any{"small_version": ProtocolVersion, "owner": "agent", "entries": entries},
+			},
+			"handoff": {Path: "test/handoff.small.yml", Type: "handoff", Data: handoff},
+		}
+	}
+	agent := map[string]any{"id": "agent-a"}
+	stamped := map[string]any{"task_id": "task-1", "agent": agent}
+	unstamped := map[string]any{"task_id": "task-2"}
+
+	if v := validateStrictInvariants(artifacts(agent, stamped, stamped)); len(v) != 0 {
+		t.Fatalf("stamped progress and handoff should pass, got %+v", v)
+	}
+	v := validateStrictInvariants(artifacts(agent, unstamped, stamped))
+	if len(v) != 1 || !contains(v[0].Message, "strict invariant S5 failed") || !contains(v[0].Message, "entries[0] (task task-2)") {
+		t.Fatalf("an unstamped entry should fail S5 even before any stamped one, got %+v", v)
+	}
+	v = validateStrictInvariants(artifacts(nil, stamped))
+	if len(v) != 1 || v[0].File != "test/handoff.small.yml" || !contains(v[0].Message, "handoff must include agent.id") {
+		t.Fatalf("an unstamped handoff should fail S5, got %+v", v)
+	}
+}
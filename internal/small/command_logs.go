@@ -1,12 +1,17 @@
 package small
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"hash"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 )
 
 const DefaultCommandSummaryCap = 200
@@ -72,3 +77,277 @@ func WriteCommandLog(baseDir, replayId, timestamp, command string) (string, stri
 	refPath := filepath.ToSlash(filepath.Join(CacheDirName, "logs", replayId, "commands", filename))
 	return refPath, hex.EncodeToString(sha[:]), nil
 }
+
+// Command output streams captured next to the command log.
+const (
+	CommandStreamStdout = "stdout"
+	CommandStreamStderr = "stderr"
+)
+
+const commandOutputTimestampLayout = "2006-01-02T15:04:05.000000000Z"
+
+// CommandOutputLog streams one command output stream to
+// .small-cache/logs/<replayId>/commands/<timestamp>.<stream>.log. Each line is
+// prefixed with the UTC time its first byte arrived. It tracks the number of raw
+// output bytes and the sha256 of the log file as written.
+type CommandOutputLog struct {
+	mu          sync.Mutex
+	file        *os.File
+	path        string
+	hash        hash.Hash
+	ref         string
+	bytes       int64
+	atLineStart bool
+	now         func() time.Time
+}
+
+// CreateCommandOutputLog creates the log file for a command output stream.
+func CreateCommandOutputLog(baseDir, replayId, timestamp, stream string) (*CommandOutputLog, error) {
+	if stream != CommandStreamStdout && stream != CommandStreamStderr {
+		return nil, fmt.Errorf("unknown command output stream %q", stream)
+	}
+	sanitized, err := SanitizeTimestampForFilename(timestamp)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := EnsureCommandLogDir(baseDir, replayId)
+	if err != nil {
+		return nil, err
+	}
+	filename := sanitized + "." + stream + ".log"
+	file, err := os.OpenFile(filepath.Join(dir, filename), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &CommandOutputLog{
+		file:        file,
+		path:        file.Name(),
+		hash:        sha256.New(),
+		ref:         filepath.ToSlash(filepath.Join(CacheDirName, "logs", replayId, "commands", filename)),
+		atLineStart: true,
+		now:         time.Now,
+	}, nil
+}
+
+// Write records p, inserting a timestamp at the start of every line.
+func (l *CommandOutputLog) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var out bytes.Buffer
+	rest := p
+	for len(rest) > 0 {
+		if l.atLineStart {
+			out.WriteString(l.now().UTC().Format(commandOutputTimestampLayout))
+			out.WriteByte(' ')
+			l.atLineStart = false
+		}
+		idx := bytes.IndexByte(rest, '\n')
+		if idx < 0 {
+			out.Write(rest)
+			break
+		}
+		out.Write(rest[:idx+1])
+		rest = rest[idx+1:]
+		l.atLineStart = true
+	}
+	if err := l.writeFile(out.Bytes()); err != nil {
+		return 0, err
+	}
+	l.bytes += int64(len(p))
+	return len(p), nil
+}
+
+func (l *CommandOutputLog) writeFile(data []byte) error {
+	if _, err := l.file.Write(data); err != nil {
+		return err
+	}
+	_, _ = l.hash.Write(data)
+	return nil
+}
+
+// Close terminates any partial final line, closes the file, and marks the log done.
+func (l *CommandOutputLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	var err error
+	if !l.atLineStart {
+		err = l.writeFile([]byte("\n"))
+		l.atLineStart = true
+	}
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	if doneErr := os.WriteFile(l.path+commandOutputDoneSuffix, nil, 0o644); err == nil {
+		err = doneErr
+	}
+	return err
+}
+
+// commandOutputDoneSuffix names the empty sidecar written once an output log is closed,
+// so followers know no more output is coming.
+const commandOutputDoneSuffix = ".done"
+
+// CommandOutputLogDone reports whether the output log at the workspace-relative ref has
+// been closed by its writer.
+func CommandOutputLogDone(baseDir, ref string) bool {
+	_, err := os.Stat(filepath.Join(baseDir, filepath.FromSlash(ref)) + commandOutputDoneSuffix)
+	return err == nil
+}
+
+// Ref returns the workspace-relative path of the log file.
+func (l *CommandOutputLog) Ref() string {
+	return l.ref
+}
+
+// Bytes returns the number of output bytes the command wrote to this stream.
+func (l *CommandOutputLog) Bytes() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.bytes
+}
+
+// SHA256 returns the hex sha256 of the log file contents written so far.
+func (l *CommandOutputLog) SHA256() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return hex.EncodeToString(l.hash.Sum(nil))
+}
+
+// CommandLogSet groups the files recorded for one command under a replayId.
+type CommandLogSet struct {
+	ReplayID   string    `json:"replayId"`
+	ID         string    `json:"id"`
+	CommandRef string    `json:"command_ref,omitempty"`
+	StdoutRef  string    `json:"stdout_ref,omitempty"`
+	StderrRef  string    `json:"stderr_ref,omitempty"`
+	StdoutSize int64     `json:"stdout_size"`
+	StderrSize int64     `json:"stderr_size"`
+	ModTime    time.Time `json:"modified_at"`
+}
+
+// Refs returns the workspace-relative paths of every file in the set.
+func (s CommandLogSet) Refs() []string {
+	var refs []string
+	for _, ref := range []string{s.CommandRef, s.StdoutRef, s.StderrRef} {
+		if ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// ListCommandLogs returns command log sets under .small-cache/logs, oldest first.
+// An empty replayId lists every run.
+func ListCommandLogs(baseDir, replayId string) ([]CommandLogSet, error) {
+	logsRoot := filepath.Join(CacheDir(baseDir), "logs")
+	var replayIds []string
+	if replayId != "" {
+		replayIds = []string{replayId}
+	} else {
+		entries, err := os.ReadDir(logsRoot)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				replayIds = append(replayIds, entry.Name())
+			}
+		}
+	}
+
+	var sets []CommandLogSet
+	for _, id := range replayIds {
+		dir := CacheCommandLogsDir(baseDir, id)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		byStem := map[string]*CommandLogSet{}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			name := entry.Name()
+			stem, kind := splitCommandLogName(name)
+			if stem == "" {
+				continue
+			}
+			set, ok := byStem[stem]
+			if !ok {
+				set = &CommandLogSet{ReplayID: id, ID: stem}
+				byStem[stem] = set
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil, err
+			}
+			if info.ModTime().After(set.ModTime) {
+				set.ModTime = info.ModTime()
+			}
+			ref := filepath.ToSlash(filepath.Join(CacheDirName, "logs", id, "commands", name))
+			switch kind {
+			case "command":
+				set.CommandRef = ref
+			case CommandStreamStdout:
+				set.StdoutRef = ref
+				set.StdoutSize = info.Size()
+			case CommandStreamStderr:
+				set.StderrRef = ref
+				set.StderrSize = info.Size()
+			}
+		}
+		for _, set := range byStem {
+			sets = append(sets, *set)
+		}
+	}
+
+	sort.Slice(sets, func(i, j int) bool {
+		if sets[i].ID == sets[j].ID {
+			return sets[i].ReplayID < sets[j].ReplayID
+		}
+		return sets[i].ID < sets[j].ID
+	})
+	return sets, nil
+}
+
+// RemoveCommandLogSet deletes every file in the set and removes the run's log
+// directories once they are empty.
+func RemoveCommandLogSet(baseDir string, set CommandLogSet) error {
+	for _, ref := range set.Refs() {
+		path := filepath.Join(baseDir, filepath.FromSlash(ref))
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.Remove(path + commandOutputDoneSuffix); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	commandsDir := CacheCommandLogsDir(baseDir, set.ReplayID)
+	_ = os.Remove(commandsDir)
+	_ = os.Remove(filepath.Dir(commandsDir))
+	return nil
+}
+
+func splitCommandLogName(name string) (string, string) {
+	switch {
+	case strings.HasSuffix(name, "."+CommandStreamStdout+".log"):
+		return strings.TrimSuffix(name, "."+CommandStreamStdout+".log"), CommandStreamStdout
+	case strings.HasSuffix(name, "."+CommandStreamStderr+".log"):
+		return strings.TrimSuffix(name, "."+CommandStreamStderr+".log"), CommandStreamStderr
+	case strings.HasSuffix(name, ".txt"):
+		return strings.TrimSuffix(name, ".txt"), "command"
+	default:
+		return "", ""
+	}
+}
@@ -63,3 +63,92 @@ func TestSummarizeCommandCap(t *testing.T) {
 		t.Fatalf("expected summary to normalize whitespace")
 	}
 }
+
+func TestCommandOutputLogTimestampsLines(t *testing.T) {
+	baseDir := t.TempDir()
+	replayId := strings.Repeat("b", 64)
+	timestamp := time.Date(2026, 1, 22, 10, 40, 42, 0, time.UTC).Format("2006-01-02T15:04:05.000000000Z")
+
+	log, err := CreateCommandOutputLog(baseDir, replayId, timestamp, CommandStreamStdout)
+	if err != nil {
+		t.Fatalf("CreateCommandOutputLog error: %v", err)
+	}
+	fixed := time.Date(2026, 1, 22, 10, 40, 43, 5, time.UTC)
+	log.now = func() time.Time { return fixed }
+
+	for _, chunk := range []string{"hel", "lo\nwor", "ld\npartial"} {
+		if _, err := log.Write([]byte(chunk)); err != nil {
+			t.Fatalf("Write error: %v", err)
+		}
+	}
+	if CommandOutputLogDone(baseDir, log.Ref()) {
+		t.Fatal("log reported done before Close")
+	}
+	if err := log.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	if !CommandOutputLogDone(baseDir, log.Ref()) {
+		t.Fatal("log not reported done after Close")
+	}
+
+	content, err := os.ReadFile(filepath.Join(baseDir, filepath.FromSlash(log.Ref())))
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+	prefix := "2026-01-22T10:40:43.000000005Z "
+	want := prefix + "hello\n" + prefix + "world\n" + prefix + "partial\n"
+	if string(content) != want {
+		t.Fatalf("log content = %q, want %q", content, want)
+	}
+	if log.Bytes() != int64(len("hello\nworld\npartial")) {
+		t.Fatalf("Bytes() = %d", log.Bytes())
+	}
+	hash := sha256.Sum256(content)
+	if log.SHA256() != hex.EncodeToString(hash[:]) {
+		t.Fatalf("SHA256() does not match log file contents")
+	}
+	if !strings.HasSuffix(log.Ref(), ".stdout.log") {
+		t.Fatalf("unexpected ref %q", log.Ref())
+	}
+}
+
+func TestListCommandLogsGroupsSiblings(t *testing.T) {
+	baseDir := t.TempDir()
+	replayId := strings.Repeat("c", 64)
+	timestamp := time.Date(2026, 1, 22, 10, 40, 42, 0, time.UTC).Format("2006-01-02T15:04:05.000000000Z")
+
+	ref, _, err := WriteCommandLog(baseDir, replayId, timestamp, "echo hi")
+	if err != nil {
+		t.Fatalf("WriteCommandLog error: %v", err)
+	}
+	for _, stream := range []string{CommandStreamStdout, CommandStreamStderr} {
+		log, err := CreateCommandOutputLog(baseDir, replayId, timestamp, stream)
+		if err != nil {
+			t.Fatalf("CreateCommandOutputLog error: %v", err)
+		}
+		_, _ = log.Write([]byte("line\n"))
+		_ = log.Close()
+	}
+
+	sets, err := ListCommandLogs(baseDir, "")
+	if err != nil {
+		t.Fatalf("ListCommandLogs error: %v", err)
+	}
+	if len(sets) != 1 {
+		t.Fatalf("expected 1 log set, got %d", len(sets))
+	}
+	set := sets[0]
+	if set.CommandRef != ref || set.StdoutRef == "" || set.StderrRef == "" {
+		t.Fatalf("unexpected log set: %#v", set)
+	}
+	if set.StdoutSize == 0 {
+		t.Fatalf("expected stdout size to be recorded")
+	}
+
+	if err := RemoveCommandLogSet(baseDir, set); err != nil {
+		t.Fatalf("RemoveCommandLogSet error: %v", err)
+	}
+	if _, err := os.Stat(CacheCommandLogsDir(baseDir, replayId)); !os.IsNotExist(err) {
+		t.Fatalf("expected empty log directory to be removed, got %v", err)
+	}
+}
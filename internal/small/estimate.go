@@ -0,0 +1,16 @@
+package small
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ParseTaskEstimate parses a plan task estimate, a positive Go duration such as "90m" or "2h30m".
+func ParseTaskEstimate(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("estimate %q must be a positive duration such as \"90m\" or \"2h\"", value)
+	}
+	return d, nil
+}
@@ -0,0 +1,38 @@
+package small
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTaskEstimate(t *testing.T) {
+	if d, err := ParseTaskEstimate(" 1h30m "); err != nil || d != 90*time.Minute {
+		t.Fatalf("ParseTaskEstimate() = %v, %v", d, err)
+	}
+	for _, value := range []string{"", "3 days", "-1h", "0s"} {
+		if _, err := ParseTaskEstimate(value); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestCheckInvariants_PlanEstimate(t *testing.T) {
+	artifacts := map[string]*Artifact{
+		"plan": {
+			Path: "test/plan.small.yml",
+			Type: "plan",
+			Data: map[string]any{
+				"small_version": ProtocolVersion,
+				"owner":         "agent",
+				"tasks": []any{
+					map[string]any{"id": "task-1", "title": "One", "estimate": "2h"},
+					map[string]any{"id": "task-2", "title": "Two", "estimate": "soon"},
+				},
+			},
+		},
+	}
+	violations := CheckInvariants(artifacts, false)
+	if len(violations) != 1 || !contains(violations[0].Message, `tasks[1].estimate "soon"`) {
+		t.Fatalf("unexpected violations: %+v", violations)
+	}
+}
@@ -55,6 +55,13 @@ func FixOrphanProgress(baseDir string) (OrphanProgressFixResult, error) {
 	replayID := extractReplayID(handoff)
 	result := OrphanProgressFixResult{ReplayID: replayID}
 
+	// Rewriting task ids changes entry hashes; an intact chain is re-sealed afterwards.
+	resealChain := false
+	if small.ProgressChainEnforced(baseDir, entries) {
+		index, _ := small.VerifyProgressChain(entries)
+		resealChain = index < 0
+	}
+
 	for i, entry := range entries {
 		entryMap, ok := entry.(map[string]any)
 		if !ok {
@@ -100,6 +107,18 @@ func FixOrphanProgress(baseDir string) (OrphanProgressFixResult, error) {
 		return result, nil
 	}
 
+	if resealChain {
+		chain := make([]map[string]any, 0, len(entries))
+		for _, entry := range entries {
+			if entryMap, ok := entry.(map[string]any); ok {
+				chain = append(chain, entryMap)
+			}
+		}
+		if err := small.SealProgressEntries(chain); err != nil {
+			return OrphanProgressFixResult{}, err
+		}
+	}
+
 	if err := small.SaveArtifact(baseDir, "progress.small.yml", progress.Data); err != nil {
 		return OrphanProgressFixResult{}, err
 	}
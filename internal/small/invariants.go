@@ -34,10 +34,10 @@ func CheckInvariants(artifacts map[string]*Artifact, strict bool) []InvariantVio
 
 		// --- Global: version ---
 		v, vok := root["small_version"].(string)
-		if !vok || v != ProtocolVersion {
+		if !vok || !ArtifactVersionSupported(artifactType, v) {
 			violations = append(violations, InvariantViolation{
 				File:    artifact.Path,
-				Message: fmt.Sprintf(`small_version must be exactly "%s", got: %v`, ProtocolVersion, root["small_version"]),
+				Message: fmt.Sprintf(`small_version must be %s, got: %v`, supportedVersionsText(artifactType), root["small_version"]),
 			})
 		}
 
@@ -101,6 +101,7 @@ type planTask struct {
 	ID     string
 	Title  string
 	Status string
+	Parent string
 }
 
 type strictInvariantConfig struct {
@@ -128,6 +129,14 @@ func validateStrictInvariantsWithConfig(artifacts map[string]*Artifact, config s
 	if hasPlan && hasHandoff {
 		violations = append(violations, validateStrictHandoffTasks(planArtifact, handoffArtifact)...)
 	}
+	if hasHandoff {
+		violations = append(violations, validateStrictHandoffAgent(handoffArtifact)...)
+	}
+
+	if hasProgress {
+		violations = append(violations, validateStrictProgressAgents(progressArtifact)...)
+		violations = append(violations, validateStrictProgressChain(progressArtifact)...)
+	}
 
 	return violations
 }
@@ -156,9 +165,11 @@ func allowedTopLevelKeys(artifactType string) map[string]bool {
 	case "handoff":
 		base["summary"] = true
 		base["resume"] = true
+		base["context"] = true
 		base["links"] = true
 		base["replayId"] = true
 		base["run"] = true
+		base["agent"] = true
 		return base
 	default:
 		return nil
@@ -195,13 +206,14 @@ func validateStrictPlanTaskEvidence(planArtifact, progressArtifact *Artifact) []
 		}
 	}
 
+	parents := parentTaskIDs(tasks)
 	var missing []string
 	for _, task := range tasks {
 		status := strings.ToLower(strings.TrimSpace(task.Status))
-		if status != "completed" && status != "blocked" {
+		if status != "completed" && status != "blocked" && status != "cancelled" {
 			continue
 		}
-		if task.ID == "" {
+		if task.ID == "" || parents[task.ID] {
 			continue
 		}
 		if _, ok := satisfied[task.ID]; !ok {
@@ -385,6 +397,7 @@ func extractPlanTasks(planArtifact *Artifact) []planTask {
 			ID:     strings.TrimSpace(stringVal(taskMap["id"])),
 			Title:  strings.TrimSpace(stringVal(taskMap["title"])),
 			Status: strings.TrimSpace(stringVal(taskMap["status"])),
+			Parent: strings.TrimSpace(stringVal(taskMap["parent"])),
 		})
 	}
 
@@ -560,7 +573,20 @@ func validatePlan(path string, root map[string]any, owner string) []InvariantVio
 		if s, _ := m["title"].(string); strings.TrimSpace(s) == "" {
 			v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("tasks[%d].title must be a non-empty string", i)})
 		}
+		if raw, ok := m["estimate"]; ok {
+			if _, err := ParseTaskEstimate(stringVal(raw)); err != nil {
+				v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("tasks[%d].%v", i, err)})
+			}
+		}
+		if raw, ok := m["lease"]; ok {
+			if err := validateTaskLease(raw); err != nil {
+				v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("tasks[%d].%v", i, err)})
+			}
+		}
 	}
+	v = append(v, validatePlanDependencies(path, tasks)...)
+	v = append(v, validatePlanHierarchy(path, tasks)...)
+	v = append(v, validatePlanSupersession(path, tasks)...)
 	return v
 }
 
@@ -910,6 +936,67 @@ func checkInsecureLinks(artifact *Artifact) []InvariantViolation {
 	return v
 }
 
+// SecretKeyNames are the key substrings that mark a value as a potential secret.
+var SecretKeyNames = []string{"api_key", "apikey", "password", "secret", "token", "access_token", "private_key"}
+
+var secretTokenPattern = regexp.MustCompile(`^[A-Za-z0-9+/]{32,}={0,2}$`)
+
+// IsSecretKey reports whether a key or variable name contains one of SecretKeyNames,
+// ignoring case.
+func IsSecretKey(name string) bool {
+	lower := strings.ToLower(name)
+	for _, secretKey := range SecretKeyNames {
+		if strings.Contains(lower, secretKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// LooksLikeSecretValue reports whether a value has the shape of an opaque token.
+func LooksLikeSecretValue(value string) bool {
+	return len(value) >= 32 && secretTokenPattern.MatchString(value)
+}
+
+// digestKeys are the fields the CLI fills with sha256 hex digests, which have the shape
+// of a token. Other values under these keys are still checked.
+var digestKeys = map[string]bool{
+	"command_sha256": true,
+	"stdout_sha256":  true,
+	"stderr_sha256":  true,
+	"entry_hash":     true,
+	"prev_hash":      true,
+}
+
+var sha256DigestPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// recordedWorkspacePaths match the progress fields where small apply records workspace
+// paths: scope violation paths and the captured working directory.
+var recordedWorkspacePaths = []*regexp.Regexp{
+	regexp.MustCompile(`^entries\[\d+\]\.evidence\.scope_violation\.paths\[\d+\]\.path$`),
+	regexp.MustCompile(`^entries\[\d+\]\.evidence\.environment\.working_dir$`),
+}
+
+func isRecordedWorkspacePath(path string) bool {
+	for _, pattern := range recordedWorkspacePaths {
+		if pattern.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathLooksLikeSecret checks each element of a recorded path, since a whole path of
+// long directory names can match the token pattern when no single element does.
+func pathLooksLikeSecret(value string) bool {
+	for _, element := range strings.Split(value, "/") {
+		if LooksLikeSecretValue(element) {
+			return true
+		}
+	}
+	return false
+}
+
 func checkSecrets(artifact *Artifact) []InvariantViolation {
 	var violations []InvariantViolation
 
@@ -918,9 +1005,6 @@ func checkSecrets(artifact *Artifact) []InvariantViolation {
 		return violations
 	}
 
-	secretKeys := []string{"api_key", "apikey", "password", "secret", "token", "access_token", "private_key"}
-	tokenPattern := regexp.MustCompile(`^[A-Za-z0-9+/]{32,}={0,2}$`)
-
 	// Paths to exclude from secrets check (known safe fields)
 	excludedPaths := map[string]bool{
 		"replayId.value":         true,
@@ -930,22 +1014,24 @@ func checkSecrets(artifact *Artifact) []InvariantViolation {
 
 	checkValue := func(key string, value any, path string) bool {
 		// Skip excluded paths
-		if excludedPaths[path] || strings.HasSuffix(path, ".replayId") || path == "replayId" || strings.HasSuffix(path, ".command_sha256") {
+		if excludedPaths[path] || strings.HasSuffix(path, ".replayId") || path == "replayId" {
 			return false
 		}
 
-		keyLower := strings.ToLower(key)
-		for _, secretKey := range secretKeys {
-			if strings.Contains(keyLower, secretKey) {
-				return true
-			}
+		if IsSecretKey(key) {
+			return true
 		}
-		if str, ok := value.(string); ok {
-			if tokenPattern.MatchString(str) && len(str) >= 32 {
-				return true
-			}
+		str, ok := value.(string)
+		if !ok {
+			return false
 		}
-		return false
+		if digestKeys[key] && sha256DigestPattern.MatchString(str) {
+			return false
+		}
+		if isRecordedWorkspacePath(path) {
+			return pathLooksLikeSecret(str)
+		}
+		return LooksLikeSecretValue(str)
 	}
 
 	var checkMap func(map[string]any, string)
@@ -1021,9 +1107,9 @@ func CheckDanglingTasks(planArtifact, progressArtifact *Artifact) []DanglingTask
 		if _, hasProgress := tasksWithProgress[task.ID]; !hasProgress {
 			continue
 		}
-		// Check if status is not terminal (completed or blocked)
+		// Check if status is not terminal (completed, blocked, or cancelled)
 		status := strings.ToLower(task.Status)
-		if status == "completed" || status == "blocked" {
+		if status == "completed" || status == "blocked" || status == "cancelled" {
 			continue
 		}
 		// This task has progress but is not closed
@@ -1,6 +1,9 @@
 package small
 
 import (
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -45,6 +48,34 @@ func TestCheckInvariants_Version(t *testing.T) {
 	}
 }
 
+func TestCheckInvariants_VersionMessageNamesSupportedVersions(t *testing.T) {
+	versionMessage := func(artifactType string, version any) string {
+		artifacts := map[string]*Artifact{
+			artifactType: {
+				Path: "test/" + artifactType + ".small.yml",
+				Type: artifactType,
+				Data: map[string]any{"small_version": version, "owner": "agent"},
+			},
+		}
+		for _, v := range CheckInvariants(artifacts, false) {
+			if contains(v.Message, "small_version") {
+				return v.Message
+			}
+		}
+		return ""
+	}
+
+	if msg := versionMessage("handoff", HandoffContextVersion); msg != "" {
+		t.Fatalf("a v1.1.0 handoff should be accepted, got %q", msg)
+	}
+	if msg := versionMessage("handoff", "2.0.0"); !contains(msg, `"1.0.0" or "1.1.0"`) {
+		t.Fatalf("expected the handoff error to name both versions, got %q", msg)
+	}
+	if msg := versionMessage("progress", HandoffContextVersion); !contains(msg, `exactly "1.0.0"`) {
+		t.Fatalf("expected v1.1.0 progress to be rejected for 1.0.0 only, got %q", msg)
+	}
+}
+
 func TestCheckInvariants_Owner(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -1534,3 +1565,71 @@ func TestCheckInvariants_StrictModeRunAPIKeyIsSecret(t *testing.T) {
 		t.Error("expected violation message to include 'run.api_key'")
 	}
 }
+
+func TestCheckSecrets_RecordedFieldsStillChecked(t *testing.T) {
+	digest := "3f1e8a0c5b7d9e2f4a6c8b0d1e3f5a7c9b1d3e5f7a9c1b3d5e7f9a1c3b5d7e9f"
+	token := "QWxhZGRpbjpvcGVuIHNlc2FtZQxxQWxhZGRpbjpvcGVu"
+	artifact := &Artifact{
+		Path: "test/progress.small.yml",
+		Type: "progress",
+		Data: map[string]any{
+			"entries": []any{
+				map[string]any{
+					"stdout_sha256": digest,
+					"entry_hash":    digest,
+					"evidence": map[string]any{
+						"scope_violation": map[string]any{
+							"paths": []any{
+								map[string]any{"path": "internal/commands/apply.go", "reason": "outside_include"},
+								map[string]any{"path": "tmp/" + token, "reason": "outside_include"},
+							},
+						},
+						"environment": map[string]any{"working_dir": "/home/dev/project"},
+					},
+				},
+				map[string]any{
+					"stderr_sha256": token,
+					"build_sha256":  digest,
+					"evidence": map[string]any{
+						"environment": map[string]any{"working_dir": "/srv/" + token},
+					},
+				},
+			},
+		},
+	}
+
+	var flagged []string
+	for _, v := range checkSecrets(artifact) {
+		flagged = append(flagged, strings.TrimPrefix(v.Message, "potential secret detected at "))
+	}
+	sort.Strings(flagged)
+	want := []string{
+		"entries[0].evidence.scope_violation.paths[1].path",
+		"entries[1].build_sha256",
+		"entries[1].evidence.environment.working_dir",
+		"entries[1].stderr_sha256",
+	}
+	if !reflect.DeepEqual(flagged, want) {
+		t.Fatalf("flagged %v, want %v", flagged, want)
+	}
+}
+
+func TestSecretKeyAndValueHelpers(t *testing.T) {
+	for name, want := range map[string]bool{
+		"GITHUB_TOKEN":   true,
+		"db_Password":    true,
+		"OPENAI_API_KEY": true,
+		"CI":             false,
+		"GOFLAGS":        false,
+	} {
+		if got := IsSecretKey(name); got != want {
+			t.Errorf("IsSecretKey(%q) = %v, want %v", name, got, want)
+		}
+	}
+	if !LooksLikeSecretValue("QUJDREVGR0hJSktMTU5PUFFSU1RVVldYWVo0NTY3ODk=") {
+		t.Error("expected base64 token to look like a secret")
+	}
+	if LooksLikeSecretValue("go version go1.24.0 linux/amd64") {
+		t.Error("expected tool version string not to look like a secret")
+	}
+}
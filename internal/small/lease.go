@@ -0,0 +1,26 @@
+package small
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// validateTaskLease checks a plan task's lease: an agent_id and an RFC3339 expires.
+func validateTaskLease(raw any) error {
+	lease, ok := raw.(map[string]any)
+	if !ok {
+		return fmt.Errorf("lease must be an object with agent_id and expires")
+	}
+	if s, _ := lease["agent_id"].(string); strings.TrimSpace(s) == "" {
+		return fmt.Errorf("lease.agent_id must be a non-empty string")
+	}
+	if _, ok := lease["expires"].(time.Time); ok {
+		return nil
+	}
+	expires := stringVal(lease["expires"])
+	if _, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(expires)); err != nil {
+		return fmt.Errorf("lease.expires %q must be an RFC3339 timestamp", expires)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package small
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCheckInvariants_PlanLease(t *testing.T) {
+	artifacts := map[string]*Artifact{
+		"plan": {
+			Path: "test/plan.small.yml",
+			Type: "plan",
+			Data: map[string]any{
+				"small_version": ProtocolVersion,
+				"owner":         "agent",
+				"tasks": []any{
+					map[string]any{"id": "task-1", "title": "One", "lease": map[string]any{"agent_id": "agent-a", "expires": "2026-01-02T10:00:00Z"}},
+					map[string]any{"id": "task-2", "title": "Two", "lease": map[string]any{"agent_id": "agent-a", "expires": time.Now()}},
+					map[string]any{"id": "task-3", "title": "Three", "lease": map[string]any{"expires": "2026-01-02T10:00:00Z"}},
+					map[string]any{"id": "task-4", "title": "Four", "lease": map[string]any{"agent_id": "agent-a", "expires": "tomorrow"}},
+				},
+			},
+		},
+	}
+	violations := CheckInvariants(artifacts, false)
+	if len(violations) != 2 || !contains(violations[0].Message, "tasks[2].lease.agent_id") || !contains(violations[1].Message, `tasks[3].lease.expires "tomorrow"`) {
+		t.Fatalf("unexpected violations: %+v", violations)
+	}
+}
@@ -26,6 +26,15 @@ type Artifact struct {
 	Data map[string]any
 	Path string
 	Type string
+	// LedgerPath is set on a progress artifact whose entries continue in the JSON Lines
+	// ledger; entries from LedgerStart on were read from it, and LedgerLines holds the
+	// ledger line number of each.
+	LedgerPath  string
+	LedgerStart int
+	LedgerLines []int
+	// ChainRequired is set on a progress artifact whose workspace requires the progress
+	// hash chain.
+	ChainRequired bool
 }
 
 func LoadArtifact(baseDir, filename string) (*Artifact, error) {
@@ -43,11 +52,18 @@ func LoadArtifact(baseDir, filename string) (*Artifact, error) {
 
 	artifactType := filename[:len(filename)-len(".small.yml")]
 
-	return &Artifact{
+	loaded := &Artifact{
 		Data: artifact,
 		Path: path,
 		Type: artifactType,
-	}, nil
+	}
+	if filename == "progress.small.yml" {
+		if err := mergeProgressLedger(baseDir, loaded); err != nil {
+			return nil, err
+		}
+		loaded.ChainRequired = ProgressChainRequired(baseDir)
+	}
+	return loaded, nil
 }
 
 func LoadAllArtifacts(baseDir string) (map[string]*Artifact, error) {
@@ -80,6 +96,10 @@ func SaveArtifact(baseDir, filename string, data map[string]any) error {
 	if err := os.WriteFile(path, yamlData, 0644); err != nil {
 		return fmt.Errorf("failed to write %s: %w", path, err)
 	}
+	// Saved progress holds every entry, including those loaded from the ledger.
+	if filename == "progress.small.yml" {
+		return ResetProgressLedger(ProgressLedgerPath(baseDir))
+	}
 
 	return nil
 }
@@ -0,0 +1,286 @@
+package small
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LockFileName is the advisory lock file every SMALL writer holds under .small/.
+const LockFileName = ".lock"
+
+// LockTimeoutEnvVar overrides how long writers wait for a contended lock.
+const LockTimeoutEnvVar = "SMALL_LOCK_TIMEOUT"
+
+const (
+	defaultLockWaitTimeout = 10 * time.Second
+	lockPollInterval       = 50 * time.Millisecond
+	maxLockCommandLength   = 200
+)
+
+// LockWaitTimeout is how long writers wait for the workspace lock. Zero means
+// SMALL_LOCK_TIMEOUT, or 10s when that is unset.
+var LockWaitTimeout time.Duration
+
+// LockAgentID reports the agent id recorded on the lock while it is held.
+var LockAgentID = func() string { return strings.TrimSpace(os.Getenv("SMALL_AGENT_ID")) }
+
+// LockHolder describes the process holding the workspace lock.
+type LockHolder struct {
+	PID        int    `yaml:"pid"`
+	Command    string `yaml:"command,omitempty"`
+	Agent      string `yaml:"agent,omitempty"`
+	AcquiredAt string `yaml:"acquired_at"`
+}
+
+func (h *LockHolder) String() string {
+	if h == nil {
+		return "an unknown process"
+	}
+	parts := []string{"pid " + strconv.Itoa(h.PID)}
+	if h.Command != "" {
+		parts = append(parts, "command "+strconv.Quote(h.Command))
+	}
+	if h.Agent != "" {
+		parts = append(parts, "agent "+h.Agent)
+	}
+	if h.AcquiredAt != "" {
+		parts = append(parts, "since "+h.AcquiredAt)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// LockContendedError is returned when another process held the lock for the whole wait.
+type LockContendedError struct {
+	Path   string
+	Holder *LockHolder
+	Waited time.Duration
+}
+
+func (e *LockContendedError) Error() string {
+	return fmt.Sprintf("%s is held by %s; gave up after %s (set --lock-timeout or %s to wait longer, or run 'small lock break --force' if the holder is gone)",
+		e.Path, e.Holder, e.Waited, LockTimeoutEnvVar)
+}
+
+// heldLocks tracks locks this process holds so nested writers (a plan save that touches
+// workspace metadata, for example) reuse the lock instead of waiting on themselves.
+// In-process serialisation is left to callers.
+var heldLocks = struct {
+	sync.Mutex
+	files map[string]*heldLock
+}{files: map[string]*heldLock{}}
+
+type heldLock struct {
+	file  *os.File
+	count int
+}
+
+// LockPath returns the workspace lock file path for baseDir.
+func LockPath(baseDir string) string {
+	return filepath.Join(baseDir, SmallDir, LockFileName)
+}
+
+// WithWorkspaceLock runs fn while holding the workspace lock.
+func WithWorkspaceLock(baseDir string, fn func() error) error {
+	unlock, err := LockWorkspace(baseDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+// LockWorkspace takes the exclusive lock on .small/.lock, waiting up to the configured
+// timeout, and returns the function that releases it. Without a .small/ directory there
+// is nothing to protect and no lock is taken.
+func LockWorkspace(baseDir string) (func(), error) {
+	if info, err := os.Stat(filepath.Join(baseDir, SmallDir)); err != nil || !info.IsDir() {
+		return func() {}, nil
+	}
+	path, err := filepath.Abs(LockPath(baseDir))
+	if err != nil {
+		path = LockPath(baseDir)
+	}
+
+	timeout := lockWaitTimeout()
+	deadline := time.Now().Add(timeout)
+	for {
+		unlock, acquired, err := acquireWorkspaceLock(path)
+		if err != nil || acquired {
+			return unlock, err
+		}
+		if !time.Now().Before(deadline) {
+			holder, _ := ReadLockHolder(path)
+			return nil, &LockContendedError{Path: path, Holder: holder, Waited: timeout}
+		}
+		// Sleep without heldLocks so other goroutines can release or reuse their locks.
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// acquireWorkspaceLock makes one attempt at the lock on path under heldLocks. It reuses
+// a lock this process already holds, which another goroutine may have taken while the
+// caller slept.
+func acquireWorkspaceLock(path string) (func(), bool, error) {
+	heldLocks.Lock()
+	defer heldLocks.Unlock()
+	if held, ok := heldLocks.files[path]; ok {
+		held.count++
+		return func() { releaseWorkspaceLock(path) }, true, nil
+	}
+	file, acquired, err := tryAcquireLockFile(path)
+	if err != nil || !acquired {
+		return nil, false, err
+	}
+	writeLockHolder(file)
+	heldLocks.files[path] = &heldLock{file: file, count: 1}
+	return func() { releaseWorkspaceLock(path) }, true, nil
+}
+
+func lockWaitTimeout() time.Duration {
+	if LockWaitTimeout > 0 {
+		return LockWaitTimeout
+	}
+	if value := strings.TrimSpace(os.Getenv(LockTimeoutEnvVar)); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+			return d
+		}
+	}
+	return defaultLockWaitTimeout
+}
+
+// tryAcquireLockFile opens and locks path without blocking. A lock taken on a file that
+// 'small lock break' removed in the meantime is dropped so the caller retries.
+func tryAcquireLockFile(path string) (*os.File, bool, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	acquired, err := tryLockFile(file)
+	if err != nil {
+		file.Close()
+		return nil, false, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	if !acquired {
+		file.Close()
+		return nil, false, nil
+	}
+	opened, statErr := file.Stat()
+	current, pathErr := os.Stat(path)
+	if statErr != nil || pathErr != nil || !os.SameFile(opened, current) {
+		_ = unlockFile(file)
+		file.Close()
+		return nil, false, nil
+	}
+	return file, true, nil
+}
+
+func writeLockHolder(file *os.File) {
+	command := strings.Join(append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...), " ")
+	if len(command) > maxLockCommandLength {
+		command = command[:maxLockCommandLength] + "..."
+	}
+	data, err := yaml.Marshal(LockHolder{
+		PID:        os.Getpid(),
+		Command:    command,
+		Agent:      LockAgentID(),
+		AcquiredAt: time.Now().UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		return
+	}
+	if err := file.Truncate(0); err != nil {
+		return
+	}
+	_, _ = file.WriteAt(data, 0)
+}
+
+func releaseWorkspaceLock(path string) {
+	heldLocks.Lock()
+	defer heldLocks.Unlock()
+	held, ok := heldLocks.files[path]
+	if !ok {
+		return
+	}
+	held.count--
+	if held.count > 0 {
+		return
+	}
+	delete(heldLocks.files, path)
+	_ = held.file.Truncate(0)
+	_ = unlockFile(held.file)
+	_ = held.file.Close()
+}
+
+// ReadLockHolder reads the holder recorded in the lock file at path. It returns nil when
+// the file is missing or empty.
+func ReadLockHolder(path string) (*LockHolder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return nil, nil
+	}
+	var holder LockHolder
+	if err := yaml.Unmarshal(data, &holder); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &holder, nil
+}
+
+// LockStatus reports whether the workspace lock is currently held.
+type LockStatus struct {
+	Path   string
+	Held   bool
+	Holder *LockHolder
+	// HolderAlive is false when the recorded holder process no longer exists.
+	HolderAlive bool
+}
+
+// InspectWorkspaceLock probes the workspace lock without waiting.
+func InspectWorkspaceLock(baseDir string) (LockStatus, error) {
+	status := LockStatus{Path: LockPath(baseDir)}
+	if _, err := os.Stat(status.Path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return status, nil
+		}
+		return status, err
+	}
+	file, acquired, err := tryAcquireLockFile(status.Path)
+	if err != nil {
+		return status, err
+	}
+	if acquired {
+		_ = unlockFile(file)
+		file.Close()
+		return status, nil
+	}
+	status.Held = true
+	status.Holder, err = ReadLockHolder(status.Path)
+	if err != nil {
+		return status, err
+	}
+	status.HolderAlive = status.Holder != nil && processAlive(status.Holder.PID)
+	return status, nil
+}
+
+// BreakWorkspaceLock removes the lock file so new writers lock a fresh one. A process
+// still holding the old file keeps running unaware, so only break locks whose holder
+// is gone or hung.
+func BreakWorkspaceLock(baseDir string) error {
+	if err := os.Remove(LockPath(baseDir)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove %s: %w", LockPath(baseDir), err)
+	}
+	return nil
+}
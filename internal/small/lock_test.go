@@ -0,0 +1,137 @@
+package small
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newLockWorkspace(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, SmallDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestLockWorkspaceIsReentrant(t *testing.T) {
+	dir := newLockWorkspace(t)
+
+	outer, err := LockWorkspace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inner, err := LockWorkspace(dir)
+	if err != nil {
+		t.Fatalf("nested lock should not wait on itself: %v", err)
+	}
+	inner()
+
+	status, err := InspectWorkspaceLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Held || status.Holder == nil || status.Holder.PID != os.Getpid() || !status.HolderAlive {
+		t.Fatalf("expected this process to hold the lock, got %+v", status)
+	}
+
+	outer()
+	status, err = InspectWorkspaceLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Held {
+		t.Fatalf("expected the lock to be released, got %+v", status)
+	}
+}
+
+func TestLockWorkspaceContended(t *testing.T) {
+	dir := newLockWorkspace(t)
+	previous := LockWaitTimeout
+	LockWaitTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { LockWaitTimeout = previous })
+
+	// Another open file description stands in for a second process.
+	other, acquired, err := tryAcquireLockFile(LockPath(dir))
+	if err != nil || !acquired {
+		t.Fatalf("failed to take the lock: %v", err)
+	}
+	defer other.Close()
+	if _, err := other.WriteString("pid: 4242\ncommand: small apply --task task-1\nagent: agent-b\nacquired_at: \"2026-01-02T10:00:00Z\"\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LockWorkspace(dir)
+	var contended *LockContendedError
+	if !errors.As(err, &contended) {
+		t.Fatalf("expected LockContendedError, got %v", err)
+	}
+	for _, want := range []string{"pid 4242", `"small apply --task task-1"`, "agent agent-b", "small lock break --force"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %s", err, want)
+		}
+	}
+
+	if err := BreakWorkspaceLock(dir); err != nil {
+		t.Fatal(err)
+	}
+	unlock, err := LockWorkspace(dir)
+	if err != nil {
+		t.Fatalf("expected a fresh lock after break, got %v", err)
+	}
+	unlock()
+}
+
+func TestLockWorkspaceWaitDoesNotBlockOtherWorkspaces(t *testing.T) {
+	contendedDir := newLockWorkspace(t)
+	freeDir := newLockWorkspace(t)
+	previous := LockWaitTimeout
+	LockWaitTimeout = 5 * time.Second
+	t.Cleanup(func() { LockWaitTimeout = previous })
+
+	other, acquired, err := tryAcquireLockFile(LockPath(contendedDir))
+	if err != nil || !acquired {
+		t.Fatalf("failed to take the lock: %v", err)
+	}
+	waited := make(chan error, 1)
+	go func() {
+		unlock, err := LockWorkspace(contendedDir)
+		if err == nil {
+			unlock()
+		}
+		waited <- err
+	}()
+	time.Sleep(2 * lockPollInterval)
+
+	start := time.Now()
+	unlock, err := LockWorkspace(freeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("locking a free workspace waited %s behind a contended one", elapsed)
+	}
+
+	_ = unlockFile(other)
+	other.Close()
+	if err := <-waited; err != nil {
+		t.Fatalf("expected the waiter to get the lock once it was released, got %v", err)
+	}
+}
+
+func TestLockWorkspaceWithoutSmallDir(t *testing.T) {
+	dir := t.TempDir()
+	unlock, err := LockWorkspace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unlock()
+	if _, err := os.Stat(filepath.Join(dir, SmallDir)); !os.IsNotExist(err) {
+		t.Fatalf("expected no .small/ to be created, got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package small
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// HumanOwnedArtifacts are the canonical artifacts that only humans may edit.
+var HumanOwnedArtifacts = []string{"intent.small.yml", "constraints.small.yml"}
+
+// AgentTrailerKey is the commit trailer agents add to mark commits they made, for
+// example "Small-Agent: builder-1".
+const AgentTrailerKey = "Small-Agent"
+
+// OwnershipAuditOptions configures AuditHumanOwnership.
+type OwnershipAuditOptions struct {
+	// Since limits the audit to commits after this git ref; empty audits all history.
+	Since string
+	// Humans are the names or emails allowed to edit human-owned artifacts. When set,
+	// commits authored by anyone else fail the audit.
+	Humans []string
+	// AgentIDs are agent identities recorded by SMALL commands. Commits authored or
+	// committed under one of them fail the audit, unless it is listed in Humans or uses
+	// the human: prefix.
+	AgentIDs []string
+}
+
+// OwnershipViolation is a commit that changed a human-owned artifact on behalf of an agent.
+type OwnershipViolation struct {
+	Artifact string
+	Commit   string
+	Author   string
+	Reason   string
+}
+
+type auditCommit struct {
+	hash           string
+	authorName     string
+	authorEmail    string
+	committerName  string
+	committerEmail string
+	agentTrailers  []string
+}
+
+// AuditHumanOwnership walks the git history of the human-owned artifacts under baseDir
+// and reports each commit attributed to an agent: one carrying a Small-Agent trailer,
+// one whose author or committer is a recorded agent identity, or, when opts.Humans is
+// set, one whose author is not listed there. Uncommitted changes are not audited.
+func AuditHumanOwnership(baseDir string, opts OwnershipAuditOptions) ([]OwnershipViolation, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git not found in PATH")
+	}
+	if _, err := runGitOutput(baseDir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, fmt.Errorf("%s is not inside a git work tree", baseDir)
+	}
+	if _, err := runGitOutput(baseDir, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		// No commits yet, so nothing to audit.
+		return nil, nil
+	}
+	revision := "HEAD"
+	if since := strings.TrimSpace(opts.Since); since != "" {
+		if _, err := runGitOutput(baseDir, "rev-parse", "--verify", "--quiet", since+"^{commit}"); err != nil {
+			return nil, fmt.Errorf("unknown git ref %q", since)
+		}
+		revision = since + "..HEAD"
+	}
+
+	humans := identitySet(opts.Humans)
+	agents := map[string]string{}
+	for _, id := range opts.AgentIDs {
+		key := strings.ToLower(strings.TrimSpace(id))
+		if key == "" || strings.HasPrefix(key, "human:") || humans[key] {
+			continue
+		}
+		agents[key] = strings.TrimSpace(id)
+	}
+
+	var violations []OwnershipViolation
+	for _, artifact := range HumanOwnedArtifacts {
+		commits, err := artifactCommits(baseDir, revision, path.Join(SmallDir, artifact))
+		if err != nil {
+			return nil, err
+		}
+		for _, commit := range commits {
+			reason := agentAttribution(commit, humans, agents)
+			if reason == "" {
+				continue
+			}
+			violations = append(violations, OwnershipViolation{
+				Artifact: artifact,
+				Commit:   commit.hash,
+				Author:   fmt.Sprintf("%s <%s>", commit.authorName, commit.authorEmail),
+				Reason:   reason,
+			})
+		}
+	}
+	return violations, nil
+}
+
+func artifactCommits(baseDir, revision, relPath string) ([]auditCommit, error) {
+	format := "--format=%H%x1f%an%x1f%ae%x1f%cn%x1f%ce%x1f%(trailers:key=" + AgentTrailerKey + ",valueonly,separator=%x1d)%x1e"
+	output, err := runGitOutput(baseDir, "log", "--no-color", format, revision, "--", relPath)
+	if err != nil {
+		return nil, fmt.Errorf("git log for %s failed: %w", relPath, err)
+	}
+	var commits []auditCommit
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.Split(strings.TrimLeft(record, "\n"), "\x1f")
+		if len(fields) < 6 {
+			continue
+		}
+		commit := auditCommit{
+			hash:           fields[0],
+			authorName:     fields[1],
+			authorEmail:    fields[2],
+			committerName:  fields[3],
+			committerEmail: fields[4],
+		}
+		for _, value := range strings.Split(fields[5], "\x1d") {
+			if value = strings.TrimSpace(value); value != "" {
+				commit.agentTrailers = append(commit.agentTrailers, value)
+			}
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// agentAttribution explains why commit is attributed to an agent, or returns "".
+func agentAttribution(commit auditCommit, humans map[string]bool, agents map[string]string) string {
+	if len(commit.agentTrailers) > 0 {
+		return fmt.Sprintf("commit carries %s: %s", AgentTrailerKey, strings.Join(commit.agentTrailers, ", "))
+	}
+	for _, who := range []struct{ role, name, email string }{
+		{"author", commit.authorName, commit.authorEmail},
+		{"committer", commit.committerName, commit.committerEmail},
+	} {
+		for _, value := range []string{who.name, who.email} {
+			if id, ok := agents[strings.ToLower(strings.TrimSpace(value))]; ok {
+				return fmt.Sprintf("%s %q matches agent id %q recorded by SMALL", who.role, value, id)
+			}
+		}
+	}
+	if len(humans) > 0 && !humans[strings.ToLower(commit.authorName)] && !humans[strings.ToLower(commit.authorEmail)] {
+		return "author is not in the human allowlist"
+	}
+	return ""
+}
+
+// identitySet indexes names and emails case-insensitively. A "Name <email>" entry is
+// matched by its email only, since anyone can commit under any name.
+func identitySet(identities []string) map[string]bool {
+	set := map[string]bool{}
+	for _, identity := range identities {
+		identity = strings.TrimSpace(identity)
+		if open := strings.LastIndex(identity, "<"); open >= 0 && strings.HasSuffix(identity, ">") {
+			identity = identity[open+1 : len(identity)-1]
+		}
+		if identity = strings.TrimSpace(identity); identity != "" {
+			set[strings.ToLower(identity)] = true
+		}
+	}
+	return set
+}
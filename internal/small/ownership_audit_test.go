@@ -0,0 +1,94 @@
+package small
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func commitAs(t *testing.T, dir, name, email, message string) {
+	t.Helper()
+	for _, args := range [][]string{
+		{"add", "-A"},
+		{"-c", "user.name=" + name, "-c", "user.email=" + email, "-c", "commit.gpgsign=false", "commit", "-q", "-m", message},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+}
+
+func writeIntent(t *testing.T, dir, text string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, SmallDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	data := "small_version: \"1.0.0\"\nowner: \"human\"\nintent: \"" + text + "\"\n"
+	if err := os.WriteFile(filepath.Join(dir, SmallDir, "intent.small.yml"), []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuditHumanOwnership(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, out)
+	}
+
+	writeIntent(t, dir, "v1")
+	commitAs(t, dir, "Alice", "alice@example.com", "Write intent")
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("unrelated\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	commitAs(t, dir, "builder-1", "builder-1@agents.local", "Unrelated change")
+
+	violations, err := AuditHumanOwnership(dir, OwnershipAuditOptions{AgentIDs: []string{"builder-1", "human:alice"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("expected human edits and agent commits elsewhere to pass, got %+v", violations)
+	}
+
+	writeIntent(t, dir, "v2")
+	commitAs(t, dir, "Alice", "alice@example.com", "Tweak intent\n\nSmall-Agent: builder-2")
+	writeIntent(t, dir, "v3")
+	commitAs(t, dir, "builder-1", "builder-1@agents.local", "Agent edit")
+	writeIntent(t, dir, "v4")
+	commitAs(t, dir, "Mallory", "mallory@example.com", "Unknown author")
+
+	violations, err = AuditHumanOwnership(dir, OwnershipAuditOptions{AgentIDs: []string{"builder-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) != 2 ||
+		!strings.Contains(violations[0].Reason, `author "builder-1" matches agent id`) ||
+		!strings.Contains(violations[1].Reason, "Small-Agent: builder-2") {
+		t.Fatalf("expected the agent-id and trailer commits to fail, got %+v", violations)
+	}
+
+	violations, err = AuditHumanOwnership(dir, OwnershipAuditOptions{Humans: []string{"Alice <alice@example.com>"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) != 3 || violations[0].Author != "Mallory <mallory@example.com>" || !strings.Contains(violations[0].Reason, "allowlist") {
+		t.Fatalf("expected authors outside the allowlist to fail, got %+v", violations)
+	}
+
+	violations, err = AuditHumanOwnership(dir, OwnershipAuditOptions{Since: "HEAD~1", Humans: []string{"mallory@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(violations) != 0 {
+		t.Fatalf("expected --since to skip older commits, got %+v", violations)
+	}
+}
@@ -0,0 +1,155 @@
+package small
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DependencyCycles returns the dependency cycles in a plan, each as the task ids along
+// the cycle with the first id repeated at the end. ids lists the tasks in plan order and
+// deps maps a task to the tasks it depends on; dependencies on unknown ids are ignored.
+// Every task lies on at most one reported cycle.
+func DependencyCycles(ids []string, deps map[string][]string) [][]string {
+	known := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		known[id] = true
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(ids))
+	var stack []string
+	var cycles [][]string
+	onCycle := map[string]bool{}
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		stack = append(stack, id)
+		for _, dep := range deps[id] {
+			if !known[dep] {
+				continue
+			}
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				start := len(stack) - 1
+				for stack[start] != dep {
+					start--
+				}
+				cycle := append([]string{}, stack[start:]...)
+				fresh := true
+				for _, member := range cycle {
+					if onCycle[member] {
+						fresh = false
+					}
+				}
+				if fresh {
+					for _, member := range cycle {
+						onCycle[member] = true
+					}
+					cycles = append(cycles, append(cycle, dep))
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = done
+	}
+
+	for _, id := range ids {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+	return cycles
+}
+
+// validatePlanDependencies rejects dependencies on task ids missing from the plan and
+// dependency cycles, either of which leaves the dependent tasks unreachable.
+func validatePlanDependencies(path string, tasks []any) []InvariantViolation {
+	var v []InvariantViolation
+	var ids []string
+	known := map[string]bool{}
+	for _, t := range tasks {
+		if m, ok := t.(map[string]any); ok {
+			if id := strings.TrimSpace(stringVal(m["id"])); id != "" {
+				ids = append(ids, id)
+				known[id] = true
+			}
+		}
+	}
+
+	deps := map[string][]string{}
+	for i, t := range tasks {
+		m, ok := t.(map[string]any)
+		if !ok || m["dependencies"] == nil {
+			continue
+		}
+		id := strings.TrimSpace(stringVal(m["id"]))
+		list, ok := m["dependencies"].([]any)
+		if !ok {
+			v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("tasks[%d].dependencies must be an array of task ids", i)})
+			continue
+		}
+		for _, raw := range list {
+			dep := strings.TrimSpace(stringVal(raw))
+			if !known[dep] {
+				v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("task %s depends on unknown task %q", id, dep)})
+				continue
+			}
+			deps[id] = append(deps[id], dep)
+		}
+	}
+
+	for _, cycle := range DependencyCycles(ids, deps) {
+		v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("dependency cycle: %s", strings.Join(cycle, " -> "))})
+	}
+	return v
+}
+
+// validatePlanSupersession checks superseded_by links: each must name another task in the
+// plan, sit on a cancelled task, and not lead back to itself.
+func validatePlanSupersession(path string, tasks []any) []InvariantViolation {
+	var v []InvariantViolation
+	var ids []string
+	known := map[string]bool{}
+	for _, t := range tasks {
+		if m, ok := t.(map[string]any); ok {
+			id := strings.TrimSpace(stringVal(m["id"]))
+			ids = append(ids, id)
+			known[id] = true
+		}
+	}
+
+	links := map[string][]string{}
+	for i, t := range tasks {
+		m, ok := t.(map[string]any)
+		if !ok || m["superseded_by"] == nil {
+			continue
+		}
+		id := strings.TrimSpace(stringVal(m["id"]))
+		by, ok := m["superseded_by"].(string)
+		if !ok || strings.TrimSpace(by) == "" {
+			v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("tasks[%d].superseded_by must be a non-empty string", i)})
+			continue
+		}
+		by = strings.TrimSpace(by)
+		if !known[by] {
+			v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("task %s is superseded by unknown task %q", id, by)})
+			continue
+		}
+		if status := strings.TrimSpace(stringVal(m["status"])); status != "cancelled" {
+			v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("task %s is superseded by %s but is not cancelled", id, by)})
+		}
+		links[id] = []string{by}
+	}
+
+	for _, cycle := range DependencyCycles(ids, links) {
+		v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("superseded_by cycle: %s", strings.Join(cycle, " -> "))})
+	}
+	return v
+}
@@ -0,0 +1,126 @@
+package small
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDependencyCycles(t *testing.T) {
+	ids := []string{"a", "b", "c", "d", "e"}
+	deps := map[string][]string{
+		"a": {"b"},
+		"b": {"c", "missing"},
+		"c": {"a"},
+		"d": {"d"},
+		"e": {"a"},
+	}
+	got := DependencyCycles(ids, deps)
+	want := [][]string{{"a", "b", "c", "a"}, {"d", "d"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("DependencyCycles() = %v, want %v", got, want)
+	}
+
+	if cycles := DependencyCycles(ids, map[string][]string{"b": {"a"}, "c": {"a", "b"}}); len(cycles) != 0 {
+		t.Fatalf("expected acyclic plan, got %v", cycles)
+	}
+}
+
+func TestCheckInvariants_PlanDependencies(t *testing.T) {
+	artifacts := map[string]*Artifact{
+		"plan": {
+			Path: "test/plan.small.yml",
+			Type: "plan",
+			Data: map[string]any{
+				"small_version": ProtocolVersion,
+				"owner":         "agent",
+				"tasks": []any{
+					map[string]any{"id": "task-1", "title": "One", "dependencies": []any{"task-2"}},
+					map[string]any{"id": "task-2", "title": "Two", "dependencies": []any{"task-1"}},
+					map[string]any{"id": "task-3", "title": "Three", "dependencies": []any{"task-9"}},
+				},
+			},
+		},
+	}
+
+	var messages []string
+	for _, v := range CheckInvariants(artifacts, false) {
+		messages = append(messages, v.Message)
+	}
+	want := []string{
+		`task task-3 depends on unknown task "task-9"`,
+		"dependency cycle: task-1 -> task-2 -> task-1",
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Fatalf("violations = %q, want %q", messages, want)
+	}
+}
+
+func TestCheckInvariants_PlanSupersession(t *testing.T) {
+	artifacts := map[string]*Artifact{
+		"plan": {
+			Path: "test/plan.small.yml",
+			Type: "plan",
+			Data: map[string]any{
+				"small_version": ProtocolVersion,
+				"owner":         "agent",
+				"tasks": []any{
+					map[string]any{"id": "task-1", "title": "Old", "status": "cancelled", "superseded_by": "task-2"},
+					map[string]any{"id": "task-2", "title": "New", "status": "pending", "superseded_by": "task-1"},
+					map[string]any{"id": "task-3", "title": "Gone", "status": "cancelled", "superseded_by": "task-9"},
+				},
+			},
+		},
+	}
+
+	var messages []string
+	for _, v := range CheckInvariants(artifacts, false) {
+		messages = append(messages, v.Message)
+	}
+	want := []string{
+		"task task-2 is superseded by task-1 but is not cancelled",
+		`task task-3 is superseded by unknown task "task-9"`,
+		"superseded_by cycle: task-1 -> task-2 -> task-1",
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Fatalf("violations = %q, want %q", messages, want)
+	}
+}
+
+func TestCancelledTasksAreTerminal(t *testing.T) {
+	plan := &Artifact{
+		Path: "test/plan.small.yml",
+		Type: "plan",
+		Data: map[string]any{
+			"small_version": ProtocolVersion,
+			"owner":         "agent",
+			"tasks": []any{
+				map[string]any{"id": "task-1", "title": "Dropped", "status": "cancelled"},
+				map[string]any{"id": "task-2", "title": "Dropped silently", "status": "cancelled"},
+			},
+		},
+	}
+	progress := &Artifact{
+		Path: "test/progress.small.yml",
+		Type: "progress",
+		Data: map[string]any{
+			"small_version": ProtocolVersion,
+			"owner":         "agent",
+			"entries": []any{
+				map[string]any{
+					"task_id":   "task-1",
+					"status":    "cancelled",
+					"timestamp": "2025-01-01T00:00:00.000000001Z",
+					"evidence":  "Covered upstream",
+				},
+			},
+		},
+	}
+
+	if dangling := CheckDanglingTasks(plan, progress); len(dangling) != 0 {
+		t.Fatalf("cancelled tasks should not dangle, got %+v", dangling)
+	}
+	violations := validateStrictPlanTaskEvidence(plan, progress)
+	if len(violations) != 1 || !contains(violations[0].Message, "task-2") || contains(violations[0].Message, "task-1 ") {
+		t.Fatalf("expected S1 to require a reason for task-2 only, got %+v", violations)
+	}
+}
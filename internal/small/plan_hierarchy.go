@@ -0,0 +1,89 @@
+package small
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// parentTaskIDs returns the ids of tasks that other tasks name as their parent. A parent's
+// status is derived from its subtasks, so evidence rules apply to leaf tasks only.
+func parentTaskIDs(tasks []planTask) map[string]bool {
+	parents := map[string]bool{}
+	for _, task := range tasks {
+		if task.Parent != "" {
+			parents[task.Parent] = true
+		}
+	}
+	return parents
+}
+
+// validatePlanHierarchy checks parent links and milestones: a parent must be another task
+// in the plan, parent chains must not loop, and a parent may only be completed once every
+// subtask is.
+func validatePlanHierarchy(path string, tasks []any) []InvariantViolation {
+	var v []InvariantViolation
+	parents := map[string]string{}
+	statuses := map[string]string{}
+	var ids []string
+	for i, t := range tasks {
+		m, ok := t.(map[string]any)
+		if !ok {
+			continue
+		}
+		id := strings.TrimSpace(stringVal(m["id"]))
+		ids = append(ids, id)
+		statuses[id] = strings.TrimSpace(stringVal(m["status"]))
+		if raw, ok := m["milestone"]; ok {
+			if s, ok := raw.(string); !ok || strings.TrimSpace(s) == "" {
+				v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("tasks[%d].milestone must be a non-empty string", i)})
+			}
+		}
+		if raw, ok := m["parent"]; ok {
+			parent, ok := raw.(string)
+			if !ok || strings.TrimSpace(parent) == "" {
+				v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("tasks[%d].parent must be a non-empty string", i)})
+				continue
+			}
+			parents[id] = strings.TrimSpace(parent)
+		}
+	}
+
+	children := map[string][]string{}
+	for _, id := range ids {
+		parent, ok := parents[id]
+		if !ok {
+			continue
+		}
+		if _, known := statuses[parent]; !known {
+			v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("task %s has unknown parent %q", id, parent)})
+			continue
+		}
+		children[parent] = append(children[parent], id)
+	}
+
+	links := make(map[string][]string, len(parents))
+	for id, parent := range parents {
+		links[id] = []string{parent}
+	}
+	for _, cycle := range DependencyCycles(ids, links) {
+		v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("parent cycle: %s", strings.Join(cycle, " -> "))})
+	}
+
+	for _, id := range ids {
+		if strings.ToLower(statuses[id]) != "completed" || len(children[id]) == 0 {
+			continue
+		}
+		var open []string
+		for _, child := range children[id] {
+			if status := strings.ToLower(statuses[child]); status != "completed" && status != "cancelled" {
+				open = append(open, child)
+			}
+		}
+		if len(open) > 0 {
+			sort.Strings(open)
+			v = append(v, InvariantViolation{File: path, Message: fmt.Sprintf("task %s is completed but subtasks are not: %s", id, strings.Join(open, ", "))})
+		}
+	}
+	return v
+}
@@ -0,0 +1,88 @@
+package small
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckInvariants_PlanHierarchy(t *testing.T) {
+	artifacts := map[string]*Artifact{
+		"plan": {
+			Path: "test/plan.small.yml",
+			Type: "plan",
+			Data: map[string]any{
+				"small_version": ProtocolVersion,
+				"owner":         "agent",
+				"tasks": []any{
+					map[string]any{"id": "task-1", "title": "Epic", "status": "completed", "milestone": "beta"},
+					map[string]any{"id": "task-2", "title": "Child", "parent": "task-1", "status": "pending"},
+					map[string]any{"id": "task-3", "title": "Orphan", "parent": "task-9"},
+					map[string]any{"id": "task-4", "title": "Loop A", "parent": "task-5"},
+					map[string]any{"id": "task-5", "title": "Loop B", "parent": "task-4", "milestone": ""},
+					map[string]any{"id": "task-6", "title": "Dropped child", "parent": "task-1", "status": "cancelled"},
+				},
+			},
+		},
+	}
+
+	var messages []string
+	for _, v := range CheckInvariants(artifacts, false) {
+		messages = append(messages, v.Message)
+	}
+	want := []string{
+		"tasks[4].milestone must be a non-empty string",
+		`task task-3 has unknown parent "task-9"`,
+		"parent cycle: task-4 -> task-5 -> task-4",
+		"task task-1 is completed but subtasks are not: task-2",
+	}
+	if !reflect.DeepEqual(messages, want) {
+		t.Fatalf("violations = %q, want %q", messages, want)
+	}
+}
+
+func TestCheckInvariants_StrictModeEvidenceSkipsParents(t *testing.T) {
+	artifacts := map[string]*Artifact{
+		"plan": {
+			Path: "test/plan.small.yml",
+			Type: "plan",
+			Data: map[string]any{
+				"small_version": ProtocolVersion,
+				"owner":         "agent",
+				"tasks": []any{
+					map[string]any{"id": "task-1", "title": "Epic", "status": "completed"},
+					map[string]any{"id": "task-2", "title": "Leaf done", "parent": "task-1", "status": "completed"},
+					map[string]any{"id": "task-3", "title": "Leaf missing", "parent": "task-1", "status": "completed"},
+				},
+			},
+		},
+		"progress": {
+			Path: "test/progress.small.yml",
+			Type: "progress",
+			Data: map[string]any{
+				"small_version": ProtocolVersion,
+				"owner":         "agent",
+				"entries": []any{
+					map[string]any{
+						"task_id":   "task-2",
+						"status":    "completed",
+						"timestamp": "2025-01-01T00:00:00.000000001Z",
+						"evidence":  "Tests pass",
+					},
+				},
+			},
+		},
+	}
+
+	var s1Violation string
+	for _, v := range CheckInvariants(artifacts, true) {
+		if contains(v.Message, "strict invariant S1 failed") {
+			s1Violation = v.Message
+		}
+	}
+	if !contains(s1Violation, "task-3") {
+		t.Fatalf("expected S1 violation for leaf task-3, got %q", s1Violation)
+	}
+	if contains(s1Violation, "task-1") || contains(s1Violation, "task-2") {
+		t.Fatalf("S1 should only report leaf tasks without evidence: %s", s1Violation)
+	}
+}
@@ -0,0 +1,154 @@
+package small
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProgressEntryHash returns the sha256 of an entry's canonical JSON: object keys sorted,
+// no insignificant whitespace, no HTML escaping, and entry_hash itself left out. The
+// entry's prev_hash is covered, which is what links it to the entry before it.
+func ProgressEntryHash(entry map[string]any) (string, error) {
+	fields := make(map[string]any, len(entry))
+	for key, value := range entry {
+		if key == "entry_hash" {
+			continue
+		}
+		fields[key] = value
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(fields); err != nil {
+		return "", fmt.Errorf("failed to encode progress entry: %w", err)
+	}
+	sum := sha256.Sum256(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ChainProgressEntry links entry to the entry whose entry_hash is prevHash and stamps its
+// own entry_hash. An empty prevHash makes entry the first link of the chain.
+func ChainProgressEntry(entry map[string]any, prevHash string) error {
+	delete(entry, "prev_hash")
+	delete(entry, "entry_hash")
+	if prevHash != "" {
+		entry["prev_hash"] = prevHash
+	}
+	hash, err := ProgressEntryHash(entry)
+	if err != nil {
+		return err
+	}
+	entry["entry_hash"] = hash
+	return nil
+}
+
+// ProgressEntryChained reports whether entry carries an entry_hash.
+func ProgressEntryChained(entry map[string]any) bool {
+	return strings.TrimSpace(stringVal(entry["entry_hash"])) != ""
+}
+
+// ProgressChainAdopted reports whether any entry in a progress log carries an entry_hash.
+// Logs written before the chain existed have none until they are sealed.
+func ProgressChainAdopted(entries []any) bool {
+	for _, raw := range entries {
+		if entry, ok := raw.(map[string]any); ok && ProgressEntryChained(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProgressChainRequired reports whether workspace.small.yml for baseDir records that
+// its progress log is hash-chained (progress_chain: true). 'small init' and
+// 'small progress seal' set it, so stripping every entry_hash from the entries does
+// not switch the chain check off.
+func ProgressChainRequired(baseDir string) bool {
+	data, err := os.ReadFile(filepath.Join(baseDir, SmallDir, "workspace.small.yml"))
+	if err != nil {
+		return false
+	}
+	var meta struct {
+		ProgressChain bool `yaml:"progress_chain"`
+	}
+	if err := yaml.Unmarshal(data, &meta); err != nil {
+		return false
+	}
+	return meta.ProgressChain
+}
+
+// ProgressChainEnforced reports whether the progress log of baseDir must carry an intact
+// hash chain: the workspace requires it or an entry has adopted it.
+func ProgressChainEnforced(baseDir string, entries []any) bool {
+	return ProgressChainRequired(baseDir) || ProgressChainAdopted(entries)
+}
+
+// SealProgressEntries rewrites the hash chain over entries in order, replacing any
+// prev_hash and entry_hash they already carry.
+func SealProgressEntries(entries []map[string]any) error {
+	prev := ""
+	for i, entry := range entries {
+		if err := ChainProgressEntry(entry, prev); err != nil {
+			return fmt.Errorf("progress entry %d: %w", i, err)
+		}
+		prev = stringVal(entry["entry_hash"])
+	}
+	return nil
+}
+
+// VerifyProgressChain checks the hash chain over entries and returns the index of the
+// first entry that breaks it with the reason, or -1 when the chain is intact.
+func VerifyProgressChain(entries []any) (int, string) {
+	prev := ""
+	for i, raw := range entries {
+		entry, ok := raw.(map[string]any)
+		if !ok {
+			return i, "entry is not an object"
+		}
+		if !ProgressEntryChained(entry) {
+			return i, "entry_hash is missing"
+		}
+		prevHash := stringVal(entry["prev_hash"])
+		switch {
+		case i == 0 && prevHash != "":
+			return i, "the first entry must not have a prev_hash (earlier entries were removed)"
+		case i > 0 && prevHash != prev:
+			return i, fmt.Sprintf("prev_hash does not match entries[%d].entry_hash (entries were removed, reordered, or edited)", i-1)
+		}
+		hash, err := ProgressEntryHash(entry)
+		if err != nil {
+			return i, err.Error()
+		}
+		if hash != stringVal(entry["entry_hash"]) {
+			return i, "entry_hash does not match the entry's content (the entry was edited)"
+		}
+		prev = hash
+	}
+	return -1, ""
+}
+
+// validateStrictProgressChain enforces S6: once the workspace requires the chain or any
+// progress entry carries an entry_hash, every entry must, and each must link to the one
+// before it.
+func validateStrictProgressChain(progressArtifact *Artifact) []InvariantViolation {
+	entries, _ := progressArtifact.Data["entries"].([]any)
+	if !progressArtifact.ChainRequired && !ProgressChainAdopted(entries) {
+		return nil
+	}
+	index, reason := VerifyProgressChain(entries)
+	if index < 0 {
+		return nil
+	}
+	entry, _ := entries[index].(map[string]any)
+	return []InvariantViolation{{
+		File:    progressArtifact.Path,
+		Message: fmt.Sprintf("strict invariant S6 failed: progress hash chain broken at entries[%d] (task %s): %s", index, stringVal(entry["task_id"]), reason),
+	}}
+}
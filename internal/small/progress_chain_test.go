@@ -0,0 +1,113 @@
+package small
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func sealedTestEntries(t *testing.T) []map[string]any {
+	t.Helper()
+	entries := []map[string]any{
+		{"task_id": "meta/init", "timestamp": "2026-01-01T10:00:00.000000000Z", "evidence": "init", "agent": map[string]any{"id": "agent-a"}},
+		{"task_id": "task-1", "timestamp": "2026-01-01T11:00:00.000000000Z", "evidence": "<start> & go", "exit_code": 0, "agent": map[string]any{"id": "agent-a"}},
+		{"task_id": "task-1", "timestamp": "2026-01-01T12:00:00.000000000Z", "evidence": "done", "touched_paths": map[string]any{"modified": []any{"a.go"}}, "agent": map[string]any{"id": "agent-a"}},
+	}
+	if err := SealProgressEntries(entries); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func chainOf(entries ...map[string]any) []any {
+	out := make([]any, len(entries))
+	for i, entry := range entries {
+		out[i] = entry
+	}
+	return out
+}
+
+func TestProgressChainDetectsTampering(t *testing.T) {
+	entries := sealedTestEntries(t)
+	if _, ok := entries[0]["prev_hash"]; ok {
+		t.Fatal("the first entry should not have a prev_hash")
+	}
+	if entries[1]["prev_hash"] != entries[0]["entry_hash"] {
+		t.Fatal("expected entries to be linked by prev_hash")
+	}
+	if index, reason := VerifyProgressChain(chainOf(entries...)); index != -1 {
+		t.Fatalf("expected an intact chain, got entries[%d]: %s", index, reason)
+	}
+
+	cases := map[string]struct {
+		chain []any
+		index int
+	}{
+		"deleted first":  {chainOf(entries[1], entries[2]), 0},
+		"deleted middle": {chainOf(entries[0], entries[2]), 1},
+		"reordered":      {chainOf(entries[0], entries[2], entries[1]), 1},
+	}
+	for name, tc := range cases {
+		if index, _ := VerifyProgressChain(tc.chain); index != tc.index {
+			t.Errorf("%s: expected a break at entries[%d], got %d", name, tc.index, index)
+		}
+	}
+
+	edited := sealedTestEntries(t)
+	edited[1]["evidence"] = "rewritten"
+	if index, reason := VerifyProgressChain(chainOf(edited...)); index != 1 || !contains(reason, "edited") {
+		t.Fatalf("expected an edit at entries[1], got %d: %s", index, reason)
+	}
+}
+
+func TestStrictProgressChainInvariant(t *testing.T) {
+	progress := func(entries []any) map[string]*Artifact {
+		return map[string]*Artifact{
+			"progress": {
+				Path: "test/progress.small.yml",
+				Type: "progress",
+				Data: map[string]any{"small_version": ProtocolVersion, "owner": "agent", "entries": entries},
+			},
+		}
+	}
+	legacy := []any{map[string]any{"task_id": "meta/init", "timestamp": "2026-01-01T10:00:00.000000000Z", "agent": map[string]any{"id": "agent-a"}}}
+	if v := validateStrictInvariants(progress(legacy)); len(v) != 0 {
+		t.Fatalf("an unsealed log should pass, got %+v", v)
+	}
+	required := progress(legacy)
+	required["progress"].ChainRequired = true
+	if v := validateStrictInvariants(required); len(v) != 1 || !contains(v[0].Message, "entry_hash is missing") {
+		t.Fatalf("an unchained log in a chained workspace should fail S6, got %+v", v)
+	}
+
+	entries := sealedTestEntries(t)
+	unchained := map[string]any{"task_id": "task-2", "timestamp": "2026-01-01T13:00:00.000000000Z", "agent": map[string]any{"id": "agent-a"}}
+	v := validateStrictInvariants(progress(chainOf(append(entries, unchained)...)))
+	if len(v) != 1 || !contains(v[0].Message, "strict invariant S6 failed") || !contains(v[0].Message, "entries[3] (task task-2)") {
+		t.Fatalf("unexpected violations: %+v", v)
+	}
+}
+
+func TestProgressChainSurvivesYAMLAndLedger(t *testing.T) {
+	dir := newLockWorkspace(t)
+	entries := sealedTestEntries(t)
+	data, err := MarshalYAMLWithQuotedVersion(map[string]any{"small_version": ProtocolVersion, "owner": "agent", "entries": entries[:2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, SmallDir, "progress.small.yml"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := AppendProgressLedgerEntry(ProgressLedgerPath(dir), entries[2]); err != nil {
+		t.Fatal(err)
+	}
+
+	artifact, err := LoadArtifact(dir, "progress.small.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, _ := artifact.Data["entries"].([]any)
+	if index, reason := VerifyProgressChain(loaded); len(loaded) != 3 || index != -1 {
+		t.Fatalf("expected the reloaded chain to verify, got %d entries, break at %d: %s", len(loaded), index, reason)
+	}
+}
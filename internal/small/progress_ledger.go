@@ -0,0 +1,295 @@
+package small
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ProgressLedgerFileName is the JSON Lines progress ledger. When it exists, new progress
+// entries are appended to it one line at a time instead of rewriting progress.small.yml,
+// and loaders treat its entries as following the ones in progress.small.yml.
+const ProgressLedgerFileName = "progress.small.jsonl"
+
+const ledgerTailChunk = 4096
+
+// ProgressLedgerPath returns the progress ledger path for baseDir.
+func ProgressLedgerPath(baseDir string) string {
+	return filepath.Join(baseDir, SmallDir, ProgressLedgerFileName)
+}
+
+// ProgressLedgerEnabled reports whether baseDir stores new progress entries in the ledger.
+func ProgressLedgerEnabled(baseDir string) bool {
+	info, err := os.Stat(ProgressLedgerPath(baseDir))
+	return err == nil && !info.IsDir()
+}
+
+// ForEachProgressLedgerEntry streams the entries in the ledger at path, calling fn with
+// each entry and its 1-based line number. A missing ledger has no entries. A final line
+// without a newline is a torn append from an interrupted writer and is skipped.
+func ForEachProgressLedgerEntry(path string, fn func(line int, entry map[string]any) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		entry, err := decodeLedgerEntry(data)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if err := fn(line, entry); err != nil {
+			return err
+		}
+	}
+}
+
+// LastProgressLedgerEntry returns the last complete entry in the ledger at path, or nil
+// when it has none. It reads backwards from the end of the file, so the cost does not
+// grow with the ledger. A torn final line is skipped, as in ForEachProgressLedgerEntry.
+func LastProgressLedgerEntry(path string) (map[string]any, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var tail []byte
+	for start := info.Size(); start > 0; {
+		next := start - ledgerTailChunk
+		if next < 0 {
+			next = 0
+		}
+		chunk := make([]byte, start-next)
+		if _, err := file.ReadAt(chunk, next); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		tail = append(chunk, tail...)
+		start = next
+
+		complete := tail[:bytes.LastIndexByte(tail, '\n')+1]
+		body := bytes.TrimRight(complete, " \t\r\n")
+		if len(body) == 0 {
+			continue
+		}
+		i := bytes.LastIndexByte(body, '\n')
+		if i < 0 && start > 0 {
+			continue
+		}
+		entry, err := decodeLedgerEntry(body[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("%s: last entry: %w", path, err)
+		}
+		return entry, nil
+	}
+	return nil, nil
+}
+
+// ReadProgressLedger returns the ledger entries at path that are newer than after, the
+// timestamp of the last entry in progress.small.yml. Older entries were already folded
+// into progress.small.yml by a compaction that was interrupted before it emptied the ledger.
+func ReadProgressLedger(path string, after time.Time) ([]map[string]any, error) {
+	entries := []map[string]any{}
+	err := ForEachProgressLedgerEntry(path, func(_ int, entry map[string]any) error {
+		if ledgerEntryCompacted(entry, after) {
+			return nil
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+func ledgerEntryCompacted(entry map[string]any, after time.Time) bool {
+	if after.IsZero() {
+		return false
+	}
+	ts, ok := progressEntryTime(entry)
+	return ok && !ts.After(after)
+}
+
+// progressEntryTime returns an entry's timestamp, which YAML may have decoded as a time.
+func progressEntryTime(entry map[string]any) (time.Time, bool) {
+	switch value := entry["timestamp"].(type) {
+	case time.Time:
+		return value, true
+	case string:
+		ts, err := ParseProgressTimestamp(value)
+		return ts, err == nil
+	}
+	return time.Time{}, false
+}
+
+// AppendProgressLedgerEntry appends entry to the ledger at path as a single JSON line and
+// fsyncs it. A torn line left by an interrupted append is dropped first. Callers hold
+// the workspace lock.
+func AppendProgressLedgerEntry(path string, entry map[string]any) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode progress entry: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	end, err := trimTornLedgerTail(file)
+	if err != nil {
+		return fmt.Errorf("failed to repair %s: %w", path, err)
+	}
+	if _, err := file.WriteAt(append(line, '\n'), end); err != nil {
+		return fmt.Errorf("failed to append to %s: %w", path, err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", path, err)
+	}
+	return nil
+}
+
+// trimTornLedgerTail truncates file after its last newline and returns the new size.
+func trimTornLedgerTail(file *os.File) (int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	end := size
+	buf := make([]byte, ledgerTailChunk)
+	for end > 0 {
+		start := end - ledgerTailChunk
+		if start < 0 {
+			start = 0
+		}
+		chunk := buf[:end-start]
+		if _, err := file.ReadAt(chunk, start); err != nil && err != io.EOF {
+			return 0, err
+		}
+		if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+			end = start + int64(i) + 1
+			break
+		}
+		end = start
+	}
+	if end != size {
+		if err := file.Truncate(end); err != nil {
+			return 0, err
+		}
+	}
+	return end, nil
+}
+
+// ResetProgressLedger empties the ledger after its entries were folded into
+// progress.small.yml.
+func ResetProgressLedger(path string) error {
+	if err := os.Truncate(path, 0); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to reset %s: %w", path, err)
+	}
+	return nil
+}
+
+func decodeLedgerEntry(data []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var entry map[string]any
+	if err := decoder.Decode(&entry); err != nil {
+		return nil, fmt.Errorf("invalid progress entry: %w", err)
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("invalid progress entry: not a JSON object")
+	}
+	return normalizeLedgerValue(entry).(map[string]any), nil
+}
+
+// normalizeLedgerValue turns JSON numbers into the int or float64 values the YAML loader
+// would produce, so ledger entries and progress.small.yml entries compare and marshal alike.
+func normalizeLedgerValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeLedgerValue(item)
+		}
+		return v
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeLedgerValue(item)
+		}
+		return v
+	case json.Number:
+		if n, err := v.Int64(); err == nil && !strings.ContainsAny(v.String(), ".eE") {
+			return int(n)
+		}
+		if f, err := v.Float64(); err == nil {
+			return f
+		}
+		return v.String()
+	default:
+		return value
+	}
+}
+
+// mergeProgressLedger appends the ledger entries for baseDir to a loaded progress
+// artifact and records where they start and which ledger lines they came from. The
+// ledger is decoded line by line, but every entry is kept in artifact.Data: the ledger
+// makes appends cheap, while loading and the strict invariants still see the whole log.
+func mergeProgressLedger(baseDir string, artifact *Artifact) error {
+	if !ProgressLedgerEnabled(baseDir) {
+		return nil
+	}
+	path := ProgressLedgerPath(baseDir)
+	entries, _ := artifact.Data["entries"].([]any)
+	var last time.Time
+	if len(entries) > 0 {
+		if entry, ok := entries[len(entries)-1].(map[string]any); ok {
+			last, _ = progressEntryTime(entry)
+		}
+	}
+	artifact.LedgerPath = path
+	artifact.LedgerStart = len(entries)
+	artifact.LedgerLines = nil
+	err := ForEachProgressLedgerEntry(path, func(line int, entry map[string]any) error {
+		if ledgerEntryCompacted(entry, last) {
+			return nil
+		}
+		entries = append(entries, entry)
+		artifact.LedgerLines = append(artifact.LedgerLines, line)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if artifact.Data == nil {
+		artifact.Data = map[string]any{}
+	}
+	artifact.Data["entries"] = entries
+	return nil
+}
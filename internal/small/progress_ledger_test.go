@@ -0,0 +1,162 @@
+package small
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const ledgerTestProgress = `small_version: "1.0.0"
+owner: "agent"
+entries:
+  - task_id: "task-1"
+    status: "in_progress"
+    timestamp: "2026-01-01T10:00:00.000000000Z"
+    evidence: "Started"
+`
+
+func newLedgerWorkspace(t *testing.T) string {
+	t.Helper()
+	dir := newLockWorkspace(t)
+	if err := os.WriteFile(filepath.Join(dir, SmallDir, "progress.small.yml"), []byte(ledgerTestProgress), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestProgressLedgerAppendDropsTornLine(t *testing.T) {
+	dir := newLedgerWorkspace(t)
+	path := ProgressLedgerPath(dir)
+	if err := AppendProgressLedgerEntry(path, map[string]any{"task_id": "task-1", "timestamp": "2026-01-01T11:00:00.000000000Z", "exit_code": 2}); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString(`{"task_id": "task-`)
+	file.Close()
+
+	var torn []map[string]any
+	if err := ForEachProgressLedgerEntry(path, func(_ int, entry map[string]any) error {
+		torn = append(torn, entry)
+		return nil
+	}); err != nil {
+		t.Fatalf("a torn final line should be skipped, got %v", err)
+	}
+	if len(torn) != 1 {
+		t.Fatalf("expected 1 complete entry, got %d", len(torn))
+	}
+
+	if err := AppendProgressLedgerEntry(path, map[string]any{"task_id": "task-2", "timestamp": "2026-01-01T12:00:00.000000000Z"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 || strings.Contains(string(data), `"task-"`) {
+		t.Fatalf("expected the torn line to be replaced, got %q", data)
+	}
+
+	artifact, err := LoadArtifact(dir, "progress.small.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := artifact.Data["entries"].([]any)
+	if len(entries) != 3 || artifact.LedgerStart != 1 {
+		t.Fatalf("expected the YAML entry followed by 2 ledger entries, got %d (ledger from %d)", len(entries), artifact.LedgerStart)
+	}
+	if code, ok := entries[1].(map[string]any)["exit_code"].(int); !ok || code != 2 {
+		t.Fatalf("expected JSON numbers to load as ints, got %#v", entries[1].(map[string]any)["exit_code"])
+	}
+}
+
+func TestLastProgressLedgerEntryReadsOnlyTheTail(t *testing.T) {
+	dir := newLedgerWorkspace(t)
+	path := ProgressLedgerPath(dir)
+	if entry, err := LastProgressLedgerEntry(path); err != nil || entry != nil {
+		t.Fatalf("expected no entry for a missing ledger, got %v, %v", entry, err)
+	}
+
+	// The corrupt first line is never read; the last entry spans several chunks and is
+	// followed by a blank line and a torn append.
+	long := strings.Repeat("x", 3*ledgerTailChunk)
+	ledger := "not json\n" +
+		`{"task_id":"task-1","timestamp":"2026-01-01T11:00:00.000000000Z"}` + "\n" +
+		`{"task_id":"task-2","timestamp":"2026-01-01T12:00:00.000000000Z","evidence":"` + long + `","exit_code":3}` + "\n" +
+		"\n" + `{"task_id": "task-`
+	if err := os.WriteFile(path, []byte(ledger), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := LastProgressLedgerEntry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry["task_id"] != "task-2" || entry["evidence"] != long || entry["exit_code"] != 3 {
+		t.Fatalf("expected the last complete entry, got task %v exit %#v", entry["task_id"], entry["exit_code"])
+	}
+
+	if err := os.WriteFile(path, []byte(`{"task_id":"task-1","timestamp":"2026-01-01T11:00:00.000000000Z"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if entry, err := LastProgressLedgerEntry(path); err != nil || entry["task_id"] != "task-1" {
+		t.Fatalf("expected the only entry, got %v, %v", entry, err)
+	}
+}
+
+func TestProgressLedgerSkipsEntriesAlreadyCompacted(t *testing.T) {
+	dir := newLedgerWorkspace(t)
+	// An interrupted compaction leaves entries in both files.
+	ledger := `{"task_id":"task-1","timestamp":"2026-01-01T10:00:00.000000000Z","evidence":"Started"}` + "\n" +
+		`{"task_id":"task-1","timestamp":"2026-01-01T10:30:00.000000000Z","evidence":"Done"}` + "\n"
+	if err := os.WriteFile(ProgressLedgerPath(dir), []byte(ledger), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	artifact, err := LoadArtifact(dir, "progress.small.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := artifact.Data["entries"].([]any); len(entries) != 2 {
+		t.Fatalf("expected the duplicated entry to be skipped, got %d entries", len(entries))
+	}
+}
+
+func TestValidateProgressReportsLedgerLine(t *testing.T) {
+	dir := newLedgerWorkspace(t)
+	ledger := `{"task_id":"task-1","timestamp":"2026-01-01T11:00:00.000000000Z","evidence":"ok"}` + "\n" +
+		`{"task_id":"task-1","timestamp":"2026-01-01T12:00:00.000000000Z","unknown_field":true}` + "\n"
+	if err := os.WriteFile(ProgressLedgerPath(dir), []byte(ledger), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	artifact, err := LoadArtifact(dir, "progress.small.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ValidateArtifactWithConfig(artifact, SchemaConfig{BaseDir: dir})
+	if err == nil || !strings.Contains(err.Error(), ProgressLedgerFileName+":2") {
+		t.Fatalf("expected a schema error pointing at ledger line 2, got %v", err)
+	}
+}
+
+func TestValidateProgressSkipsCompactedLedgerEntries(t *testing.T) {
+	dir := newLedgerWorkspace(t)
+	// The first line duplicates an entry already folded into progress.small.yml by an
+	// interrupted compaction, so neither the loader nor the validator may see it.
+	ledger := `{"task_id":"task-1","timestamp":"2026-01-01T10:00:00.000000000Z","unknown_field":true}` + "\n" +
+		`{"task_id":"task-1","timestamp":"2026-01-01T11:00:00.000000000Z","evidence":"ok"}` + "\n"
+	if err := os.WriteFile(ProgressLedgerPath(dir), []byte(ledger), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	artifact, err := LoadArtifact(dir, "progress.small.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(artifact.LedgerLines) != 1 || artifact.LedgerLines[0] != 2 {
+		t.Fatalf("expected only ledger line 2 to be loaded, got %v", artifact.LedgerLines)
+	}
+	if err := ValidateArtifactWithConfig(artifact, SchemaConfig{BaseDir: dir}); err != nil {
+		t.Fatalf("expected the compacted ledger entry to be skipped, got %v", err)
+	}
+}
@@ -36,6 +36,12 @@ func AppendRunIndexEntry(baseDir string, entry RunIndexEntry) error {
 		return fmt.Errorf("run index entry requires reason")
 	}
 
+	unlock, err := LockWorkspace(baseDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	runsDir := RunStoreDir(baseDir)
 	path := RunIndexPath(baseDir)
 
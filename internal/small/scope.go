@@ -0,0 +1,199 @@
+package small
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Scope violation reasons recorded in evidence.
+const (
+	ScopeReasonOutsideInclude = "outside_include"
+	ScopeReasonExcluded       = "excluded"
+)
+
+// IntentScope holds the include/exclude path patterns declared in intent.small.yml.
+//
+// Patterns are workspace-relative, slash-separated paths. A trailing slash marks a
+// directory prefix ("src/"), glob metacharacters enable path.Match semantics per
+// segment with "**" matching any number of segments, and anything else matches the
+// exact path or any path beneath it. An empty include list places every path in scope.
+type IntentScope struct {
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+}
+
+// ScopeViolation describes a touched path that falls outside the intent scope.
+type ScopeViolation struct {
+	Path    string `json:"path"`
+	Reason  string `json:"reason"`
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// LoadIntentScope reads scope.include and scope.exclude from intent.small.yml.
+// It returns ok=false when the intent artifact does not exist.
+func LoadIntentScope(baseDir string) (IntentScope, bool, error) {
+	if !ArtifactExists(baseDir, "intent.small.yml") {
+		return IntentScope{}, false, nil
+	}
+	artifact, err := LoadArtifact(baseDir, "intent.small.yml")
+	if err != nil {
+		return IntentScope{}, false, err
+	}
+	scope, err := IntentScopeFromArtifact(artifact)
+	if err != nil {
+		return IntentScope{}, false, err
+	}
+	return scope, true, nil
+}
+
+// IntentScopeFromArtifact extracts the scope patterns from a loaded intent artifact.
+func IntentScopeFromArtifact(artifact *Artifact) (IntentScope, error) {
+	if artifact == nil || artifact.Data == nil {
+		return IntentScope{}, fmt.Errorf("intent artifact is empty")
+	}
+	raw, ok := artifact.Data["scope"].(map[string]any)
+	if !ok {
+		return IntentScope{}, fmt.Errorf("intent.scope must be an object with include/exclude arrays")
+	}
+	include, err := scopePatternList(raw["include"], "include")
+	if err != nil {
+		return IntentScope{}, err
+	}
+	exclude, err := scopePatternList(raw["exclude"], "exclude")
+	if err != nil {
+		return IntentScope{}, err
+	}
+	return IntentScope{Include: include, Exclude: exclude}, nil
+}
+
+func scopePatternList(value any, field string) ([]string, error) {
+	if value == nil {
+		return []string{}, nil
+	}
+	items, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("intent.scope.%s must be an array", field)
+	}
+	patterns := make([]string, 0, len(items))
+	for i, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("intent.scope.%s[%d] must be a string", field, i)
+		}
+		if normalized := normalizeScopePattern(s); normalized != "" {
+			patterns = append(patterns, normalized)
+		}
+	}
+	return patterns, nil
+}
+
+// Check returns the scope decision for a single workspace-relative path.
+// A path is in scope when it matches an include pattern (or include is empty)
+// and matches no exclude pattern.
+func (s IntentScope) Check(relPath string) (ScopeViolation, bool) {
+	p := normalizeScopePath(relPath)
+	for _, pattern := range s.Exclude {
+		if MatchScopePattern(pattern, p) {
+			return ScopeViolation{Path: p, Reason: ScopeReasonExcluded, Pattern: pattern}, false
+		}
+	}
+	if len(s.Include) == 0 {
+		return ScopeViolation{}, true
+	}
+	for _, pattern := range s.Include {
+		if MatchScopePattern(pattern, p) {
+			return ScopeViolation{}, true
+		}
+	}
+	return ScopeViolation{Path: p, Reason: ScopeReasonOutsideInclude}, false
+}
+
+// Violations checks every path and returns the violations sorted by path.
+func (s IntentScope) Violations(paths []string) []ScopeViolation {
+	var violations []ScopeViolation
+	for _, p := range paths {
+		if violation, ok := s.Check(p); !ok {
+			violations = append(violations, violation)
+		}
+	}
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].Path < violations[j].Path
+	})
+	return violations
+}
+
+// MatchScopePattern reports whether a workspace-relative path matches a scope pattern.
+func MatchScopePattern(pattern, relPath string) bool {
+	pattern = normalizeScopePattern(pattern)
+	p := normalizeScopePath(relPath)
+	if pattern == "" || p == "" {
+		return false
+	}
+
+	if strings.ContainsAny(pattern, "*?[") {
+		return matchScopeGlob(strings.Split(strings.TrimSuffix(pattern, "/"), "/"), strings.Split(p, "/"))
+	}
+
+	if strings.HasSuffix(pattern, "/") {
+		return p == strings.TrimSuffix(pattern, "/") || strings.HasPrefix(p, pattern)
+	}
+	return p == pattern || strings.HasPrefix(p, pattern+"/")
+}
+
+// ScopeLiteralPrefix returns the leading path segments of a scope pattern that contain
+// no glob metacharacters, without a trailing slash. "src/**/*.go" yields "src" and
+// "**/*.md" yields "", meaning the pattern can match anywhere in the workspace.
+func ScopeLiteralPrefix(pattern string) string {
+	pattern = strings.TrimSuffix(normalizeScopePattern(pattern), "/")
+	var literal []string
+	for _, segment := range strings.Split(pattern, "/") {
+		if segment == "" || strings.ContainsAny(segment, "*?[") {
+			break
+		}
+		literal = append(literal, segment)
+	}
+	return strings.Join(literal, "/")
+}
+
+// matchScopeGlob matches path segments against pattern segments. A glob pattern
+// also matches everything beneath a matching directory, so "src/*" covers "src/a/b.go".
+func matchScopeGlob(patternParts, pathParts []string) bool {
+	if len(patternParts) == 0 {
+		return true
+	}
+	if patternParts[0] == "**" {
+		for i := 0; i <= len(pathParts); i++ {
+			if matchScopeGlob(patternParts[1:], pathParts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(pathParts) == 0 {
+		return false
+	}
+	matched, err := path.Match(patternParts[0], pathParts[0])
+	if err != nil || !matched {
+		return false
+	}
+	return matchScopeGlob(patternParts[1:], pathParts[1:])
+}
+
+func normalizeScopePattern(pattern string) string {
+	trimmed := strings.TrimSpace(strings.ReplaceAll(pattern, "\\", "/"))
+	for strings.HasPrefix(trimmed, "./") {
+		trimmed = strings.TrimPrefix(trimmed, "./")
+	}
+	trimmed = strings.TrimPrefix(trimmed, "/")
+	if trimmed == "." {
+		return ""
+	}
+	return trimmed
+}
+
+func normalizeScopePath(relPath string) string {
+	trimmed := normalizeScopePattern(relPath)
+	return strings.TrimSuffix(trimmed, "/")
+}
@@ -0,0 +1,120 @@
+package small
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchScopePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"src/", "src/main.go", true},
+		{"src/", "src", true},
+		{"src/", "srcfoo/main.go", false},
+		{"./src/", "src/a/b.go", true},
+		{"internal", "internal/small/scope.go", true},
+		{"internal", "internal2/x.go", false},
+		{"README.md", "README.md", true},
+		{"README.md", "docs/README.md", false},
+		{"*.md", "README.md", true},
+		{"*.md", "docs/README.md", false},
+		{"docs/*", "docs/guide/intro.md", true},
+		{"**/*.md", "docs/guide/intro.md", true},
+		{"**/*.md", "README.md", true},
+		{"src/**/testdata", "src/a/b/testdata/file.txt", true},
+		{"src/**/testdata", "src/testdata", true},
+		{"src/**/testdata", "lib/testdata", false},
+	}
+	for _, tt := range tests {
+		if got := MatchScopePattern(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchScopePattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestScopeLiteralPrefix(t *testing.T) {
+	tests := map[string]string{
+		"src/":             "src",
+		"./docs/guide":     "docs/guide",
+		"src/**/*.go":      "src",
+		"**/*.md":          "",
+		"internal/*/x.go":  "internal",
+		"README.md":        "README.md",
+		"pkg/[ab]/file.go": "pkg",
+	}
+	for pattern, want := range tests {
+		if got := ScopeLiteralPrefix(pattern); got != want {
+			t.Errorf("ScopeLiteralPrefix(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestIntentScopeViolations(t *testing.T) {
+	scope := IntentScope{
+		Include: []string{"src/", "docs/"},
+		Exclude: []string{"src/generated/"},
+	}
+
+	violations := scope.Violations([]string{
+		"src/main.go",
+		"src/generated/api.go",
+		"docs/index.md",
+		"Makefile",
+	})
+
+	want := []ScopeViolation{
+		{Path: "Makefile", Reason: ScopeReasonOutsideInclude},
+		{Path: "src/generated/api.go", Reason: ScopeReasonExcluded, Pattern: "src/generated/"},
+	}
+	if !reflect.DeepEqual(violations, want) {
+		t.Fatalf("violations = %#v, want %#v", violations, want)
+	}
+}
+
+func TestIntentScopeEmptyIncludeAllowsEverything(t *testing.T) {
+	scope := IntentScope{Exclude: []string{"vendor/"}}
+	if _, ok := scope.Check("anything/at/all.go"); !ok {
+		t.Fatal("expected path to be in scope when include is empty")
+	}
+	if _, ok := scope.Check("vendor/lib.go"); ok {
+		t.Fatal("expected excluded path to be out of scope")
+	}
+}
+
+func TestLoadIntentScope(t *testing.T) {
+	baseDir := t.TempDir()
+	if _, ok, err := LoadIntentScope(baseDir); err != nil || ok {
+		t.Fatalf("expected missing intent to return ok=false, got ok=%v err=%v", ok, err)
+	}
+
+	smallDir := filepath.Join(baseDir, SmallDir)
+	if err := os.MkdirAll(smallDir, 0o755); err != nil {
+		t.Fatalf("failed to create .small dir: %v", err)
+	}
+	intent := `small_version: "1.0.0"
+owner: "human"
+intent: "Test"
+scope:
+  include:
+    - "src/"
+  exclude:
+    - "src/vendor/"
+success_criteria: []
+`
+	if err := os.WriteFile(filepath.Join(smallDir, "intent.small.yml"), []byte(intent), 0o644); err != nil {
+		t.Fatalf("failed to write intent: %v", err)
+	}
+
+	scope, ok, err := LoadIntentScope(baseDir)
+	if err != nil || !ok {
+		t.Fatalf("LoadIntentScope error: ok=%v err=%v", ok, err)
+	}
+	if !reflect.DeepEqual(scope.Include, []string{"src/"}) || !reflect.DeepEqual(scope.Exclude, []string{"src/vendor/"}) {
+		t.Fatalf("unexpected scope: %#v", scope)
+	}
+}
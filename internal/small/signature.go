@@ -0,0 +1,401 @@
+package small
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Detached signatures use the OpenSSH SSHSIG format, so a signature made by SMALL can be
+// checked with `ssh-keygen -Y verify -n small` and one made by `ssh-keygen -Y sign -n small`
+// with an ed25519 key verifies here.
+const (
+	// SignatureNamespace is the SSHSIG namespace SMALL signs and verifies under.
+	SignatureNamespace = "small"
+	// SignatureSuffix is appended to a file name to name its detached signature.
+	SignatureSuffix = ".sig"
+	// HandoffSignatureFileName is the detached signature of handoff.small.yml.
+	HandoffSignatureFileName = "handoff.small.yml" + SignatureSuffix
+
+	sshSigMagic     = "SSHSIG"
+	sshSigVersion   = 1
+	sshEd25519      = "ssh-ed25519"
+	sshSigArmorHead = "-----BEGIN SSH SIGNATURE-----"
+	sshSigArmorTail = "-----END SSH SIGNATURE-----"
+	sshKeyMagic     = "openssh-key-v1\x00"
+)
+
+// LoadSigningKey reads an ed25519 private key from an unencrypted OpenSSH private key
+// file (as written by `ssh-keygen -t ed25519`) or a PKCS#8 PEM file.
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not a PEM file", path)
+	}
+	switch block.Type {
+	case "OPENSSH PRIVATE KEY":
+		key, err := parseOpenSSHEd25519PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %s: %w", path, err)
+		}
+		return key, nil
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("signing key %s: %w", path, err)
+		}
+		key, ok := parsed.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("signing key %s is not an ed25519 key", path)
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("signing key %s has unsupported PEM type %q", path, block.Type)
+	}
+}
+
+func parseOpenSSHEd25519PrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	if !bytes.HasPrefix(data, []byte(sshKeyMagic)) {
+		return nil, errors.New("not an openssh-key-v1 key")
+	}
+	r := sshReader{data: data[len(sshKeyMagic):]}
+	cipher, kdf := r.str(), r.str()
+	r.str() // kdf options
+	count := r.uint32()
+	if r.err == nil && (string(cipher) != "none" || string(kdf) != "none") {
+		return nil, errors.New("encrypted keys are not supported; sign with `ssh-keygen -Y sign -n small` instead")
+	}
+	if r.err == nil && count != 1 {
+		return nil, fmt.Errorf("expected one key, found %d", count)
+	}
+	r.str() // public key
+	priv := sshReader{data: r.str()}
+	if r.err != nil {
+		return nil, r.err
+	}
+	check1, check2 := priv.uint32(), priv.uint32()
+	keyType := priv.str()
+	pub := priv.str()
+	secret := priv.str()
+	if priv.err != nil {
+		return nil, priv.err
+	}
+	if check1 != check2 {
+		return nil, errors.New("corrupt private key (check bytes differ)")
+	}
+	if string(keyType) != sshEd25519 {
+		return nil, fmt.Errorf("unsupported key type %s (only ed25519 keys are supported)", keyType)
+	}
+	if len(pub) != ed25519.PublicKeySize || len(secret) != ed25519.PrivateKeySize {
+		return nil, errors.New("malformed ed25519 key")
+	}
+	return ed25519.PrivateKey(secret), nil
+}
+
+// SignData returns an armored SSHSIG signature of data in the SMALL namespace.
+func SignData(key ed25519.PrivateKey, data []byte) []byte {
+	pub := key.Public().(ed25519.PublicKey)
+	signature := ed25519.Sign(key, sshSignedData("sha512", data))
+
+	var sig bytes.Buffer
+	writeSSHString(&sig, []byte(sshEd25519))
+	writeSSHString(&sig, signature)
+
+	var blob bytes.Buffer
+	blob.WriteString(sshSigMagic)
+	_ = binary.Write(&blob, binary.BigEndian, uint32(sshSigVersion))
+	writeSSHString(&blob, sshPublicKeyBlob(pub))
+	writeSSHString(&blob, []byte(SignatureNamespace))
+	writeSSHString(&blob, nil)
+	writeSSHString(&blob, []byte("sha512"))
+	writeSSHString(&blob, sig.Bytes())
+
+	encoded := base64.StdEncoding.EncodeToString(blob.Bytes())
+	var out strings.Builder
+	out.WriteString(sshSigArmorHead + "\n")
+	for len(encoded) > 70 {
+		out.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	out.WriteString(encoded + "\n")
+	out.WriteString(sshSigArmorTail + "\n")
+	return []byte(out.String())
+}
+
+// VerifyDataSignature checks an armored SSHSIG signature of data against the trusted keys
+// and returns the key that made it.
+func VerifyDataSignature(data, armored []byte, trusted []ed25519.PublicKey) (ed25519.PublicKey, error) {
+	text := strings.TrimSpace(string(armored))
+	if !strings.HasPrefix(text, sshSigArmorHead) || !strings.HasSuffix(text, sshSigArmorTail) {
+		return nil, errors.New("not an SSH signature")
+	}
+	body := strings.Join(strings.Fields(strings.TrimSuffix(strings.TrimPrefix(text, sshSigArmorHead), sshSigArmorTail)), "")
+	blob, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, fmt.Errorf("malformed SSH signature: %w", err)
+	}
+	if !bytes.HasPrefix(blob, []byte(sshSigMagic)) {
+		return nil, errors.New("malformed SSH signature: missing SSHSIG preamble")
+	}
+	r := sshReader{data: blob[len(sshSigMagic):]}
+	version := r.uint32()
+	pubBlob := r.str()
+	namespace := r.str()
+	r.str() // reserved
+	hashAlg := r.str()
+	sigBlob := r.str()
+	if r.err != nil {
+		return nil, fmt.Errorf("malformed SSH signature: %w", r.err)
+	}
+	if version != sshSigVersion {
+		return nil, fmt.Errorf("unsupported SSH signature version %d", version)
+	}
+	if string(namespace) != SignatureNamespace {
+		return nil, fmt.Errorf("signature namespace is %q, expected %q", namespace, SignatureNamespace)
+	}
+	if string(hashAlg) != "sha512" && string(hashAlg) != "sha256" {
+		return nil, fmt.Errorf("unsupported signature hash %q", hashAlg)
+	}
+	pub, err := parseSSHPublicKeyBlob(pubBlob)
+	if err != nil {
+		return nil, err
+	}
+	sr := sshReader{data: sigBlob}
+	sigType, signature := sr.str(), sr.str()
+	if sr.err != nil || string(sigType) != sshEd25519 {
+		return nil, errors.New("malformed SSH signature: expected an ssh-ed25519 signature")
+	}
+
+	known := false
+	for _, key := range trusted {
+		if key.Equal(pub) {
+			known = true
+			break
+		}
+	}
+	if !known {
+		return nil, fmt.Errorf("signed by untrusted key %s", PublicKeyFingerprint(pub))
+	}
+	if !ed25519.Verify(pub, sshSignedData(string(hashAlg), data), signature) {
+		return nil, fmt.Errorf("signature by %s does not match the content (modified after signing)", PublicKeyFingerprint(pub))
+	}
+	return pub, nil
+}
+
+// SignFile writes a detached signature of the file at path to path + SignatureSuffix.
+func SignFile(path string, key ed25519.PrivateKey) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := WriteFileAtomic(path+SignatureSuffix, SignData(key, data), 0o644); err != nil {
+		return fmt.Errorf("failed to write signature: %w", err)
+	}
+	return nil
+}
+
+// VerifyFileSignature checks the detached signature beside the file at path.
+func VerifyFileSignature(path string, trusted []ed25519.PublicKey) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := os.ReadFile(path + SignatureSuffix)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New("not signed")
+		}
+		return nil, err
+	}
+	return VerifyDataSignature(data, signature, trusted)
+}
+
+// LoadTrustedKeys reads ed25519 public keys from path. Each non-comment line holds an
+// OpenSSH public key, optionally preceded by principals as in an allowed_signers file;
+// PEM "PUBLIC KEY" blocks are accepted as well.
+func LoadTrustedKeys(path string) ([]ed25519.PublicKey, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted keys: %w", err)
+	}
+	defer file.Close()
+
+	var keys []ed25519.PublicKey
+	var pemLines []string
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if pemLines != nil || strings.HasPrefix(text, "-----BEGIN ") {
+			pemLines = append(pemLines, text)
+			if !strings.HasPrefix(text, "-----END ") {
+				continue
+			}
+			key, err := parsePEMPublicKey(strings.Join(pemLines, "\n"))
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+			}
+			keys = append(keys, key)
+			pemLines = nil
+			continue
+		}
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, err := parseAuthorizedKeyLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trusted keys: %w", err)
+	}
+	if pemLines != nil {
+		return nil, fmt.Errorf("%s: unterminated PEM block", path)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s contains no trusted keys", path)
+	}
+	return keys, nil
+}
+
+func parseAuthorizedKeyLine(line string) (ed25519.PublicKey, error) {
+	fields := strings.Fields(line)
+	for i, field := range fields {
+		if !strings.HasPrefix(field, "ssh-") && !strings.HasPrefix(field, "ecdsa-") && !strings.HasPrefix(field, "sk-") {
+			continue
+		}
+		if field != sshEd25519 {
+			return nil, fmt.Errorf("unsupported key type %s (only ssh-ed25519 keys are supported)", field)
+		}
+		if i+1 >= len(fields) {
+			return nil, errors.New("missing key data after ssh-ed25519")
+		}
+		blob, err := base64.StdEncoding.DecodeString(fields[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("malformed key data: %w", err)
+		}
+		return parseSSHPublicKeyBlob(blob)
+	}
+	return nil, errors.New("no ssh-ed25519 public key found")
+}
+
+func parsePEMPublicKey(text string) (ed25519.PublicKey, error) {
+	block, _ := pem.Decode([]byte(text))
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.New(`expected a PEM "PUBLIC KEY" block`)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("only ed25519 public keys are supported")
+	}
+	return key, nil
+}
+
+// PublicKeyFingerprint formats key the way ssh-keygen -l does: SHA256:<base64>.
+func PublicKeyFingerprint(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(sshPublicKeyBlob(key))
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// AuthorizedKey formats key as an OpenSSH public key line for a trusted keys file.
+func AuthorizedKey(key ed25519.PublicKey) string {
+	return sshEd25519 + " " + base64.StdEncoding.EncodeToString(sshPublicKeyBlob(key))
+}
+
+func sshSignedData(hashAlg string, data []byte) []byte {
+	var digest []byte
+	if hashAlg == "sha256" {
+		sum := sha256.Sum256(data)
+		digest = sum[:]
+	} else {
+		sum := sha512.Sum512(data)
+		digest = sum[:]
+	}
+	var buf bytes.Buffer
+	buf.WriteString(sshSigMagic)
+	writeSSHString(&buf, []byte(SignatureNamespace))
+	writeSSHString(&buf, nil)
+	writeSSHString(&buf, []byte(hashAlg))
+	writeSSHString(&buf, digest)
+	return buf.Bytes()
+}
+
+func sshPublicKeyBlob(key ed25519.PublicKey) []byte {
+	var buf bytes.Buffer
+	writeSSHString(&buf, []byte(sshEd25519))
+	writeSSHString(&buf, key)
+	return buf.Bytes()
+}
+
+func parseSSHPublicKeyBlob(blob []byte) (ed25519.PublicKey, error) {
+	r := sshReader{data: blob}
+	keyType, key := r.str(), r.str()
+	if r.err != nil {
+		return nil, fmt.Errorf("malformed public key: %w", r.err)
+	}
+	if string(keyType) != sshEd25519 {
+		return nil, fmt.Errorf("unsupported key type %s (only ssh-ed25519 keys are supported)", keyType)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("malformed ssh-ed25519 public key")
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+func writeSSHString(buf *bytes.Buffer, value []byte) {
+	_ = binary.Write(buf, binary.BigEndian, uint32(len(value)))
+	buf.Write(value)
+}
+
+// sshReader decodes the SSH wire encoding; the first error sticks and later reads return
+// zero values.
+type sshReader struct {
+	data []byte
+	err  error
+}
+
+func (r *sshReader) uint32() uint32 {
+	if r.err != nil {
+		return 0
+	}
+	if len(r.data) < 4 {
+		r.err = errors.New("unexpected end of data")
+		return 0
+	}
+	value := binary.BigEndian.Uint32(r.data)
+	r.data = r.data[4:]
+	return value
+}
+
+func (r *sshReader) str() []byte {
+	n := r.uint32()
+	if r.err != nil {
+		return nil
+	}
+	if uint64(len(r.data)) < uint64(n) {
+		r.err = errors.New("unexpected end of data")
+		return nil
+	}
+	value := r.data[:n]
+	r.data = r.data[n:]
+	return value
+}
@@ -0,0 +1,121 @@
+package small
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePKCS8SigningKey(t *testing.T, dir string) (string, ed25519.PublicKey) {
+	t.Helper()
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "signing.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path, pub
+}
+
+func TestSignAndVerifyFile(t *testing.T) {
+	dir := t.TempDir()
+	keyPath, pub := writePKCS8SigningKey(t, dir)
+	key, err := LoadSigningKey(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trustedPath := filepath.Join(dir, "trusted_keys")
+	trustedFile := "# orchestrator keys\norchestrator@example.com " + AuthorizedKey(pub) + " ci\n"
+	if err := os.WriteFile(trustedPath, []byte(trustedFile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	trusted, err := LoadTrustedKeys(trustedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	artifact := filepath.Join(dir, "handoff.small.yml")
+	if err := os.WriteFile(artifact, []byte("summary: \"done\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyFileSignature(artifact, trusted); err == nil || !strings.Contains(err.Error(), "not signed") {
+		t.Fatalf("expected an unsigned file to fail, got %v", err)
+	}
+	if err := SignFile(artifact, key); err != nil {
+		t.Fatal(err)
+	}
+	signer, err := VerifyFileSignature(artifact, trusted)
+	if err != nil || !signer.Equal(pub) {
+		t.Fatalf("expected a valid signature by the trusted key, got %v", err)
+	}
+	if _, err := VerifyFileSignature(artifact, []ed25519.PublicKey{other}); err == nil || !strings.Contains(err.Error(), "untrusted key") {
+		t.Fatalf("expected an untrusted signer to fail, got %v", err)
+	}
+
+	if err := os.WriteFile(artifact, []byte("summary: \"edited\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyFileSignature(artifact, trusted); err == nil || !strings.Contains(err.Error(), "modified after signing") {
+		t.Fatalf("expected an edited file to fail, got %v", err)
+	}
+}
+
+func TestSignaturesInteroperateWithSSHKeygen(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "id_ed25519")
+	if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", keyPath).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen failed: %v: %s", err, out)
+	}
+	key, err := LoadSigningKey(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trusted, err := LoadTrustedKeys(keyPath + ".pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("small_version: \"1.0.0\"\n")
+	artifact := filepath.Join(dir, "handoff.small.yml")
+	if err := os.WriteFile(artifact, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SignFile(artifact, key); err != nil {
+		t.Fatal(err)
+	}
+	check := exec.Command("ssh-keygen", "-Y", "check-novalidate", "-n", SignatureNamespace, "-s", artifact+SignatureSuffix)
+	check.Stdin = bytes.NewReader(data)
+	if out, err := check.CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen rejected the signature: %v: %s", err, out)
+	}
+
+	if err := os.Remove(artifact + SignatureSuffix); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command("ssh-keygen", "-Y", "sign", "-n", SignatureNamespace, "-f", keyPath, artifact).CombinedOutput(); err != nil {
+		t.Fatalf("ssh-keygen -Y sign failed: %v: %s", err, out)
+	}
+	if _, err := VerifyFileSignature(artifact, trusted); err != nil {
+		t.Fatalf("expected the ssh-keygen signature to verify: %v", err)
+	}
+}
@@ -9,12 +9,14 @@ import (
 )
 
 var canonicalSmallRootFiles = map[string]struct{}{
-	"intent.small.yml":      {},
-	"constraints.small.yml": {},
-	"plan.small.yml":        {},
-	"progress.small.yml":    {},
-	"handoff.small.yml":     {},
-	"workspace.small.yml":   {},
+	"intent.small.yml":       {},
+	"constraints.small.yml":  {},
+	"plan.small.yml":         {},
+	"progress.small.yml":     {},
+	ProgressLedgerFileName:   {},
+	"handoff.small.yml":      {},
+	HandoffSignatureFileName: {},
+	"workspace.small.yml":    {},
 }
 
 func StrictSmallLayoutViolations(baseDir, commandHint string) ([]InvariantViolation, error) {
@@ -37,6 +39,9 @@ func StrictSmallLayoutViolations(baseDir, commandHint string) ([]InvariantViolat
 			}
 			continue
 		}
+		if name == LockFileName {
+			continue
+		}
 		if _, ok := canonicalSmallRootFiles[name]; !ok {
 			unexpected = append(unexpected, relPath)
 		}
@@ -21,6 +21,7 @@ func TestStrictSmallLayoutViolations(t *testing.T) {
 		"progress.small.yml",
 		"handoff.small.yml",
 		"workspace.small.yml",
+		LockFileName,
 	}
 	for _, filename := range allowed {
 		if err := os.WriteFile(filepath.Join(smallDir, filename), []byte("small_version: \"1.0.0\"\nowner: \"agent\"\n"), 0o644); err != nil {
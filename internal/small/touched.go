@@ -0,0 +1,251 @@
+package small
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// WorkspaceSnapshot maps workspace-relative file paths to content hashes.
+type WorkspaceSnapshot struct {
+	Files map[string]string
+	// Git reports whether the file list came from git (tracked plus untracked, honoring .gitignore).
+	Git bool
+}
+
+// TouchedPaths lists files created, modified, or deleted between two snapshots.
+type TouchedPaths struct {
+	Created  []string `yaml:"created,omitempty" json:"created,omitempty"`
+	Modified []string `yaml:"modified,omitempty" json:"modified,omitempty"`
+	Deleted  []string `yaml:"deleted,omitempty" json:"deleted,omitempty"`
+}
+
+// IsEmpty reports whether no paths were touched.
+func (t TouchedPaths) IsEmpty() bool {
+	return len(t.Created) == 0 && len(t.Modified) == 0 && len(t.Deleted) == 0
+}
+
+// All returns every touched path in sorted order.
+func (t TouchedPaths) All() []string {
+	all := make([]string, 0, len(t.Created)+len(t.Modified)+len(t.Deleted))
+	all = append(all, t.Created...)
+	all = append(all, t.Modified...)
+	all = append(all, t.Deleted...)
+	sort.Strings(all)
+	return all
+}
+
+// Map returns the touched paths in the shape recorded on progress entries.
+func (t TouchedPaths) Map() map[string]any {
+	out := map[string]any{}
+	if len(t.Created) > 0 {
+		out["created"] = StringsToAny(t.Created)
+	}
+	if len(t.Modified) > 0 {
+		out["modified"] = StringsToAny(t.Modified)
+	}
+	if len(t.Deleted) > 0 {
+		out["deleted"] = StringsToAny(t.Deleted)
+	}
+	return out
+}
+
+// SnapshotWorkspace hashes every file under baseDir that is not SMALL-managed state.
+// Inside a git work tree the file list comes from git (tracked and untracked files,
+// excluding ignored ones); otherwise the directory tree is walked.
+func SnapshotWorkspace(baseDir string) (WorkspaceSnapshot, error) {
+	snapshot := WorkspaceSnapshot{Files: map[string]string{}}
+
+	paths, ok := gitWorkspaceFiles(baseDir)
+	if ok {
+		snapshot.Git = true
+		for _, rel := range paths {
+			if isSmallManagedPath(rel) {
+				continue
+			}
+			full := filepath.Join(baseDir, filepath.FromSlash(rel))
+			info, err := os.Lstat(full)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			hash, err := hashWorkspaceFile(full, info)
+			if err != nil {
+				return WorkspaceSnapshot{}, err
+			}
+			snapshot.Files[rel] = hash
+		}
+		return snapshot, nil
+	}
+
+	err := filepath.WalkDir(baseDir, func(full string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(baseDir, full)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if rel != "." && (d.Name() == ".git" || isSmallManagedPath(rel)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		hash, err := hashWorkspaceFile(full, info)
+		if err != nil {
+			return err
+		}
+		snapshot.Files[rel] = hash
+		return nil
+	})
+	if err != nil {
+		return WorkspaceSnapshot{}, err
+	}
+	return snapshot, nil
+}
+
+// DiffWorkspaceSnapshots compares two snapshots and returns the touched paths.
+func DiffWorkspaceSnapshots(before, after WorkspaceSnapshot) TouchedPaths {
+	var touched TouchedPaths
+	for rel, hash := range after.Files {
+		prev, ok := before.Files[rel]
+		switch {
+		case !ok:
+			touched.Created = append(touched.Created, rel)
+		case prev != hash:
+			touched.Modified = append(touched.Modified, rel)
+		}
+	}
+	for rel := range before.Files {
+		if _, ok := after.Files[rel]; !ok {
+			touched.Deleted = append(touched.Deleted, rel)
+		}
+	}
+	sort.Strings(touched.Created)
+	sort.Strings(touched.Modified)
+	sort.Strings(touched.Deleted)
+	return touched
+}
+
+// ChangedPathsSince lists workspace-relative paths that differ between the git ref and
+// the working tree, including staged, unstaged, and untracked (non-ignored) files.
+// SMALL-managed paths are omitted. Renames are reported as a deletion plus a creation.
+func ChangedPathsSince(baseDir, ref string) ([]string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return nil, fmt.Errorf("git ref is required")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git not found in PATH")
+	}
+	if _, err := runGitOutput(baseDir, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, fmt.Errorf("%s is not inside a git work tree", baseDir)
+	}
+	if _, err := runGitOutput(baseDir, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err != nil {
+		return nil, fmt.Errorf("unknown git ref %q", ref)
+	}
+
+	diff, err := runGitOutput(baseDir, "diff", "--name-only", "-z", "--no-renames", "--relative", ref, "--")
+	if err != nil {
+		return nil, fmt.Errorf("git diff against %s failed: %w", ref, err)
+	}
+	untracked, err := runGitOutput(baseDir, "ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, fmt.Errorf("git ls-files failed: %w", err)
+	}
+
+	seen := map[string]bool{}
+	var paths []string
+	for _, rel := range strings.Split(diff+"\x00"+untracked, "\x00") {
+		if rel == "" || seen[rel] || isSmallManagedPath(rel) {
+			continue
+		}
+		seen[rel] = true
+		paths = append(paths, rel)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+func runGitOutput(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return string(output), nil
+}
+
+func gitWorkspaceFiles(baseDir string) ([]string, bool) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, false
+	}
+	output, err := runGitOutput(baseDir, "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, false
+	}
+	seen := map[string]bool{}
+	var paths []string
+	for _, rel := range strings.Split(output, "\x00") {
+		if rel == "" || seen[rel] {
+			continue
+		}
+		seen[rel] = true
+		paths = append(paths, rel)
+	}
+	return paths, true
+}
+
+func hashWorkspaceFile(full string, info fs.FileInfo) (string, error) {
+	h := sha256.New()
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Readlink(full)
+		if err != nil {
+			return "", err
+		}
+		_, _ = io.WriteString(h, "symlink:"+target)
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+	if !info.Mode().IsRegular() {
+		return "", nil
+	}
+	f, err := os.Open(full)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// isSmallManagedPath reports whether a workspace-relative path belongs to SMALL's own
+// state directories, which are tracked by the protocol itself rather than by scope.
+func isSmallManagedPath(rel string) bool {
+	for _, dir := range []string{SmallDir, CacheDirName, RunStoreDirName, ArchiveStoreDirName} {
+		if rel == dir || strings.HasPrefix(rel, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
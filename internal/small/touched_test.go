@@ -0,0 +1,58 @@
+package small
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiffWorkspaceSnapshots(t *testing.T) {
+	baseDir := t.TempDir()
+	writeFile := func(rel, content string) {
+		t.Helper()
+		full := filepath.Join(baseDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to write %s: %v", rel, err)
+		}
+	}
+
+	writeFile("keep.txt", "same")
+	writeFile("src/change.go", "before")
+	writeFile("old.txt", "gone soon")
+	writeFile(".small/progress.small.yml", "entries: []")
+
+	before, err := SnapshotWorkspace(baseDir)
+	if err != nil {
+		t.Fatalf("SnapshotWorkspace before: %v", err)
+	}
+
+	writeFile("src/change.go", "after")
+	writeFile("src/new.go", "new")
+	writeFile(".small/progress.small.yml", "entries: [changed]")
+	writeFile(".small-cache/logs/x.txt", "ignored")
+	if err := os.Remove(filepath.Join(baseDir, "old.txt")); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+
+	after, err := SnapshotWorkspace(baseDir)
+	if err != nil {
+		t.Fatalf("SnapshotWorkspace after: %v", err)
+	}
+
+	touched := DiffWorkspaceSnapshots(before, after)
+	want := TouchedPaths{
+		Created:  []string{"src/new.go"},
+		Modified: []string{"src/change.go"},
+		Deleted:  []string{"old.txt"},
+	}
+	if !reflect.DeepEqual(touched, want) {
+		t.Fatalf("touched = %#v, want %#v", touched, want)
+	}
+	if got := touched.All(); !reflect.DeepEqual(got, []string{"old.txt", "src/change.go", "src/new.go"}) {
+		t.Fatalf("All() = %v", got)
+	}
+}
@@ -45,8 +45,8 @@ var (
 )
 
 // getSchemaPath returns the filesystem path for an artifact schema.
-func getSchemaPath(baseDir, artifactType string) string {
-	return filepath.Join(baseDir, "spec", "small", "v1.0.0", "schemas", artifactType+".schema.json")
+func getSchemaPath(baseDir, version, artifactType string) string {
+	return filepath.Join(baseDir, "spec", "small", "v"+version, "schemas", artifactType+".schema.json")
 }
 
 // findSpecDir looks for on-disk schemas starting from baseDir and walking up.
@@ -75,14 +75,14 @@ func compileSchemaFromDisk(schemaPath string) (*jsonschema.Schema, error) {
 }
 
 // compileSchemaFromEmbedded compiles a schema from the embedded filesystem.
-func compileSchemaFromEmbedded(artifactType string) (*jsonschema.Schema, error) {
-	schemaBytes, err := specembed.ReadSchema(artifactType)
+func compileSchemaFromEmbedded(version, artifactType string) (*jsonschema.Schema, error) {
+	schemaBytes, err := specembed.ReadVersionedSchema(version, artifactType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read embedded schema for %s: %w", artifactType, err)
+		return nil, fmt.Errorf("failed to read embedded v%s schema for %s: %w", version, artifactType, err)
 	}
 
 	// Use a stable URL for the embedded schema
-	schemaURL := fmt.Sprintf("small://v1.0.0/schemas/%s.schema.json", artifactType)
+	schemaURL := fmt.Sprintf("small://v%s/schemas/%s.schema.json", version, artifactType)
 
 	compiler := jsonschema.NewCompiler()
 	compiler.Draft = jsonschema.Draft2020
@@ -94,14 +94,20 @@ func compileSchemaFromEmbedded(artifactType string) (*jsonschema.Schema, error)
 	return compiler.Compile(schemaURL)
 }
 
-// LoadSchema resolves and compiles a schema for the given artifact type.
+// LoadSchema resolves and compiles the v1.0.0 schema for the given artifact type.
+func LoadSchema(artifactType string, config SchemaConfig) (*ResolvedSchema, error) {
+	return LoadVersionedSchema(ProtocolVersion, artifactType, config)
+}
+
+// LoadVersionedSchema resolves and compiles the schema for the given protocol
+// version and artifact type.
 // Resolution order:
 //  1. If config.SpecDir is set, load from that directory
 //  2. Else if on-disk schemas found (dev mode), load from disk
 //  3. Else load from embedded schemas
-func LoadSchema(artifactType string, config SchemaConfig) (*ResolvedSchema, error) {
+func LoadVersionedSchema(version, artifactType string, config SchemaConfig) (*ResolvedSchema, error) {
 	// Build cache key based on config
-	cacheKey := fmt.Sprintf("%s:%s:%s", artifactType, config.SpecDir, config.BaseDir)
+	cacheKey := fmt.Sprintf("%s:%s:%s:%s", version, artifactType, config.SpecDir, config.BaseDir)
 
 	if cached, ok := schemaCache[cacheKey]; ok {
 		return cached, nil
@@ -111,7 +117,7 @@ func LoadSchema(artifactType string, config SchemaConfig) (*ResolvedSchema, erro
 
 	// 1. Check explicit --spec-dir
 	if config.SpecDir != "" {
-		schemaPath := filepath.Join(config.SpecDir, "small", "v1.0.0", "schemas", artifactType+".schema.json")
+		schemaPath := filepath.Join(config.SpecDir, "small", "v"+version, "schemas", artifactType+".schema.json")
 		if _, err := os.Stat(schemaPath); err == nil {
 			schema, err := compileSchemaFromDisk(schemaPath)
 			if err != nil {
@@ -130,7 +136,7 @@ func LoadSchema(artifactType string, config SchemaConfig) (*ResolvedSchema, erro
 	// 2. Check for on-disk schemas (dev mode)
 	if resolved == nil && config.BaseDir != "" {
 		if repoRoot := findSpecDir(config.BaseDir); repoRoot != "" {
-			schemaPath := getSchemaPath(repoRoot, artifactType)
+			schemaPath := getSchemaPath(repoRoot, version, artifactType)
 			schema, err := compileSchemaFromDisk(schemaPath)
 			if err != nil {
 				return nil, fmt.Errorf("failed to compile on-disk schema: %w", err)
@@ -145,14 +151,14 @@ func LoadSchema(artifactType string, config SchemaConfig) (*ResolvedSchema, erro
 
 	// 3. Fall back to embedded schemas
 	if resolved == nil {
-		schema, err := compileSchemaFromEmbedded(artifactType)
+		schema, err := compileSchemaFromEmbedded(version, artifactType)
 		if err != nil {
 			return nil, err
 		}
 		resolved = &ResolvedSchema{
 			Schema: schema,
 			Source: SchemaSourceEmbedded,
-			Path:   specembed.SchemaPath(artifactType),
+			Path:   specembed.VersionedSchemaPath(version, artifactType),
 		}
 	}
 
@@ -160,14 +166,53 @@ func LoadSchema(artifactType string, config SchemaConfig) (*ResolvedSchema, erro
 	return resolved, nil
 }
 
+// schemaVersion returns the protocol version whose schema validates artifact: v1.1.0
+// for handoffs that declare it, v1.0.0 otherwise.
+func schemaVersion(artifact *Artifact) string {
+	if version, _ := artifact.Data["small_version"].(string); artifact.Type == "handoff" && version == HandoffContextVersion {
+		return HandoffContextVersion
+	}
+	return ProtocolVersion
+}
+
 // ValidateArtifactWithConfig validates an artifact using the given schema config.
 func ValidateArtifactWithConfig(artifact *Artifact, config SchemaConfig) error {
-	resolved, err := LoadSchema(artifact.Type, config)
+	resolved, err := LoadVersionedSchema(schemaVersion(artifact), artifact.Type, config)
 	if err != nil {
 		return err
 	}
 
-	yamlData, err := yaml.Marshal(artifact.Data)
+	if artifact.LedgerPath == "" {
+		return validateSchemaData(resolved.Schema, artifact.Data, artifact.Path)
+	}
+
+	// The loaded ledger entries are validated one at a time, each wrapped in a
+	// single-entry progress document, so errors point at their ledger line.
+	header := map[string]any{}
+	for key, value := range artifact.Data {
+		header[key] = value
+	}
+	entries, _ := artifact.Data["entries"].([]any)
+	start := min(artifact.LedgerStart, len(entries))
+	header["entries"] = entries[:start]
+	if err := validateSchemaData(resolved.Schema, header, artifact.Path); err != nil {
+		return err
+	}
+	for i, entry := range entries[start:] {
+		path := artifact.LedgerPath
+		if i < len(artifact.LedgerLines) {
+			path = fmt.Sprintf("%s:%d", artifact.LedgerPath, artifact.LedgerLines[i])
+		}
+		header["entries"] = []any{entry}
+		if err := validateSchemaData(resolved.Schema, header, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateSchemaData(schema *jsonschema.Schema, data map[string]any, path string) error {
+	yamlData, err := yaml.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("failed to marshal YAML: %w", err)
 	}
@@ -177,11 +222,11 @@ func ValidateArtifactWithConfig(artifact *Artifact, config SchemaConfig) error {
 		return fmt.Errorf("failed to convert YAML to JSON: %w", err)
 	}
 
-	if err := resolved.Schema.Validate(jsonData); err != nil {
+	if err := schema.Validate(jsonData); err != nil {
 		if validationError, ok := err.(*jsonschema.ValidationError); ok {
-			return formatValidationError(validationError, artifact.Path)
+			return formatValidationError(validationError, path)
 		}
-		return fmt.Errorf("validation failed for %s: %w", artifact.Path, err)
+		return fmt.Errorf("validation failed for %s: %w", path, err)
 	}
 
 	return nil
@@ -1,5 +1,26 @@
 package small
 
+import "fmt"
+
 // ProtocolVersion is the single source of truth for the SMALL protocol version.
 // All templates and invariants reference this constant.
 const ProtocolVersion = "1.0.0"
+
+// HandoffContextVersion is the protocol version of handoffs that carry a context
+// section. v1.1.0 extends the v1.0.0 handoff with context only; every other
+// artifact, and every handoff without context, stays at ProtocolVersion.
+const HandoffContextVersion = "1.1.0"
+
+// ArtifactVersionSupported reports whether version is a small_version accepted for
+// artifactType.
+func ArtifactVersionSupported(artifactType, version string) bool {
+	return version == ProtocolVersion || (artifactType == "handoff" && version == HandoffContextVersion)
+}
+
+// supportedVersionsText describes the small_version values accepted for artifactType.
+func supportedVersionsText(artifactType string) string {
+	if artifactType == "handoff" {
+		return fmt.Sprintf(`"%s" or "%s"`, ProtocolVersion, HandoffContextVersion)
+	}
+	return fmt.Sprintf(`exactly "%s"`, ProtocolVersion)
+}
@@ -42,3 +42,12 @@ func normalizeSmallVersionYAML(data []byte) []byte {
 	}
 	return data
 }
+
+// StringsToAny converts a string slice into the []any shape used by YAML artifact data.
+func StringsToAny(values []string) []any {
+	out := make([]any, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
@@ -1,8 +1,9 @@
-// Package specembed provides embedded SMALL protocol v1.0.0 schemas for runtime validation.
+// Package specembed provides embedded SMALL protocol schemas for runtime validation.
 // This allows the CLI to validate artifacts without requiring the spec directory on disk.
 //
-// The schemas are copied from spec/small/v1.0.0/schemas/ during development.
-// The authoritative source remains spec/small/v1.0.0/schemas/.
+// The v1.0.0 schemas are copied from spec/small/v1.0.0/schemas/ and the v1.1.0
+// handoff extension from spec/small/v1.1.0/schemas/ during development.
+// The authoritative source remains spec/small/.
 package specembed
 
 import (
@@ -10,13 +11,17 @@ import (
 	"io/fs"
 )
 
-// schemas embeds the v1.0.0 JSON schema files.
+// baseVersion is the protocol version whose schemas live directly under schemas/.
+// Later minor versions live under schemas/v<version>/.
+const baseVersion = "1.0.0"
+
+// schemas embeds the JSON schema files.
 //
-//go:embed schemas/*.schema.json
+//go:embed schemas/*.schema.json schemas/v1.1.0/*.schema.json
 var schemas embed.FS
 
-// FS returns the embedded filesystem containing the v1.0.0 schemas.
-// Schema files are located at "schemas/<type>.schema.json".
+// FS returns the embedded filesystem containing the schemas.
+// v1.0.0 schema files are located at "schemas/<type>.schema.json".
 func FS() fs.FS {
 	return schemas
 }
@@ -30,3 +35,16 @@ func SchemaPath(artifactType string) string {
 func ReadSchema(artifactType string) ([]byte, error) {
 	return fs.ReadFile(schemas, SchemaPath(artifactType))
 }
+
+// VersionedSchemaPath returns the path to the schema file for a protocol version.
+func VersionedSchemaPath(version, artifactType string) string {
+	if version == baseVersion {
+		return SchemaPath(artifactType)
+	}
+	return "schemas/v" + version + "/" + artifactType + ".schema.json"
+}
+
+// ReadVersionedSchema reads the schema file for a protocol version from the embedded FS.
+func ReadVersionedSchema(version, artifactType string) ([]byte, error) {
+	return fs.ReadFile(schemas, VersionedSchemaPath(version, artifactType))
+}
@@ -1,6 +1,7 @@
 package specembed
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -45,3 +46,26 @@ func TestSchemaPath(t *testing.T) {
 		})
 	}
 }
+
+func TestReadVersionedSchema(t *testing.T) {
+	if got := VersionedSchemaPath("1.0.0", "handoff"); got != "schemas/handoff.schema.json" {
+		t.Errorf("VersionedSchemaPath(1.0.0, handoff) = %s", got)
+	}
+	if got := VersionedSchemaPath("1.1.0", "handoff"); got != "schemas/v1.1.0/handoff.schema.json" {
+		t.Errorf("VersionedSchemaPath(1.1.0, handoff) = %s", got)
+	}
+	data, err := ReadVersionedSchema("1.1.0", "handoff")
+	if err != nil {
+		t.Fatalf("failed to read v1.1.0 handoff schema: %v", err)
+	}
+	if !strings.Contains(string(data), `"context"`) {
+		t.Errorf("expected the v1.1.0 handoff schema to define context")
+	}
+	base, err := ReadSchema("handoff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(base), `"context"`) {
+		t.Errorf("expected the v1.0.0 handoff schema to be unchanged by v1.1.0")
+	}
+}
@@ -42,6 +42,9 @@ type Info struct {
 	CreatedAt    string `yaml:"created_at,omitempty"`
 	UpdatedAt    string `yaml:"updated_at,omitempty"`
 	Run          *Run   `yaml:"run,omitempty"`
+	// ProgressChain records that progress entries are hash-chained, so strict mode
+	// requires the chain even if every entry_hash is removed.
+	ProgressChain bool `yaml:"progress_chain,omitempty"`
 }
 
 // Run describes current run metadata persisted in workspace.small.yml.
@@ -128,6 +131,11 @@ func Save(baseDir string, kind Kind) error {
 	if err := os.MkdirAll(smallDir, 0755); err != nil {
 		return fmt.Errorf("failed to create .small directory: %w", err)
 	}
+	unlock, err := small.LockWorkspace(baseDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	now := time.Now().UTC().Format(time.RFC3339Nano)
 	info := Info{
@@ -288,6 +296,11 @@ func TouchUpdatedAt(baseDir string) (bool, error) {
 		}
 		return false, fmt.Errorf("failed to stat workspace metadata: %w", err)
 	}
+	unlock, err := small.LockWorkspace(baseDir)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
 
 	info, err := Load(baseDir)
 	if err != nil {
@@ -309,7 +322,7 @@ func TouchUpdatedAt(baseDir string) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("failed to marshal workspace metadata: %w", err)
 	}
-	if err := os.WriteFile(path, data, 0o644); err != nil {
+	if err := small.WriteFileAtomic(path, data, 0o644); err != nil {
 		return false, fmt.Errorf("failed to write workspace metadata: %w", err)
 	}
 	return true, nil
@@ -333,6 +346,11 @@ func SetRunReplayID(baseDir, replayID string) error {
 	if value == "" {
 		return fmt.Errorf("replay_id must be non-empty")
 	}
+	unlock, err := small.LockWorkspace(baseDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
 
 	info, err := Load(baseDir)
 	if err != nil {
@@ -362,7 +380,43 @@ func SetRunReplayID(baseDir, replayID string) error {
 		return fmt.Errorf("failed to marshal workspace metadata: %w", err)
 	}
 	path := filepath.Join(baseDir, small.SmallDir, "workspace.small.yml")
-	if err := os.WriteFile(path, data, 0o644); err != nil {
+	if err := small.WriteFileAtomic(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write workspace metadata: %w", err)
+	}
+	return nil
+}
+
+// SetProgressChain records in workspace metadata that the progress log is hash-chained.
+func SetProgressChain(baseDir string) error {
+	unlock, err := small.LockWorkspace(baseDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	info, err := Load(baseDir)
+	if err != nil {
+		return err
+	}
+	if info.ProgressChain {
+		return nil
+	}
+	info.ProgressChain = true
+
+	if info.SmallVersion == "" {
+		info.SmallVersion = small.ProtocolVersion
+	}
+	if info.Owner == "" {
+		info.Owner = "agent"
+	}
+	info.UpdatedAt = time.Now().UTC().Format(time.RFC3339Nano)
+
+	data, err := small.MarshalYAMLWithQuotedVersion(info)
+	if err != nil {
+		return fmt.Errorf("failed to marshal workspace metadata: %w", err)
+	}
+	path := filepath.Join(baseDir, small.SmallDir, "workspace.small.yml")
+	if err := small.WriteFileAtomic(path, data, 0o644); err != nil {
 		return fmt.Errorf("failed to write workspace metadata: %w", err)
 	}
 	return nil